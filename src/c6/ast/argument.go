@@ -0,0 +1,101 @@
+package ast
+
+/*
+Argument presents an argument in the argument list of the mixin declaration:
+
+	@mixin button($color, $padding: 10px, $shadows...) { }
+*/
+type Argument struct {
+	Span
+
+	Name         string
+	DefaultValue Expression
+
+	// The last argument may take the rest arguments, e.g. `$shadows...`
+	VariableLength bool
+
+	Token *Token
+}
+
+func NewArgument(token *Token) *Argument {
+	return &Argument{Span{TokenRange(token)}, token.Str, nil, false, token}
+}
+
+func (self Argument) String() (out string) {
+	out = self.Name
+	if self.DefaultValue != nil {
+		out += ": " + self.DefaultValue.String()
+	}
+	if self.VariableLength {
+		out += "..."
+	}
+	return out
+}
+
+type ArgumentList struct {
+	Span
+
+	Arguments []*Argument
+}
+
+func NewArgumentList() *ArgumentList {
+	return &ArgumentList{Span{}, []*Argument{}}
+}
+
+func (self *ArgumentList) Append(arg *Argument) {
+	self.Arguments = append(self.Arguments, arg)
+}
+
+func (self *ArgumentList) Len() int {
+	return len(self.Arguments)
+}
+
+func (self ArgumentList) String() string {
+	var out = "("
+	for i, arg := range self.Arguments {
+		if i > 0 {
+			out += ", "
+		}
+		out += arg.String()
+	}
+	return out + ")"
+}
+
+/*
+KeywordArgument presents the keyword argument of a mixin or function call:
+
+	@include button($padding: 5px);
+*/
+type KeywordArgument struct {
+	Span
+
+	Name  string
+	Value Expression
+}
+
+func NewKeywordArgument(name string, value Expression) *KeywordArgument {
+	return &KeywordArgument{Span{NodeRange(value)}, name, value}
+}
+
+func (self KeywordArgument) String() string {
+	return self.Name + ": " + self.Value.String()
+}
+
+/*
+RestArgument expands a list into the positional arguments of a call:
+
+	@include box-shadow($shadows...);
+*/
+type RestArgument struct {
+	Span
+
+	Value Expression
+}
+
+func NewRestArgument(value Expression) *RestArgument {
+	return &RestArgument{Span{NodeRange(value)}, value}
+}
+
+func (self RestArgument) String() string {
+	return self.Value.String() + "..."
+}
@@ -1,6 +1,8 @@
 package ast
 
 type Block struct {
+	Span
+
 	Statements []Statement
 }
 
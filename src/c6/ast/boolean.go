@@ -0,0 +1,57 @@
+package ast
+
+type Boolean struct {
+	Span
+
+	Value bool
+	Token *Token
+}
+
+func (self Boolean) CanBeNode() {}
+func (self Boolean) GetValueType() ValueType {
+	return BooleanValue
+}
+
+func (self Boolean) String() string {
+	if self.Value {
+		return "true"
+	}
+	return "false"
+}
+
+func NewBoolean(value bool, token *Token) *Boolean {
+	return &Boolean{Span{TokenRange(token)}, value, token}
+}
+
+type Null struct {
+	Span
+
+	Token *Token
+}
+
+func (self Null) CanBeNode() {}
+func (self Null) GetValueType() ValueType {
+	return NullValue
+}
+
+func (self Null) String() string {
+	return "null"
+}
+
+func NewNull(token *Token) *Null {
+	return &Null{Span{TokenRange(token)}, token}
+}
+
+/*
+IsTruthy reports the truthiness of the value, only `false` and `null` are
+falsey.
+*/
+func IsTruthy(val Value) bool {
+	switch t := val.(type) {
+	case *Boolean:
+		return t.Value
+	case *Null:
+		return false
+	}
+	return val != nil
+}
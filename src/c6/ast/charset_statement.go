@@ -1,12 +1,18 @@
 package ast
 
 type CharsetStatement struct {
+	Span
+
 	Charset string
 	Token   *Token
 }
 
 func (self CharsetStatement) CanBeStatement() {}
 
+func (self CharsetStatement) String() string {
+	return "@charset \"" + self.Charset + "\";"
+}
+
 func NewCharsetStatement(token *Token) *CharsetStatement {
-	return &CharsetStatement{token.Str, token}
+	return &CharsetStatement{Span{TokenRange(token)}, token.Str, token}
 }
@@ -15,6 +15,8 @@ type Color interface {
 }
 
 type HexColor struct {
+	Span
+
 	Hex   Hex
 	R     uint32
 	G     uint32
@@ -24,7 +26,13 @@ type HexColor struct {
 
 func (self HexColor) CanBeNode()  {}
 func (self HexColor) CanBeColor() {}
+func (self HexColor) GetValueType() ValueType {
+	return HexColorValue
+}
 func (self HexColor) String() string {
+	if len(self.Hex) > 0 && self.Hex[0] == '#' {
+		return string(self.Hex)
+	}
 	return "#" + string(self.Hex)
 }
 
@@ -34,7 +42,7 @@ func NewHexColorFromToken(token *Token) *HexColor {
 
 func NewHexColor(hex string, token *Token) *HexColor {
 	var r, g, b, _ = HexToRGBA(hex)
-	return &HexColor{Hex(hex), r, g, b, token}
+	return &HexColor{Span{TokenRange(token)}, Hex(hex), r, g, b, token}
 }
 
 func HexColorAddNumber(c *HexColor, num *Number) *HexColor {
@@ -42,7 +50,7 @@ func HexColorAddNumber(c *HexColor, num *Number) *HexColor {
 	g := c.G + uint32(num.Value)
 	b := c.B + uint32(num.Value)
 	hex := Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &HexColor{hex, r, g, b, nil}
+	return &HexColor{Span{}, hex, r, g, b, nil}
 }
 
 func uintsub(a, b uint32) uint32 {
@@ -58,7 +66,7 @@ func HexColorSubNumber(c *HexColor, num *Number) *HexColor {
 	g := uintsub(c.G, val)
 	b := uintsub(c.B, val)
 	hex := Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &HexColor{hex, r, g, b, nil}
+	return &HexColor{Span{}, hex, r, g, b, nil}
 }
 
 func HexColorMulNumber(color *HexColor, num *Number) *HexColor {
@@ -66,7 +74,7 @@ func HexColorMulNumber(color *HexColor, num *Number) *HexColor {
 	g := uint32(math.Floor(float64(color.G) * num.Value))
 	b := uint32(math.Floor(float64(color.B) * num.Value))
 	hex := Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &HexColor{hex, r, g, b, nil}
+	return &HexColor{Span{}, hex, r, g, b, nil}
 }
 
 func HexColorDivNumber(color *HexColor, num *Number) *HexColor {
@@ -74,7 +82,7 @@ func HexColorDivNumber(color *HexColor, num *Number) *HexColor {
 	g := uint32(math.Floor(float64(color.G) / num.Value))
 	b := uint32(math.Floor(float64(color.B) / num.Value))
 	hex := Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &HexColor{hex, r, g, b, nil}
+	return &HexColor{Span{}, hex, r, g, b, nil}
 }
 
 // HexToRGB converts an Hex string to a RGB triple.
@@ -88,7 +96,6 @@ func HexToRGBA(h string) (uint32, uint32, uint32, float32) {
 	}
 	if len(h) == 6 {
 		if rgb, err := strconv.ParseUint(string(h), 16, 32); err == nil {
-			fmt.Printf("%+v", rgb)
 			return uint32(rgb >> 16), uint32((rgb >> 8) & 0xFF), uint32(rgb & 0xFF), 0
 		}
 	}
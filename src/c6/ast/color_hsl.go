@@ -8,6 +8,8 @@ import "fmt"
 import "math"
 
 type HSLColor struct {
+	Span
+
 	H     float64
 	S     float64
 	L     float64
@@ -16,6 +18,9 @@ type HSLColor struct {
 
 func (self HSLColor) CanBeColor() {}
 func (self HSLColor) CanBeNode()  {}
+func (self HSLColor) GetValueType() ValueType {
+	return HSLColorValue
+}
 func (self HSLColor) HSLAColor() *HSLAColor {
 	return NewHSLAColor(self.H, self.S, self.L, 0, nil)
 
@@ -34,10 +39,12 @@ func (self HSLColor) String() string {
 }
 
 func NewHSLColor(h, s, v float64, token *Token) *HSLColor {
-	return &HSLColor{h, s, v, token}
+	return &HSLColor{Span{TokenRange(token)}, h, s, v, token}
 }
 
 type HSLAColor struct {
+	Span
+
 	H     float64
 	S     float64
 	L     float64
@@ -47,12 +54,15 @@ type HSLAColor struct {
 
 func (self HSLAColor) CanBeColor() {}
 func (self HSLAColor) CanBeNode()  {}
+func (self HSLAColor) GetValueType() ValueType {
+	return HSLAColorValue
+}
 func (self HSLAColor) String() string {
 	return fmt.Sprintf("hsl(%G, %G, %G, %G)", self.H, self.S, self.L, self.A)
 }
 
 func NewHSLAColor(h, s, v, a float64, token *Token) *HSLAColor {
-	return &HSLAColor{h, s, v, a, token}
+	return &HSLAColor{Span{TokenRange(token)}, h, s, v, a, token}
 }
 
 /*
@@ -4,6 +4,8 @@ import "fmt"
 import "math"
 
 type HSVColor struct {
+	Span
+
 	H     float64
 	S     float64
 	V     float64
@@ -29,7 +31,7 @@ func (self HSVColor) String() string {
 }
 
 func NewHSVColor(h, s, v float64, token *Token) *HSVColor {
-	return &HSVColor{h, s, v, token}
+	return &HSVColor{Span{TokenRange(token)}, h, s, v, token}
 }
 
 func RGBToHSV(ir, ig, ib uint32) (h, s, v float64) {
@@ -4,6 +4,8 @@ import "fmt"
 import "math"
 
 type RGBAColor struct {
+	Span
+
 	R     uint32
 	G     uint32
 	B     uint32
@@ -14,11 +16,14 @@ type RGBAColor struct {
 // Factor functions
 func NewRGBAColorWithHexCode(hex string, token *Token) *RGBAColor {
 	var r, g, b, a = HexToRGBA(hex)
-	return &RGBAColor{r, g, b, a, token}
+	return &RGBAColor{Span{TokenRange(token)}, r, g, b, a, token}
 }
 
 func (self RGBAColor) CanBeNode()  {}
 func (self RGBAColor) CanBeColor() {}
+func (self RGBAColor) GetValueType() ValueType {
+	return RGBAColorValue
+}
 
 // NOTE: 8 char hex color is only supported by IE.
 func (self RGBAColor) Hex() Hex {
@@ -62,7 +67,7 @@ func RGBAColorDivNumber(c *RGBAColor, n *Number) *RGBAColor {
 }
 
 func NewRGBAColor(r, g, b uint32, a float32, token *Token) *RGBAColor {
-	return &RGBAColor{r, g, b, a, token}
+	return &RGBAColor{Span{TokenRange(token)}, r, g, b, a, token}
 }
 
 /*
@@ -70,6 +75,8 @@ RGBColor can present rgb(....)
 
 */
 type RGBColor struct {
+	Span
+
 	R     uint32
 	G     uint32
 	B     uint32
@@ -78,6 +85,9 @@ type RGBColor struct {
 
 func (self RGBColor) CanBeNode()  {}
 func (self RGBColor) CanBeColor() {}
+func (self RGBColor) GetValueType() ValueType {
+	return RGBColorValue
+}
 
 func (self RGBColor) Hex() Hex {
 	return Hex(fmt.Sprintf("#%02X%02X%02X", self.R, self.G, self.B))
@@ -88,12 +98,12 @@ func (self RGBColor) String() string {
 }
 
 func NewRGBColor(r, g, b uint32, token *Token) *RGBColor {
-	return &RGBColor{r, g, b, token}
+	return &RGBColor{Span{TokenRange(token)}, r, g, b, token}
 }
 
 func NewRGBColorWithHexCode(hex string, token *Token) *RGBColor {
 	var r, g, b, _ = HexToRGBA(hex)
-	return &RGBColor{r, g, b, token}
+	return &RGBColor{Span{TokenRange(token)}, r, g, b, token}
 }
 
 func RGBColorAddNumber(c *RGBColor, n *Number) *RGBColor {
@@ -0,0 +1,122 @@
+package ast
+
+import "math"
+
+// The numbers closer than the epsilon are equal, like 1cm and 10mm
+const fuzzyEpsilon = 1e-11
+
+func fuzzyEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < fuzzyEpsilon
+}
+
+// IsComparisonOp reports whether the operator is `== != < <= > >=`
+func IsComparisonOp(op OpType) bool {
+	switch op {
+	case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+		return true
+	}
+	return false
+}
+
+/*
+Compare applies the comparison operator `== != < <= > >=` to the values, the
+numbers are compared after the units are converted, and the unitless number
+can be compared with any number by `<`, `<=`, `>` and `>=`.
+*/
+func Compare(op OpType, a Value, b Value) (Value, error) {
+	switch op {
+	case OpEq:
+		return NewBoolean(Equal(a, b), nil), nil
+	case OpNe:
+		return NewBoolean(!Equal(a, b), nil), nil
+	case OpLt, OpLe, OpGt, OpGe:
+		var la, lb = toLength(a), toLength(b)
+		if la == nil || lb == nil {
+			return nil, undefinedOperation(op, a, b)
+		}
+		var x, y, _, ok = coerceLengths(la, lb)
+		if !ok {
+			return nil, &IncompatibleUnitsError{la.UnitString(), lb.UnitString()}
+		}
+		var result bool
+		switch op {
+		case OpLt:
+			result = x < y && !fuzzyEqual(x, y)
+		case OpLe:
+			result = x < y || fuzzyEqual(x, y)
+		case OpGt:
+			result = x > y && !fuzzyEqual(x, y)
+		case OpGe:
+			result = x > y || fuzzyEqual(x, y)
+		}
+		return NewBoolean(result, nil), nil
+	}
+	return nil, undefinedOperation(op, a, b)
+}
+
+/*
+Equal reports whether the values are equal in Sass:
+
+	1in == 96px     // true, the units are converted
+	1 == 1px        // false, the unitless number only equals the unitless number
+	"a" == a        // true, the quotes are ignored
+	#f00 == rgb(255, 0, 0)
+	red == #f00     // true, the color keywords are colors
+*/
+func Equal(a Value, b Value) bool {
+	a, b = resolveColorKeywords(a, b)
+	switch ta := a.(type) {
+	case *Number, *Length:
+		var la, lb = toLength(a), toLength(b)
+		if lb == nil || la.IsUnitless() != lb.IsUnitless() {
+			return false
+		}
+		var y, ok = ConvertLength(lb, la)
+		return ok && fuzzyEqual(la.Value, y)
+	case *String:
+		var tb, ok = b.(*String)
+		return ok && ta.Value == tb.Value
+	case *Boolean:
+		var tb, ok = b.(*Boolean)
+		return ok && ta.Value == tb.Value
+	case *Null:
+		var _, ok = b.(*Null)
+		return ok
+	case *List:
+		var tb, ok = b.(*List)
+		if !ok || ta.Separator != tb.Separator || ta.Len() != tb.Len() {
+			return false
+		}
+		for i, item := range ta.Expressions {
+			if !Equal(item, tb.Expressions[i]) {
+				return false
+			}
+		}
+		return true
+	case *Map:
+		var tb, ok = b.(*Map)
+		if !ok || ta.Len() != tb.Len() {
+			return false
+		}
+		for i, key := range ta.Keys {
+			var value = tb.Get(key)
+			if value == nil || !Equal(ta.Values[i], value) {
+				return false
+			}
+		}
+		return true
+	case Color:
+		if _, ok := b.(Color); !ok {
+			return false
+		}
+		var ar, ag, ab, aa = colorChannels(a)
+		var br, bg, bb, ba = colorChannels(b)
+		// the alpha channel of RGBAColor is float32
+		return ar == br && ag == bg && ab == bb && float32(aa) == float32(ba)
+	}
+	// the expressions that are not values, like the plain CSS function calls
+	if _, ok := valueTypeOf(b); ok {
+		return false
+	}
+	return CSSString(a) == CSSString(b)
+}
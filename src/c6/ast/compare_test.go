@@ -0,0 +1,55 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestEqual(t *testing.T) {
+	var cases = []struct {
+		a, b     Value
+		expected bool
+	}{
+		{NewLength(1, UNIT_IN, nil), NewLength(96, UNIT_PX, nil), true},
+		{NewLength(1, UNIT_CM, nil), NewLength(10, UNIT_MM, nil), true},
+		{NewNumber(1, nil), NewLength(1, UNIT_PX, nil), false},
+		{NewLength(1, UNIT_PX, nil), NewLength(1, UNIT_EM, nil), false},
+		{&String{Quote: '"', Value: "a"}, &String{Value: "a"}, true},
+		{NewBoolean(true, nil), NewBoolean(true, nil), true},
+		{NewNull(nil), NewNull(nil), true},
+		{NewNull(nil), NewBoolean(false, nil), false},
+		{NewHexColor("#f00", nil), NewRGBColor(255, 0, 0, nil), true},
+		{&String{Value: "red"}, NewHexColor("#f00", nil), true},
+		{NewHexColor("#ff0000", nil), &String{Value: "Red"}, true},
+		{&String{Value: "red"}, &String{Value: "blue"}, false},
+		{&String{Quote: '"', Value: "red"}, NewHexColor("#f00", nil), false},
+		{&List{Separator: " ", Expressions: []Expression{NewNumber(1, nil)}}, &List{Separator: ",", Expressions: []Expression{NewNumber(1, nil)}}, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, Equal(c.a, c.b), "%s == %s", c.a, c.b)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var cases = []struct {
+		op       OpType
+		a, b     Value
+		expected string
+	}{
+		{OpLt, NewNumber(1, nil), NewNumber(2, nil), "true"},
+		{OpLe, NewLength(1, UNIT_IN, nil), NewLength(96, UNIT_PX, nil), "true"},
+		{OpGt, NewLength(1, UNIT_SECOND, nil), NewLength(500, UNIT_MILLISECOND, nil), "true"},
+		{OpGe, NewNumber(1, nil), NewLength(2, UNIT_PX, nil), "false"},
+		{OpNe, NewNumber(1, nil), &String{Value: "a"}, "true"},
+	}
+	for _, c := range cases {
+		val, err := Compare(c.op, c.a, c.b)
+		if assert.Nil(t, err) {
+			assert.Equal(t, c.expected, val.String())
+		}
+	}
+
+	_, err := Compare(OpLt, NewLength(1, UNIT_PX, nil), NewLength(1, UNIT_SECOND, nil))
+	assert.IsType(t, &IncompatibleUnitsError{}, err)
+
+	_, err = Compare(OpGt, NewBoolean(true, nil), NewNumber(1, nil))
+	assert.Equal(t, "Undefined operation: true > 1", err.Error())
+}
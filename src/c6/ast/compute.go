@@ -1,6 +1,8 @@
 package ast
 
 import "fmt"
+import "math"
+import "strings"
 
 type ComputableValue interface {
 	GetValueType() ValueType
@@ -9,150 +11,447 @@ type ComputableValue interface {
 type ValueType uint16
 
 const (
-	NumberValue    ValueType = 0
-	LengthValue              = 1
-	HexColorValue            = 2
-	RGBAColorValue           = 3
-	RGBColorValue            = 4
-	ListValue                = 5
-	MapValue                 = 6
+	NumberValue ValueType = iota
+	LengthValue
+	HexColorValue
+	RGBAColorValue
+	RGBColorValue
+	ListValue
+	MapValue
+	StringValue
+	BooleanValue
+	NullValue
+	HSLColorValue
+	HSLAColorValue
 )
 
+const ValueTypeNum = 12
+
+var numberValueTypes = []ValueType{NumberValue, LengthValue}
+var colorValueTypes = []ValueType{HexColorValue, RGBAColorValue, RGBColorValue, HSLColorValue, HSLAColorValue}
+
 /*
-Value
+ComputeFunction computes the binary operation of the values, the operands
+are dispatched by their value types with computeFunctionMatrix.
 */
-type ComputeFunction func(a Value, b Value) Value
+type ComputeFunction func(op OpType, a Value, b Value) (Value, error)
 
-const ValueTypeNum = 7
+/*
+Each row is the value type of the left operand, and each column is the value
+type of the right operand. The pairs without the arithmetic of their own are
+computed by computeValues, like the string concatenation.
+*/
+var computeFunctionMatrix [ValueTypeNum][ValueTypeNum]ComputeFunction
 
-var computableMatrix [ValueTypeNum][ValueTypeNum]bool = [ValueTypeNum][ValueTypeNum]bool{
-	/* NumberValue */
-	[ValueTypeNum]bool{false, false, false, false, false, false, false},
+func init() {
+	for a := range computeFunctionMatrix {
+		for b := range computeFunctionMatrix[a] {
+			computeFunctionMatrix[a][b] = computeValues
+		}
+	}
+	for _, a := range numberValueTypes {
+		for _, b := range numberValueTypes {
+			computeFunctionMatrix[a][b] = computeNumbers
+		}
+		for _, c := range colorValueTypes {
+			computeFunctionMatrix[a][c] = computeNumberColor
+			computeFunctionMatrix[c][a] = computeColorNumber
+		}
+	}
+	for _, a := range colorValueTypes {
+		for _, b := range colorValueTypes {
+			computeFunctionMatrix[a][b] = computeColors
+		}
+	}
+}
 
-	/* LengthValue */
-	[ValueTypeNum]bool{false, false, false, false, false, false, false},
+/*
+OperationError reports the operation that can't be applied to the operands,
+the Left operand is nil for the unary operation:
 
-	/* HexColorValue */
-	[ValueTypeNum]bool{false, false, false, false, false, false, false},
+	Undefined operation: 1px + #fff
+	Alpha channels must be equal: rgba(0, 0, 0, 0.5) + #fff
+*/
+type OperationError struct {
+	Reason string
+	Op     OpType
+	Left   Value
+	Right  Value
+}
 
-	/* RGBAColorValue */
-	[ValueTypeNum]bool{false, false, false, false, false, false, false},
+func (self *OperationError) Error() string {
+	var reason = self.Reason
+	if reason == "" {
+		reason = "Undefined operation"
+	}
+	if self.Left == nil {
+		return reason + ": " + self.Op.Symbol() + CSSString(self.Right)
+	}
+	return reason + ": " + CSSString(self.Left) + " " + self.Op.Symbol() + " " + CSSString(self.Right)
+}
 
-	/* RGBColorValue */
-	[ValueTypeNum]bool{false, false, false, false, false, false, false},
+func undefinedOperation(op OpType, a Value, b Value) error {
+	return &OperationError{Op: op, Left: a, Right: b}
 }
 
-/**
-Each row: [5]ComputeFunction{ NumberValue, LengthValue, HexColorValue, RGBAColorValue, RGBColorValue }
+/*
+IncompatibleUnitsError reports the units that can't be added, subtracted or
+compared, like `10px + 2em` and `1px*px + 1px`. The units are formatted by
+Length.UnitString.
 */
-var computeFunctionMatrix [5][5]ComputeFunction = [5][5]ComputeFunction{
+type IncompatibleUnitsError struct {
+	Left  string
+	Right string
+}
 
-	/* NumberValue */
-	[5]ComputeFunction{nil, nil, nil, nil, nil},
+func (self *IncompatibleUnitsError) Error() string {
+	return fmt.Sprintf("Incompatible units: '%s' and '%s'", self.Left, self.Right)
+}
 
-	/* LengthValue */
-	[5]ComputeFunction{nil, nil, nil, nil, nil},
+func valueTypeOf(val Value) (ValueType, bool) {
+	if computable, ok := val.(ComputableValue); ok {
+		return computable.GetValueType(), true
+	}
+	return 0, false
+}
 
-	/* HexColorValue */
-	[5]ComputeFunction{nil, nil, nil, nil, nil},
+// colorKeyword returns the color of the unquoted color keyword like `red`
+func colorKeyword(val Value) Value {
+	if str, ok := val.(*String); ok && str.Quote == 0 {
+		if hex, ok := ColorKeywords[strings.ToLower(str.Value)]; ok {
+			return NewHexColor(hex, str.Token)
+		}
+	}
+	return nil
+}
 
-	/* RGBAColorValue */
-	[5]ComputeFunction{nil, nil, nil, nil, nil},
+/*
+resolveColorKeywords resolves the color keywords to the colors when the other
+operand is a color or a number, `red + blue` is #FF00FF and `red == #f00` is
+true, while `red + -x` is still the string concatenation.
+*/
+func resolveColorKeywords(a Value, b Value) (Value, Value) {
+	var ca, cb = colorKeyword(a), colorKeyword(b)
+	var isColorOrNumber = func(val Value) bool {
+		_, ok := val.(Color)
+		return ok || toLength(val) != nil
+	}
+	if ca != nil && (cb != nil || isColorOrNumber(b)) {
+		a = ca
+	}
+	if cb != nil && (ca != nil || isColorOrNumber(a)) {
+		b = cb
+	}
+	return a, b
+}
 
-	/* RGBColorValue */
-	[5]ComputeFunction{nil, nil, nil, nil, nil},
+func isArithmeticOp(op OpType) bool {
+	switch op {
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod:
+		return true
+	}
+	return false
+}
+
+/*
+IsComputable reports whether the operation can be computed on the operands.
+The expressions that are not values, like the plain CSS function calls, are
+not computable and are rendered as they are written.
+*/
+func IsComputable(op OpType, a Value, b Value) bool {
+	if !isArithmeticOp(op) {
+		return false
+	}
+	_, aok := valueTypeOf(a)
+	_, bok := valueTypeOf(b)
+	return aok && bok
+}
+
+/*
+Compute applies the arithmetic operator `+ - * / %` to the values, the
+OperationError is returned if the operation is undefined for the operands.
+*/
+func Compute(op OpType, a Value, b Value) (Value, error) {
+	a, b = resolveColorKeywords(a, b)
+	var ta, aok = valueTypeOf(a)
+	var tb, bok = valueTypeOf(b)
+	if !aok || !bok || !isArithmeticOp(op) {
+		return nil, undefinedOperation(op, a, b)
+	}
+	return computeFunctionMatrix[ta][tb](op, a, b)
+}
+
+/*
+ComputeUnary applies the unary operator `-`, `+` or `not` to the value.
+*/
+func ComputeUnary(op OpType, val Value) (Value, error) {
+	if op == OpNot {
+		return NewBoolean(!IsTruthy(val), nil), nil
+	}
+	if op != OpAdd && op != OpSub {
+		return nil, undefinedOperation(op, nil, val)
+	}
+	switch t := val.(type) {
+	case *Number:
+		if op == OpSub {
+			return NewNumber(-t.Value, nil), nil
+		}
+		return t, nil
+	case *Length:
+		if op == OpSub {
+			return t.withValue(-t.Value), nil
+		}
+		return t, nil
+	case *Map, *Null:
+		return nil, undefinedOperation(op, nil, val)
+	}
+	if _, ok := valueTypeOf(val); !ok {
+		return nil, undefinedOperation(op, nil, val)
+	}
+	return &String{Value: op.Symbol() + CSSString(val)}, nil
+}
+
+/*
+CSSString returns the value as it's written in CSS, the quoted strings keep
+their quotes.
+*/
+func CSSString(val Value) string {
+	switch t := val.(type) {
+	case *String:
+		if t.Quote != 0 {
+			return string(t.Quote) + t.Value + string(t.Quote)
+		}
+		return t.Value
+	case *List:
+		var items []string
+		for _, item := range t.Expressions {
+			items = append(items, CSSString(item))
+		}
+		return strings.Join(items, t.Separator)
+	case nil:
+		return ""
+	}
+	return val.String()
 }
 
-func Compute(op OpType, a Value, b Value) Value {
+/*
+computeValues computes the values without arithmetic of their own like the
+strings and the lists: `+` concatenates the values, `-` and `/` join them
+with the operator as the unquoted string, the maps and null can't be used.
+*/
+func computeValues(op OpType, a Value, b Value) (Value, error) {
+	for _, val := range []Value{a, b} {
+		switch val.(type) {
+		case *Map, *Null:
+			return nil, undefinedOperation(op, a, b)
+		}
+	}
+
 	switch op {
 	case OpAdd:
-		switch ta := a.(type) {
-		case *Number:
-			switch tb := b.(type) {
-			case *Number:
-				return NumberAddNumber(ta, tb)
-			case *HexColor:
-				return HexColorAddNumber(tb, ta)
-			}
-		case *Length:
-			switch tb := b.(type) {
-			case *Length:
-				return LengthAddLength(ta, tb)
-			}
-		case *HexColor:
-			switch tb := b.(type) {
-			case *Number:
-				return HexColorAddNumber(ta, tb)
-			}
-		case *RGBColor:
-			switch tb := b.(type) {
-			case *Number:
-				return RGBColorAddNumber(ta, tb)
-			}
-		case *RGBAColor:
-			switch tb := b.(type) {
-			case *Number:
-				return RGBAColorAddNumber(ta, tb)
-			}
+		// the quote of the string on the left is kept, `"a" + b` is "ab" and
+		// `a + "b"` is ab.
+		var quote byte = 0
+		if str, ok := a.(*String); ok {
+			quote = str.Quote
+		} else if str, ok := b.(*String); ok {
+			quote = str.Quote
 		}
-	case OpSub:
-		switch ta := a.(type) {
+		return &String{Quote: quote, Value: stringText(a) + stringText(b)}, nil
+	case OpSub, OpDiv:
+		return &String{Value: CSSString(a) + op.Symbol() + CSSString(b)}, nil
+	}
+	return nil, undefinedOperation(op, a, b)
+}
 
-		case *Number:
-			switch tb := b.(type) {
-			case *Number:
-				return NumberSubNumber(ta, tb)
-			}
+// stringText returns the text of the string without the quotes
+func stringText(val Value) string {
+	if str, ok := val.(*String); ok {
+		return str.Value
+	}
+	return CSSString(val)
+}
 
-		case *Length:
-			switch tb := b.(type) {
-			case *Length:
-				val := LengthSubLength(ta, tb)
-				fmt.Printf("Substracted value: %+v\n", val)
-				return val
-			}
+func toLength(val Value) *Length {
+	switch t := val.(type) {
+	case *Length:
+		return t
+	case *Number:
+		return NewLength(t.Value, UNIT_NONE, nil)
+	}
+	return nil
+}
 
-		case *HexColor:
-			switch tb := b.(type) {
-			case *Number:
-				return HexColorSubNumber(ta, tb)
+/*
+computeNumbers computes the numbers and the lengths, the unitless value takes
+the units of the other operand, and the result without units is the number.
+*/
+func computeNumbers(op OpType, a Value, b Value) (Value, error) {
+	if na, ok := a.(*Number); ok {
+		if nb, ok := b.(*Number); ok {
+			switch op {
+			case OpAdd:
+				return NumberAddNumber(na, nb), nil
+			case OpSub:
+				return NumberSubNumber(na, nb), nil
+			case OpMul:
+				return NumberMulNumber(na, nb), nil
+			case OpDiv:
+				return NumberDivNumber(na, nb), nil
+			case OpMod:
+				return NumberModNumber(na, nb), nil
 			}
+			return nil, undefinedOperation(op, a, b)
+		}
+	}
 
-		case *RGBColor:
-			switch tb := b.(type) {
-			case *Number:
-				return RGBColorSubNumber(ta, tb)
-			}
+	var la, lb = toLength(a), toLength(b)
+	var result *Length
+	switch op {
+	case OpAdd:
+		result = LengthAddLength(la, lb)
+	case OpSub:
+		result = LengthSubLength(la, lb)
+	case OpMul:
+		result = LengthMulLength(la, lb)
+	case OpDiv:
+		result = LengthDivLength(la, lb)
+	case OpMod:
+		result = LengthModLength(la, lb)
+	default:
+		return nil, undefinedOperation(op, a, b)
+	}
+	if result == nil {
+		return nil, &IncompatibleUnitsError{la.UnitString(), lb.UnitString()}
+	}
+	if result.IsUnitless() {
+		return NewNumber(result.Value, nil), nil
+	}
+	return result, nil
+}
 
-		case *RGBAColor:
-			switch tb := b.(type) {
-			case *Number:
-				return RGBAColorSubNumber(ta, tb)
-			}
+/*
+computeColorNumber applies the unitless number to each channel of the color:
+
+	#010203 * 2 = #020406
+*/
+func computeColorNumber(op OpType, a Value, b Value) (Value, error) {
+	if l, ok := b.(*Length); ok && !l.IsUnitless() {
+		return nil, undefinedOperation(op, a, b)
+	}
+	var n = toLength(b).Value
+	var r, g, bl, alpha = colorChannels(a)
+	return computeChannels(op, a, b, [3]float64{r, g, bl}, [3]float64{n, n, n}, alpha)
+}
+
+/*
+computeNumberColor adds the number to or multiplies the number by the color,
+the number can't be the dividend, `1 - #fff` and `1 / #fff` are joined as
+the strings like the other values.
+*/
+func computeNumberColor(op OpType, a Value, b Value) (Value, error) {
+	switch op {
+	case OpAdd, OpMul:
+		if l, ok := a.(*Length); ok && !l.IsUnitless() {
+			return nil, undefinedOperation(op, a, b)
 		}
-	case OpMul:
-		switch ta := a.(type) {
-
-		case *Length:
-			switch tb := b.(type) {
-			case *Length:
-				return LengthMulLength(ta, tb)
-			case *Number:
-				return LengthMulNumber(ta, tb)
-			}
+		var n = toLength(a).Value
+		var r, g, bl, alpha = colorChannels(b)
+		return computeChannels(op, b, a, [3]float64{n, n, n}, [3]float64{r, g, bl}, alpha)
+	case OpSub, OpDiv:
+		return computeValues(op, a, b)
+	}
+	return nil, undefinedOperation(op, a, b)
+}
 
-		case *RGBColor:
-			switch tb := b.(type) {
-			case *Number:
-				return RGBColorMulNumber(ta, tb)
-			}
+/*
+computeColors computes the colors channel by channel, the alpha channels must
+be equal.
+*/
+func computeColors(op OpType, a Value, b Value) (Value, error) {
+	var ar, ag, ab, aa = colorChannels(a)
+	var br, bg, bb, ba = colorChannels(b)
+	if aa != ba {
+		return nil, &OperationError{Reason: "Alpha channels must be equal", Op: op, Left: a, Right: b}
+	}
+	return computeChannels(op, a, b, [3]float64{ar, ag, ab}, [3]float64{br, bg, bb}, aa)
+}
 
-		case *RGBAColor:
-			switch tb := b.(type) {
-			case *Number:
-				return RGBAColorMulNumber(ta, tb)
-			}
+/*
+computeChannels computes the red, green and blue channels, the result is
+rounded and clamped to 0~255, and it takes the color type of the color
+operand `like`.
+*/
+func computeChannels(op OpType, like Value, other Value, x [3]float64, y [3]float64, alpha float64) (Value, error) {
+	var channels [3]uint32
+	for i := range channels {
+		var v float64
+		switch op {
+		case OpAdd:
+			v = x[i] + y[i]
+		case OpSub:
+			v = x[i] - y[i]
+		case OpMul:
+			v = x[i] * y[i]
+		case OpDiv:
+			v = x[i] / y[i]
+		case OpMod:
+			v = floorMod(x[i], y[i])
+		default:
+			return nil, undefinedOperation(op, like, other)
+		}
+		if math.IsNaN(v) {
+			v = 0
 		}
+		channels[i] = uint32(math.Max(0, math.Min(255, math.Round(v))))
 	}
-	return nil
+	return newColorLike(like, channels[0], channels[1], channels[2], alpha), nil
+}
+
+// colorChannels returns the red, green, blue and alpha channels of the color
+func colorChannels(color Value) (r, g, b, a float64) {
+	switch t := color.(type) {
+	case *HexColor:
+		return float64(t.R), float64(t.G), float64(t.B), 1
+	case *RGBColor:
+		return float64(t.R), float64(t.G), float64(t.B), 1
+	case *RGBAColor:
+		return float64(t.R), float64(t.G), float64(t.B), float64(t.A)
+	case *HSLColor:
+		var r, g, b = HSLToRGB(t.H, t.S, t.L)
+		return float64(r), float64(g), float64(b), 1
+	case *HSLAColor:
+		var r, g, b = HSLToRGB(t.H, t.S, t.L)
+		return float64(r), float64(g), float64(b), t.A
+	}
+	return 0, 0, 0, 1
+}
+
+// newColorLike creates the color of the same type as the color `like`
+func newColorLike(like Value, r, g, b uint32, a float64) Value {
+	switch like.(type) {
+	case *RGBColor:
+		return NewRGBColor(r, g, b, nil)
+	case *RGBAColor:
+		return NewRGBAColor(r, g, b, float32(a), nil)
+	case *HSLColor:
+		var h, s, l = RGBToHSL(r, g, b)
+		return NewHSLColor(h, s, l, nil)
+	case *HSLAColor:
+		var h, s, l = RGBToHSL(r, g, b)
+		return NewHSLAColor(h, s, l, a, nil)
+	}
+	return NewHexColor(fmt.Sprintf("#%02x%02x%02x", r, g, b), nil)
+}
+
+/*
+floorMod returns the modulo of which the sign follows the divisor as in Sass,
+`-5 % 3` is 1.
+*/
+func floorMod(a float64, b float64) float64 {
+	var mod = math.Mod(a, b)
+	if mod != 0 && (mod < 0) != (b < 0) {
+		mod += b
+	}
+	return mod
 }
@@ -0,0 +1,144 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestComputeNumbers(t *testing.T) {
+	var cases = []struct {
+		op       OpType
+		a, b     Value
+		expected string
+	}{
+		{OpAdd, NewNumber(1, nil), NewNumber(2, nil), "3"},
+		{OpDiv, NewNumber(10, nil), NewNumber(4, nil), "2.5"},
+		{OpMul, NewNumber(3, nil), NewLength(2, UNIT_PX, nil), "6px"},
+		{OpAdd, NewNumber(3, nil), NewLength(2, UNIT_EM, nil), "5em"},
+		{OpSub, NewLength(10, UNIT_PX, nil), NewNumber(3, nil), "7px"},
+		{OpDiv, NewLength(10, UNIT_PX, nil), NewLength(4, UNIT_PX, nil), "2.5"},
+		{OpMod, NewLength(10, UNIT_PX, nil), NewNumber(3, nil), "1px"},
+		{OpMod, NewNumber(-5, nil), NewNumber(3, nil), "1"},
+		{OpMod, NewNumber(5, nil), NewNumber(-3, nil), "-1"},
+		{OpDiv, NewNumber(1, nil), NewNumber(0, nil), "Infinity"},
+		{OpDiv, NewNumber(-1, nil), NewNumber(0, nil), "-Infinity"},
+		{OpDiv, NewLength(1, UNIT_PX, nil), NewNumber(0, nil), "Infinitypx"},
+		{OpDiv, NewNumber(0, nil), NewNumber(0, nil), "NaN"},
+	}
+	for _, c := range cases {
+		val, err := Compute(c.op, c.a, c.b)
+		if assert.Nil(t, err) {
+			assert.Equal(t, c.expected, val.String())
+		}
+	}
+}
+
+func TestComputeIncompatibleUnits(t *testing.T) {
+	_, err := Compute(OpAdd, NewLength(10, UNIT_PX, nil), NewLength(2, UNIT_EM, nil))
+	assert.IsType(t, &IncompatibleUnitsError{}, err)
+	assert.Equal(t, "Incompatible units: 'px' and 'em'", err.Error())
+}
+
+func TestComputeColors(t *testing.T) {
+	var cases = []struct {
+		op       OpType
+		a, b     Value
+		expected string
+	}{
+		{OpAdd, NewHexColor("#010203", nil), NewNumber(1, nil), "#020304"},
+		{OpAdd, NewNumber(1, nil), NewHexColor("#010203", nil), "#020304"},
+		{OpMul, NewNumber(2, nil), NewHexColor("#010203", nil), "#020406"},
+		{OpSub, NewHexColor("#010203", nil), NewNumber(2, nil), "#000001"},
+		{OpAdd, NewHexColor("#ffffff", nil), NewNumber(1, nil), "#ffffff"},
+		{OpAdd, NewHexColor("#010203", nil), NewHexColor("#010101", nil), "#020304"},
+		{OpMod, NewHexColor("#050607", nil), NewNumber(4, nil), "#010203"},
+		{OpDiv, NewRGBColor(10, 20, 30, nil), NewNumber(2, nil), "rgb(5, 10, 15)"},
+		{OpSub, NewRGBAColor(10, 20, 30, 0.5, nil), NewRGBAColor(1, 2, 3, 0.5, nil), "rgba(9, 18, 27, 0.5)"},
+		{OpSub, NewNumber(1, nil), NewHexColor("#fff", nil), "1-#fff"},
+		{OpDiv, NewNumber(1, nil), NewHexColor("#fff", nil), "1/#fff"},
+		{OpAdd, &String{Value: "red"}, &String{Value: "blue"}, "#ff00ff"},
+		{OpSub, &String{Value: "white"}, NewNumber(1, nil), "#fefefe"},
+	}
+	for _, c := range cases {
+		val, err := Compute(c.op, c.a, c.b)
+		if assert.Nil(t, err) {
+			assert.Equal(t, c.expected, val.String())
+		}
+	}
+}
+
+func TestComputeUndefinedOperations(t *testing.T) {
+	var cases = []struct {
+		op       OpType
+		a, b     Value
+		expected string
+	}{
+		{OpAdd, NewLength(1, UNIT_PX, nil), NewHexColor("#fff", nil), "Undefined operation: 1px + #fff"},
+		{OpMul, NewHexColor("#fff", nil), NewLength(2, UNIT_EM, nil), "Undefined operation: #fff * 2em"},
+		{OpMod, NewNumber(1, nil), NewHexColor("#fff", nil), "Undefined operation: 1 % #fff"},
+		{OpMul, &String{Quote: '"', Value: "a"}, NewNumber(2, nil), `Undefined operation: "a" * 2`},
+		{OpAdd, NewMap(), NewNumber(1, nil), "Undefined operation: () + 1"},
+		{OpSub, NewNull(nil), NewNumber(1, nil), "Undefined operation: null - 1"},
+		{OpMul, NewBoolean(true, nil), NewBoolean(false, nil), "Undefined operation: true * false"},
+		{OpAdd, NewRGBAColor(0, 0, 0, 0.5, nil), NewHexColor("#fff", nil), "Alpha channels must be equal: rgba(0, 0, 0, 0.5) + #fff"},
+	}
+	for _, c := range cases {
+		_, err := Compute(c.op, c.a, c.b)
+		if assert.IsType(t, &OperationError{}, err) {
+			assert.Equal(t, c.expected, err.Error())
+		}
+	}
+}
+
+func TestComputeStrings(t *testing.T) {
+	var quoted = &String{Quote: '"', Value: "a"}
+	var unquoted = &String{Value: "b"}
+
+	val, err := Compute(OpAdd, quoted, unquoted)
+	assert.Nil(t, err)
+	assert.Equal(t, `"ab"`, CSSString(val))
+
+	val, err = Compute(OpAdd, unquoted, quoted)
+	assert.Nil(t, err)
+	assert.Equal(t, "ba", CSSString(val))
+
+	val, err = Compute(OpAdd, quoted, NewLength(1, UNIT_PX, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, `"a1px"`, CSSString(val))
+
+	val, err = Compute(OpSub, quoted, unquoted)
+	assert.Nil(t, err)
+	assert.Equal(t, `"a"-b`, CSSString(val))
+
+	val, err = Compute(OpDiv, NewBoolean(true, nil), unquoted)
+	assert.Nil(t, err)
+	assert.Equal(t, "true/b", CSSString(val))
+
+	var list = &List{Separator: " ", Expressions: []Expression{NewNumber(1, nil), NewNumber(2, nil)}}
+	val, err = Compute(OpAdd, list, NewNumber(3, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "1 23", CSSString(val))
+}
+
+func TestComputeUnary(t *testing.T) {
+	val, err := ComputeUnary(OpSub, NewLength(2, UNIT_PX, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "-2px", val.String())
+
+	val, err = ComputeUnary(OpAdd, NewNumber(2, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "2", val.String())
+
+	val, err = ComputeUnary(OpSub, &String{Value: "foo"})
+	assert.Nil(t, err)
+	assert.Equal(t, "-foo", val.String())
+
+	val, err = ComputeUnary(OpNot, NewNull(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "true", val.String())
+
+	val, err = ComputeUnary(OpNot, NewNumber(0, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "false", val.String())
+
+	_, err = ComputeUnary(OpSub, NewNull(nil))
+	assert.Equal(t, "Undefined operation: -null", err.Error())
+}
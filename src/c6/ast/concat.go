@@ -6,12 +6,14 @@ A struct that contains many concatable strings
 	{any}{expression}{any}
 */
 type LiteralConcat struct {
+	Span
+
 	Left  Expression
 	Right Expression
 }
 
 func NewLiteralConcat(left, right Expression) *LiteralConcat {
-	return &LiteralConcat{left, right}
+	return &LiteralConcat{Span{NodeRange(left).Join(NodeRange(right))}, left, right}
 }
 
 func (self LiteralConcat) String() string {
@@ -8,9 +8,13 @@ DeclarationBlock.
 }
 */
 type DeclarationBlock struct {
+	Span
+
+	// The parsed nested rulesets are declarations, so they're evaluated in
+	// the source order with the properties and the variable assignments.
 	Declarations []Declaration
 
-	// Nested rulesets
+	// The nested rulesets of the evaluated block
 	SubRuleSets []*RuleSet
 }
 
@@ -0,0 +1,39 @@
+package ast
+
+/*
+EachStatement iterates the list or the map, the items can be destructured
+into multiple variables:
+
+	@each $name in $names { }
+	@each $key, $value in $map { }
+*/
+type EachStatement struct {
+	Span
+
+	Variables []*Variable
+	List      Expression
+	Block     *DeclarationBlock
+	Token     *Token
+}
+
+func NewEachStatement(token *Token) *EachStatement {
+	return &EachStatement{Span{TokenRange(token)}, []*Variable{}, nil, nil, token}
+}
+
+func (self EachStatement) CanBeStatement()   {}
+func (self EachStatement) CanBeDeclaration() {}
+
+func (self *EachStatement) AppendVariable(variable *Variable) {
+	self.Variables = append(self.Variables, variable)
+}
+
+func (self EachStatement) String() (out string) {
+	out = "@each "
+	for i, variable := range self.Variables {
+		if i > 0 {
+			out += ", "
+		}
+		out += variable.String()
+	}
+	return out + " in " + self.List.String()
+}
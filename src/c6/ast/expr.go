@@ -6,15 +6,22 @@ type Expression interface {
 }
 
 type UnaryExpression struct {
+	Span
+
 	Op   OpType
 	Expr Expression
 }
 
 func NewUnaryExpression(op OpType, expr Expression) *UnaryExpression {
-	return &UnaryExpression{op, expr}
+	return &UnaryExpression{Span{NodeRange(expr)}, op, expr}
 }
 
 func (self *UnaryExpression) Evaluate(symTable *SymTable) Value {
+	// the truthiness is evaluated by the evaluator
+	if self.Op == OpNot {
+		return nil
+	}
+
 	var val Value = nil
 	if expr, ok := self.Expr.(*BinaryExpression); ok {
 		val = expr.Evaluate(symTable)
@@ -39,6 +46,8 @@ func (self UnaryExpression) String() string {
 }
 
 type BinaryExpression struct {
+	Span
+
 	Op      OpType
 	Left    Expression
 	Right   Expression
@@ -55,25 +64,44 @@ func (self BinaryExpression) String() (out string) {
 }
 
 /**
-The the divide expression will only be evaluated in the following 3 conditions:
+The the divide expression will only be evaluated in the following conditions,
+otherwise the slash separates the literal numbers like `font: 12px/1.5`:
 
 	1. If the value, or any part of it, is stored in a variable or returned by a function.
 	2. If the value is surrounded by parentheses.
 	3. If the value is used as part of another arithmetic expression.
+	4. If any operand is not a literal number, like `$a/2`, `fn()/2` and `+12px/2`.
+
+The conditions 1 and 3 depend on where the expression is used, they're
+decided by the evaluator, ShallDivide only checks the expression itself. The
+slash next to the interpolation like `#{$size}/#{$line-height}` is plain CSS,
+it never divides.
 
 @see http://sass-lang.com/documentation/file.SASS_REFERENCE.html#division-and-slash
 */
 func (self *BinaryExpression) ShallDivide() bool {
-	if self.Op == OpDiv {
-		_, aok := self.Left.(*Length)
-		_, bok := self.Right.(*Length)
+	if self.Op != OpDiv || self.HasInterpolation() {
+		return false
+	}
+	return self.Grouped || !isLiteralNumber(self.Left) || !isLiteralNumber(self.Right)
+}
 
-		// it's not grouped, we should inflate it as string
-		if aok && bok && self.Grouped == false {
-			return true
-		}
+// HasInterpolation reports whether any operand is the interpolation
+func (self *BinaryExpression) HasInterpolation() bool {
+	_, lok := self.Left.(*Interpolation)
+	_, rok := self.Right.(*Interpolation)
+	return lok || rok
+}
+
+// isLiteralNumber reports whether the expression is a number as it's written,
+// the slash-separated numbers like `12px/2` in `12px/2/3` are literal too.
+func isLiteralNumber(expr Expression) bool {
+	switch t := expr.(type) {
+	case *Number, *Length:
+		return true
+	case *BinaryExpression:
+		return t.Op == OpDiv && !t.ShallDivide()
 	}
-	// otherwise we can divide the value
 	return false
 }
 
@@ -97,12 +125,15 @@ func (self *BinaryExpression) Evaluate(symTable *SymTable) Value {
 	case *Number, *Length, *HexColor:
 		rval = Value(expr)
 	}
-	if lval != nil && rval != nil {
-		return Compute(self.Op, lval, rval)
+	// the invalid operations are left to the evaluator to report
+	if lval != nil && rval != nil && IsComputable(self.Op, lval, rval) {
+		if val, err := Compute(self.Op, lval, rval); err == nil {
+			return val
+		}
 	}
 	return nil
 }
 
 func NewBinaryExpression(op OpType, left Expression, right Expression, grouped bool) *BinaryExpression {
-	return &BinaryExpression{op, left, right, grouped}
+	return &BinaryExpression{Span{NodeRange(left).Join(NodeRange(right))}, op, left, right, grouped}
 }
@@ -14,3 +14,26 @@ func TestBinaryExprString(t *testing.T) {
 	var expr2 = NewBinaryExpression(OpSub, expr, num3, false)
 	t.Logf("%s", expr2.String())
 }
+
+func TestBinaryExprShallDivide(t *testing.T) {
+	var literal = NewBinaryExpression(OpDiv, NewLength(12, UNIT_PX, nil), NewNumber(1.5, nil), false)
+	if literal.ShallDivide() {
+		t.Errorf("%s is slash-separated", literal)
+	}
+	if chain := NewBinaryExpression(OpDiv, literal, NewNumber(3, nil), false); chain.ShallDivide() {
+		t.Errorf("%s is slash-separated", chain)
+	}
+
+	var grouped = NewBinaryExpression(OpDiv, NewLength(12, UNIT_PX, nil), NewNumber(2, nil), true)
+	if !grouped.ShallDivide() {
+		t.Errorf("%s should divide in parentheses", grouped)
+	}
+	var unary = NewBinaryExpression(OpDiv, NewUnaryExpression(OpAdd, NewLength(12, UNIT_PX, nil)), NewNumber(2, nil), false)
+	if !unary.ShallDivide() {
+		t.Errorf("%s should divide", unary)
+	}
+	var str = NewBinaryExpression(OpDiv, &String{Value: "a"}, NewNumber(2, nil), false)
+	if !str.ShallDivide() {
+		t.Errorf("%s should divide", str)
+	}
+}
@@ -0,0 +1,42 @@
+package ast
+
+import "strings"
+
+/*
+ExtendStatement presents the `@extend` directive in a ruleset, each target is
+a compound selector:
+
+	@extend .button;
+	@extend %message-shared !optional;
+*/
+type ExtendStatement struct {
+	Span
+
+	Selectors []*ComplexSelector
+	// The missing target is not an error with `!optional`
+	Optional bool
+	Token    *Token
+}
+
+func NewExtendStatement(token *Token) *ExtendStatement {
+	return &ExtendStatement{Span{TokenRange(token)}, []*ComplexSelector{}, false, token}
+}
+
+func (self ExtendStatement) CanBeStatement()   {}
+func (self ExtendStatement) CanBeDeclaration() {}
+
+func (self *ExtendStatement) AppendSelector(sel *ComplexSelector) {
+	self.Selectors = append(self.Selectors, sel)
+}
+
+func (self ExtendStatement) String() string {
+	var selectors []string
+	for _, sel := range self.Selectors {
+		selectors = append(selectors, sel.String())
+	}
+	var out = "@extend " + strings.Join(selectors, ", ")
+	if self.Optional {
+		out += " !optional"
+	}
+	return out
+}
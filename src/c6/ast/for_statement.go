@@ -0,0 +1,34 @@
+package ast
+
+/*
+ForStatement presents the `@for` directive, the end is included with
+`through` and excluded with `to`:
+
+	@for $i from 1 through 3 { }
+	@for $i from 1 to 3 { }
+*/
+type ForStatement struct {
+	Span
+
+	Variable  *Variable
+	From      Expression
+	To        Expression
+	Inclusive bool
+	Block     *DeclarationBlock
+	Token     *Token
+}
+
+func NewForStatement(variable *Variable, token *Token) *ForStatement {
+	return &ForStatement{Span{TokenRange(token)}, variable, nil, nil, false, nil, token}
+}
+
+func (self ForStatement) CanBeStatement()   {}
+func (self ForStatement) CanBeDeclaration() {}
+
+func (self ForStatement) String() string {
+	var keyword = " to "
+	if self.Inclusive {
+		keyword = " through "
+	}
+	return "@for " + self.Variable.String() + " from " + self.From.String() + keyword + self.To.String()
+}
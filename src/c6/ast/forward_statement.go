@@ -0,0 +1,43 @@
+package ast
+
+import "strings"
+
+/*
+ForwardStatement presents the `@forward` rule that exposes the members of
+another module as the members of the current module:
+
+	@forward "src/list" hide list-reset, $horizontal-list-gap;
+	@forward "src/list" as list-*;
+
+The names of Show and Hide are the forwarded names with the prefix, the
+variables are written with '$'.
+*/
+type ForwardStatement struct {
+	Span
+
+	Url    string
+	Prefix string
+	Show   []string
+	Hide   []string
+	Token  *Token
+}
+
+func NewForwardStatement(url string, token *Token) *ForwardStatement {
+	return &ForwardStatement{Url: url, Token: token}
+}
+
+func (self ForwardStatement) CanBeStatement() {}
+
+func (self ForwardStatement) String() string {
+	var out = "@forward \"" + self.Url + "\""
+	if self.Prefix != "" {
+		out += " as " + self.Prefix + "*"
+	}
+	if self.Show != nil {
+		out += " show " + strings.Join(self.Show, ", ")
+	}
+	if self.Hide != nil {
+		out += " hide " + strings.Join(self.Hide, ", ")
+	}
+	return out + ";"
+}
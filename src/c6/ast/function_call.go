@@ -1,6 +1,8 @@
 package ast
 
 type FunctionCall struct {
+	Span
+
 	Function  string
 	Arguments []Expression
 	Token     *Token
@@ -20,7 +22,7 @@ func (self FunctionCall) String() (out string) {
 }
 
 func NewFunctionCall(token *Token) *FunctionCall {
-	return &FunctionCall{token.Str, []Expression{}, token}
+	return &FunctionCall{Span{TokenRange(token)}, token.Str, []Expression{}, token}
 }
 
 func (self *FunctionCall) AppendArgument(arg Expression) {
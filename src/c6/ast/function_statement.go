@@ -0,0 +1,49 @@
+package ast
+
+/*
+FunctionStatement presents the user-defined function:
+
+	@function rem($px, $base: 16px) {
+		@return $px / $base * 1rem;
+	}
+*/
+type FunctionStatement struct {
+	Span
+
+	Name         string
+	ArgumentList *ArgumentList
+	Block        *DeclarationBlock
+	Token        *Token
+}
+
+func NewFunctionStatement(nameTok *Token) *FunctionStatement {
+	return &FunctionStatement{Span{TokenRange(nameTok)}, nameTok.Str, NewArgumentList(), nil, nameTok}
+}
+
+func (self FunctionStatement) CanBeStatement()   {}
+func (self FunctionStatement) CanBeDeclaration() {}
+
+func (self FunctionStatement) String() string {
+	return "@function " + self.Name + self.ArgumentList.String()
+}
+
+/*
+ReturnStatement presents the `@return` directive in the function body.
+*/
+type ReturnStatement struct {
+	Span
+
+	Value Expression
+	Token *Token
+}
+
+func NewReturnStatement(value Expression, token *Token) *ReturnStatement {
+	return &ReturnStatement{Span{TokenRange(token)}, value, token}
+}
+
+func (self ReturnStatement) CanBeStatement()   {}
+func (self ReturnStatement) CanBeDeclaration() {}
+
+func (self ReturnStatement) String() string {
+	return "@return " + self.Value.String()
+}
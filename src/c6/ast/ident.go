@@ -1,6 +1,8 @@
 package ast
 
 type Ident struct {
+	Span
+
 	Ident string
 	Token Token
 }
@@ -11,5 +13,5 @@ func (self Ident) String() string {
 }
 
 func NewIdent(ident string, token Token) *Ident {
-	return &Ident{ident, token}
+	return &Ident{Span{token.Range}, ident, token}
 }
@@ -0,0 +1,38 @@
+package ast
+
+/*
+IfStatement presents the conditional directive, the `@else if` branches are
+stored as the nested if statements:
+
+	@if $a {
+		...
+	} @else if $b {
+		...
+	} @else {
+		...
+	}
+*/
+type IfStatement struct {
+	Span
+
+	Condition Expression
+	Block     *DeclarationBlock
+	ElseIfs   []*IfStatement
+	ElseBlock *DeclarationBlock
+	Token     *Token
+}
+
+func NewIfStatement(condition Expression, token *Token) *IfStatement {
+	return &IfStatement{Span{TokenRange(token)}, condition, nil, []*IfStatement{}, nil, token}
+}
+
+func (self IfStatement) CanBeStatement()   {}
+func (self IfStatement) CanBeDeclaration() {}
+
+func (self *IfStatement) AppendElseIf(elseIf *IfStatement) {
+	self.ElseIfs = append(self.ElseIfs, elseIf)
+}
+
+func (self IfStatement) String() string {
+	return "@if " + self.Condition.String()
+}
@@ -1,14 +1,50 @@
 package ast
 
 type ImportStatement struct {
+	Span
+
 	Url       interface{} // if it's wrapped with url(...) or "string"
 	MediaList []string
+
+	// `@import-once` skips the file that is already imported, the plain
+	// CSS import is still rendered as `@import`.
+	Once  bool
+	Token *Token
 }
 
 func (self ImportStatement) CanBeStatement() {}
 
-func (self ImportStatement) String() string {
-	return ""
+func (self ImportStatement) String() (out string) {
+	switch url := self.Url.(type) {
+	case Url:
+		out = "@import url(" + string(url) + ")"
+	case RelativeUrl:
+		out = "@import \"" + string(url) + "\""
+	}
+	for _, media := range self.MediaList {
+		out += " " + media
+	}
+	return out + ";"
+}
+
+/*
+ImportList is the import statement of several urls, `@import "a", "b";` is
+evaluated as the import statements of the urls in order.
+*/
+type ImportList struct {
+	Span
+
+	Imports []*ImportStatement
+}
+
+func (self ImportList) CanBeStatement() {}
+
+func (self ImportList) String() string {
+	var out = ""
+	for _, stm := range self.Imports {
+		out += stm.String()
+	}
+	return out
 }
 
 // for Url()
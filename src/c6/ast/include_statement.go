@@ -0,0 +1,75 @@
+package ast
+
+/*
+IncludeStatement presents the mixin inclusion, the content block is optional:
+
+	@include button(red, $padding: 5px);
+
+	@include media(screen) using ($type) {
+		color: red;
+	}
+*/
+type IncludeStatement struct {
+	Span
+
+	MixinName string
+
+	// The arguments can be positional expressions, *KeywordArgument or
+	// *RestArgument
+	Arguments []Expression
+
+	ContentBlock *DeclarationBlock
+
+	// The arguments declared by `using (...)` for the content block
+	ContentArgumentList *ArgumentList
+
+	Token *Token
+}
+
+func NewIncludeStatement(nameTok *Token) *IncludeStatement {
+	return &IncludeStatement{Span{TokenRange(nameTok)}, nameTok.Str, []Expression{}, nil, nil, nameTok}
+}
+
+func (self IncludeStatement) CanBeStatement()   {}
+func (self IncludeStatement) CanBeDeclaration() {}
+
+func (self *IncludeStatement) AppendArgument(arg Expression) {
+	self.Arguments = append(self.Arguments, arg)
+}
+
+func (self IncludeStatement) String() (out string) {
+	out = "@include " + self.MixinName
+	if len(self.Arguments) > 0 {
+		out += "("
+		for i, arg := range self.Arguments {
+			if i > 0 {
+				out += ", "
+			}
+			out += arg.String()
+		}
+		out += ")"
+	}
+	return out
+}
+
+/*
+ContentStatement presents the `@content` directive in the mixin, the
+arguments are passed to the `using (...)` of the content block.
+*/
+type ContentStatement struct {
+	Span
+
+	Arguments []Expression
+	Token     *Token
+}
+
+func NewContentStatement(token *Token) *ContentStatement {
+	return &ContentStatement{Span{TokenRange(token)}, []Expression{}, token}
+}
+
+func (self ContentStatement) CanBeStatement()   {}
+func (self ContentStatement) CanBeDeclaration() {}
+
+func (self ContentStatement) String() string {
+	return "@content"
+}
@@ -1,6 +1,8 @@
 package ast
 
 type Interpolation struct {
+	Span
+
 	Expression Expression
 	StartToken *Token
 	EndToken   *Token
@@ -13,5 +15,5 @@ func (self Interpolation) String() string {
 }
 
 func NewInterpolation(expr Expression, startToken *Token, endToken *Token) *Interpolation {
-	return &Interpolation{expr, startToken, endToken}
+	return &Interpolation{Span{TokenRange(startToken).Join(TokenRange(endToken))}, expr, startToken, endToken}
 }
@@ -1,12 +1,18 @@
 package ast
 
-import "strconv"
-import "fmt"
+import "strings"
 
 type Length struct {
+	Span
+
 	Value float64
 	Unit  UnitType
 	Token *Token
+
+	// The units of the compound length like `px*px/s`, the Unit is
+	// UNIT_NONE for the compound length.
+	Numerators   []UnitType
+	Denominators []UnitType
 }
 
 func (self Length) CanBeNode() {}
@@ -15,84 +21,237 @@ func (self Length) GetValueType() ValueType {
 }
 
 func (self Length) String() (out string) {
-	out += strconv.FormatFloat(self.Value, 'G', -1, 64)
+	out += FormatNumber(self.Value)
+	return out + self.UnitString()
+}
+
+// Units returns the numerator units and the denominator units of the length
+func (self Length) Units() ([]UnitType, []UnitType) {
 	if self.Unit != UNIT_NONE {
-		out += self.Unit.UnitString()
+		return []UnitType{self.Unit}, nil
 	}
-	return out
+	return self.Numerators, self.Denominators
+}
+
+func (self Length) IsUnitless() bool {
+	var numerators, denominators = self.Units()
+	return len(numerators) == 0 && len(denominators) == 0
+}
+
+// IsCompound reports whether the length has more than one unit, like `px*px`
+// or `1/s`, which can't be used in CSS.
+func (self Length) IsCompound() bool {
+	return self.Unit == UNIT_NONE && !self.IsUnitless()
+}
+
+/*
+UnitString returns the units as the Sass unit() function formats them:
+
+	px
+	px*px/s
+	s^-1
+	(px*s)^-1
+*/
+func (self Length) UnitString() string {
+	var numerators, denominators = self.Units()
+	var join = func(units []UnitType) string {
+		var strs []string
+		for _, unit := range units {
+			strs = append(strs, unit.UnitString())
+		}
+		return strings.Join(strs, "*")
+	}
+	if len(numerators) == 0 {
+		switch len(denominators) {
+		case 0:
+			return ""
+		case 1:
+			return join(denominators) + "^-1"
+		}
+		return "(" + join(denominators) + ")^-1"
+	}
+	if len(denominators) == 0 {
+		return join(numerators)
+	}
+	return join(numerators) + "/" + join(denominators)
 }
 
 func NewLength(val float64, unit UnitType, token *Token) *Length {
-	return &Length{val, unit, token}
+	return &Length{Span: Span{TokenRange(token)}, Value: val, Unit: unit, Token: token}
+}
+
+/*
+NewCompoundLength creates the length with the numerator units and the
+denominator units, the length of the single unit is created by NewLength.
+*/
+func NewCompoundLength(val float64, numerators []UnitType, denominators []UnitType, token *Token) *Length {
+	switch {
+	case len(numerators) == 0 && len(denominators) == 0:
+		return NewLength(val, UNIT_NONE, token)
+	case len(numerators) == 1 && len(denominators) == 0:
+		return NewLength(val, numerators[0], token)
+	}
+	return &Length{Span: Span{TokenRange(token)}, Value: val, Token: token, Numerators: numerators, Denominators: denominators}
+}
+
+// withValue creates the length of the value in the same units
+func (self *Length) withValue(val float64) *Length {
+	var numerators, denominators = self.Units()
+	return NewCompoundLength(val, numerators, denominators, nil)
+}
+
+/*
+convertUnits returns the factor to convert the value in the units to the
+target units, each unit is paired with a compatible unit of the target. It
+returns false if the units can't be paired.
+*/
+func convertUnits(units []UnitType, targets []UnitType) (float64, bool) {
+	if len(units) != len(targets) {
+		return 0, false
+	}
+	var factor = 1.0
+	var paired = make([]bool, len(targets))
+	for _, unit := range units {
+		var found = false
+		for i, target := range targets {
+			if paired[i] {
+				continue
+			}
+			if f, ok := ConversionFactor(unit, target); ok {
+				factor *= f
+				paired[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return factor, true
+}
+
+/*
+ConvertLength returns the value of the length in the units of the target
+length, e.g. 1in is 96 in px. It returns false if the units are
+incompatible.
+*/
+func ConvertLength(length *Length, target *Length) (float64, bool) {
+	var numerators, denominators = length.Units()
+	var targetNumerators, targetDenominators = target.Units()
+	nf, nok := convertUnits(numerators, targetNumerators)
+	df, dok := convertUnits(denominators, targetDenominators)
+	if !nok || !dok {
+		return 0, false
+	}
+	return length.Value * nf / df, true
+}
+
+/*
+coerceLengths returns the values of the lengths in the units of their sum,
+which are the units of the left length, the unitless length takes the units
+of the other one. It returns false if the units are incompatible.
+
+	1in + 2px  // 1in + 0.0208333in
+	10 + 2px   // 10px + 2px
+*/
+func coerceLengths(a *Length, b *Length) (x float64, y float64, units *Length, ok bool) {
+	if a.IsUnitless() {
+		return a.Value, b.Value, b, true
+	}
+	if b.IsUnitless() {
+		return a.Value, b.Value, a, true
+	}
+	y, ok = ConvertLength(b, a)
+	return a.Value, y, a, ok
 }
 
 func LengthSubLength(a *Length, b *Length) *Length {
-	if a.Unit != b.Unit {
-		fmt.Printf("Incompatible unit %s != %s.  %v - %v \n", a.Unit, b.Unit, a, b)
-		return nil
+	if x, y, units, ok := coerceLengths(a, b); ok {
+		return units.withValue(x - y)
 	}
-	var result = a.Value - b.Value
-	return NewLength(result, a.Unit, nil)
+	return nil
 }
 
 func LengthAddLength(a *Length, b *Length) *Length {
-	if a.Unit != b.Unit {
-		fmt.Printf("Incompatible unit %s != %s.  %v + %v \n", a.Unit, b.Unit, a, b)
-		return nil
+	if x, y, units, ok := coerceLengths(a, b); ok {
+		return units.withValue(x + y)
 	}
-	var result = a.Value + b.Value
-	return NewLength(result, a.Unit, nil)
+	return nil
 }
 
 /*
-10px / 3, 10 / 3, 10px / 10px is allowed here
+10px % 3, 10px % 3px is allowed here, the sign of the result follows the
+divisor.
 */
-func LengthDivLength(a *Length, b *Length) *Length {
-	if a.Unit == UNIT_NONE || b.Unit == UNIT_NONE || a.Unit == b.Unit {
-		var result = a.Value / b.Value
-		var unit = UNIT_NONE
-		if a.Unit != UNIT_NONE {
-			unit = a.Unit
-		}
-		if b.Unit != UNIT_NONE {
-			unit = b.Unit
-		}
-		return NewLength(result, unit, nil)
+func LengthModLength(a *Length, b *Length) *Length {
+	if x, y, units, ok := coerceLengths(a, b); ok {
+		return units.withValue(floorMod(x, y))
 	}
 	return nil
 }
 
+func concatUnits(a []UnitType, b []UnitType) []UnitType {
+	var units = make([]UnitType, 0, len(a)+len(b))
+	return append(append(units, a...), b...)
+}
+
 /*
-3 * 10px, 10px * 3, 10px * 10px is allowed here
+simplifyLength creates the length with the units, the compatible units of
+the numerators and the denominators are cancelled, e.g. 2px*in/px is 192px.
 */
-func LengthMulLength(a *Length, b *Length) *Length {
-	if a.Unit == UNIT_NONE || b.Unit == UNIT_NONE || a.Unit == b.Unit {
-		var result = a.Value * b.Value
-		var unit = UNIT_NONE
-		if a.Unit != UNIT_NONE {
-			unit = a.Unit
+func simplifyLength(val float64, numerators []UnitType, denominators []UnitType) *Length {
+	var remaining = concatUnits(denominators, nil)
+	var units []UnitType
+	for _, unit := range numerators {
+		var cancelled = false
+		for i, denominator := range remaining {
+			if f, ok := ConversionFactor(unit, denominator); ok {
+				val *= f
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				cancelled = true
+				break
+			}
 		}
-		if b.Unit != UNIT_NONE {
-			unit = b.Unit
+		if !cancelled {
+			units = append(units, unit)
 		}
-		return NewLength(result, unit, nil)
 	}
-	return nil
+	return NewCompoundLength(val, units, remaining, nil)
+}
+
+/*
+10px / 3, 10 / 3, 10px / 10px is allowed here, the compatible units are
+cancelled, and the others are kept as the compound units like px/s.
+*/
+func LengthDivLength(a *Length, b *Length) *Length {
+	var an, ad = a.Units()
+	var bn, bd = b.Units()
+	return simplifyLength(a.Value/b.Value, concatUnits(an, bd), concatUnits(ad, bn))
+}
+
+/*
+3 * 10px, 10px * 3, 10px * 10px is allowed here, 10px * 10px is 100px*px.
+*/
+func LengthMulLength(a *Length, b *Length) *Length {
+	var an, ad = a.Units()
+	var bn, bd = b.Units()
+	return simplifyLength(a.Value*b.Value, concatUnits(an, bn), concatUnits(ad, bd))
 }
 
 func LengthMulNumber(a *Length, b *Number) *Length {
-	return NewLength(a.Value*b.Value, a.Unit, nil)
+	return a.withValue(a.Value * b.Value)
 }
 
 func NumberMulLength(a *Number, b *Length) *Length {
-	return NewLength(a.Value*b.Value, b.Unit, nil)
+	return b.withValue(a.Value * b.Value)
 }
 
 func LengthDivNumber(a *Length, b *Number) *Length {
-	return NewLength(a.Value/b.Value, a.Unit, nil)
+	return a.withValue(a.Value / b.Value)
 }
 
 func NumberDivLength(a *Number, b *Length) *Length {
-	panic("Number can't be divided by length")
-	return nil
+	var bn, bd = b.Units()
+	return simplifyLength(a.Value/b.Value, concatUnits(bd, nil), concatUnits(bn, nil))
 }
@@ -0,0 +1,75 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestConversionFactor(t *testing.T) {
+	var cases = []struct {
+		from, to UnitType
+		factor   float64
+	}{
+		{UNIT_IN, UNIT_PX, 96},
+		{UNIT_PC, UNIT_PT, 12},
+		{UNIT_CM, UNIT_MM, 10},
+		{UNIT_MM, UNIT_Q, 4},
+		{UNIT_TURN, UNIT_DEG, 360},
+		{UNIT_DEG, UNIT_GRAD, 400.0 / 360},
+		{UNIT_SECOND, UNIT_MILLISECOND, 1000},
+		{UNIT_KHZ, UNIT_HZ, 1000},
+		{UNIT_DPPX, UNIT_DPI, 96},
+		{UNIT_EM, UNIT_EM, 1},
+	}
+	for _, c := range cases {
+		factor, ok := ConversionFactor(c.from, c.to)
+		assert.True(t, ok)
+		assert.InDelta(t, c.factor, factor, 1e-9, "%s to %s", c.from.UnitString(), c.to.UnitString())
+	}
+
+	for _, pair := range [][2]UnitType{{UNIT_PX, UNIT_EM}, {UNIT_PX, UNIT_SECOND}, {UNIT_PERCENT, UNIT_PX}} {
+		_, ok := ConversionFactor(pair[0], pair[1])
+		assert.False(t, ok)
+	}
+}
+
+func TestLengthUnitString(t *testing.T) {
+	assert.Equal(t, "px", NewLength(1, UNIT_PX, nil).UnitString())
+	assert.Equal(t, "", NewLength(1, UNIT_NONE, nil).UnitString())
+	assert.Equal(t, "px*px/s", NewCompoundLength(1, []UnitType{UNIT_PX, UNIT_PX}, []UnitType{UNIT_SECOND}, nil).UnitString())
+	assert.Equal(t, "s^-1", NewCompoundLength(1, nil, []UnitType{UNIT_SECOND}, nil).UnitString())
+	assert.Equal(t, "(px*s)^-1", NewCompoundLength(1, nil, []UnitType{UNIT_PX, UNIT_SECOND}, nil).UnitString())
+	assert.Equal(t, "2kHz", NewLength(2, UNIT_KHZ, nil).String())
+}
+
+func TestLengthConversion(t *testing.T) {
+	var sum = LengthAddLength(NewLength(1, UNIT_IN, nil), NewLength(48, UNIT_PX, nil))
+	assert.Equal(t, "1.5in", sum.String())
+
+	sum = LengthAddLength(NewLength(10, UNIT_NONE, nil), NewLength(2, UNIT_PX, nil))
+	assert.Equal(t, "12px", sum.String())
+
+	assert.Nil(t, LengthAddLength(NewLength(1, UNIT_PX, nil), NewLength(1, UNIT_EM, nil)))
+
+	var value, ok = ConvertLength(NewLength(500, UNIT_MILLISECOND, nil), NewLength(1, UNIT_SECOND, nil))
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, value)
+}
+
+func TestLengthCompoundUnits(t *testing.T) {
+	var area = LengthMulLength(NewLength(2, UNIT_PX, nil), NewLength(3, UNIT_PX, nil))
+	assert.True(t, area.IsCompound())
+	assert.Equal(t, "6px*px", area.String())
+
+	var speed = LengthDivLength(area, NewLength(2, UNIT_SECOND, nil))
+	assert.Equal(t, "3px*px/s", speed.String())
+
+	// the compatible units are cancelled with the conversion
+	var length = LengthDivLength(speed, LengthDivLength(NewLength(1, UNIT_IN, nil), NewLength(500, UNIT_MILLISECOND, nil)))
+	assert.False(t, length.IsCompound())
+	assert.InDelta(t, 0.015625, length.Value, 1e-9)
+	assert.Equal(t, UNIT_PX, length.Unit)
+
+	var frequency = NumberDivLength(NewNumber(1, nil), NewLength(4, UNIT_SECOND, nil))
+	assert.Equal(t, "0.25s^-1", frequency.String())
+
+	assert.Nil(t, LengthAddLength(area, NewLength(1, UNIT_PX, nil)))
+}
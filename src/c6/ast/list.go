@@ -3,6 +3,8 @@ package ast
 import "strings"
 
 type List struct {
+	Span
+
 	Separator   string
 	Expressions []Expression
 }
@@ -30,5 +32,18 @@ func (list *List) Append(expr Expression) {
 
 // By the default, the separator is space
 func NewList() *List {
-	return &List{" ", []Expression{}}
+	return &List{Span{}, " ", []Expression{}}
+}
+
+/*
+NewSlashList creates the slash-separated list, the items are separated by '/'
+without spaces like `12px/1.5`.
+*/
+func NewSlashList(items ...Expression) *List {
+	return &List{Span{}, "/", append([]Expression{}, items...)}
+}
+
+// IsSlashSeparated reports whether the list is separated by '/'
+func (list *List) IsSlashSeparated() bool {
+	return list.Separator == "/"
 }
@@ -1,19 +1,59 @@
 package ast
 
+/*
+Map presents the map value, the keys are kept in the declared order:
+
+	(primary: #333, secondary: #666)
+*/
 type Map struct {
+	Span
+
+	Keys   []Expression
+	Values []Expression
 }
 
-func (self *Map) Set() {
+/*
+Set the value of the key, the keys are compared by their string form.
+*/
+func (self *Map) Set(key Expression, value Expression) {
+	for i, k := range self.Keys {
+		if k.String() == key.String() {
+			self.Values[i] = value
+			return
+		}
+	}
+	self.Keys = append(self.Keys, key)
+	self.Values = append(self.Values, value)
+}
 
+func (self *Map) Get(key Expression) Expression {
+	for i, k := range self.Keys {
+		if k.String() == key.String() {
+			return self.Values[i]
+		}
+	}
+	return nil
 }
 
-func (self *Map) Get() {
+func (self *Map) Len() int {
+	return len(self.Keys)
 }
 
 func (self Map) GetValueType() ValueType {
 	return MapValue
 }
 
+func (self Map) String() (out string) {
+	out = "("
+	for i, key := range self.Keys {
+		if i > 0 {
+			out += ", "
+		}
+		out += key.String() + ": " + self.Values[i].String()
+	}
+	return out + ")"
+}
+
 func NewMap() *Map {
-	return &Map{}
+	return &Map{Span{}, []Expression{}, []Expression{}}
 }
@@ -0,0 +1,27 @@
+package ast
+
+/*
+MediaStatement presents the `@media` block, the query is kept as it is
+written:
+
+	@media screen and (max-width: 100px) {
+		.foo { width: 100%; }
+	}
+*/
+type MediaStatement struct {
+	Span
+
+	Query string
+	Block *Block
+	Token *Token
+}
+
+func NewMediaStatement(query string, token *Token) *MediaStatement {
+	return &MediaStatement{Span{TokenRange(token)}, query, &Block{}, token}
+}
+
+func (self MediaStatement) CanBeStatement() {}
+
+func (self MediaStatement) String() string {
+	return "@media " + self.Query
+}
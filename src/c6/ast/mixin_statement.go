@@ -0,0 +1,29 @@
+package ast
+
+/*
+MixinStatement presents the mixin declaration:
+
+	@mixin button($color, $padding: 10px) {
+		color: $color;
+		padding: $padding;
+	}
+*/
+type MixinStatement struct {
+	Span
+
+	Name         string
+	ArgumentList *ArgumentList
+	Block        *DeclarationBlock
+	Token        *Token
+}
+
+func NewMixinStatement(nameTok *Token) *MixinStatement {
+	return &MixinStatement{Span{TokenRange(nameTok)}, nameTok.Str, NewArgumentList(), nil, nameTok}
+}
+
+func (self MixinStatement) CanBeStatement()   {}
+func (self MixinStatement) CanBeDeclaration() {}
+
+func (self MixinStatement) String() string {
+	return "@mixin " + self.Name + self.ArgumentList.String()
+}
@@ -1,18 +1,21 @@
 package ast
 
+import "math"
 import "strconv"
 
 type Number struct {
+	Span
+
 	Value float64
 	Token *Token
 }
 
 func NewNumberInt64(num int64, token *Token) *Number {
-	return &Number{float64(num), token}
+	return &Number{Span{TokenRange(token)}, float64(num), token}
 }
 
 func NewNumber(num float64, token *Token) *Number {
-	return &Number{num, token}
+	return &Number{Span{TokenRange(token)}, num, token}
 }
 
 func (self Number) GetValueType() ValueType {
@@ -20,7 +23,31 @@ func (self Number) GetValueType() ValueType {
 }
 
 func (num Number) String() (out string) {
-	return strconv.FormatFloat(num.Value, 'G', -1, 64)
+	return FormatNumber(num.Value)
+}
+
+/*
+FormatNumber formats the number as Sass does, the infinities and NaN of the
+division by zero are `Infinity`, `-Infinity` and `NaN`.
+*/
+func FormatNumber(val float64) string {
+	if s, ok := FormatNonFinite(val); ok {
+		return s
+	}
+	return strconv.FormatFloat(val, 'G', -1, 64)
+}
+
+// FormatNonFinite formats the infinities and NaN, it returns false for the finite number
+func FormatNonFinite(val float64) (string, bool) {
+	switch {
+	case math.IsNaN(val):
+		return "NaN", true
+	case math.IsInf(val, 1):
+		return "Infinity", true
+	case math.IsInf(val, -1):
+		return "-Infinity", true
+	}
+	return "", false
 }
 
 /*
@@ -34,15 +61,14 @@ Valid expression:
 	#bb - #cc
 	3px - 3px
 	3px + 3px
+	3px - 3
 
 Invalid expression
 
-- Number can't be the dividend.
-- Hex Color can't divisor.
+- Number can't be the dividend, `3 / #aaa` and `3 - #bbb` are strings.
+- The color can't be computed with the length that has unit.
 
-	3 / #aaa
-	3 - #bbb
-	3px - 3
+	#aaa + 3px
 	6em - 3px
 
 */
@@ -73,3 +99,7 @@ func NumberAddNumber(a *Number, b *Number) *Number {
 	var result = a.Value + b.Value
 	return NewNumber(result, nil)
 }
+
+func NumberModNumber(a *Number, b *Number) *Number {
+	return NewNumber(floorMod(a.Value, b.Value), nil)
+}
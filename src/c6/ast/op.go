@@ -12,6 +12,16 @@ const (
 	OpPow
 	OpConcat
 	OpLiteralConcat
+	OpMod
+	OpNot
+	OpEq
+	OpNe
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+	OpAnd
+	OpOr
 )
 
 func ConvertTokenTypeToOpType(tokenType TokenType) OpType {
@@ -24,6 +34,26 @@ func ConvertTokenTypeToOpType(tokenType TokenType) OpType {
 		return OpMul
 	case T_DIV:
 		return OpDiv
+	case T_MOD:
+		return OpMod
+	case T_NOT:
+		return OpNot
+	case T_EQ:
+		return OpEq
+	case T_NE:
+		return OpNe
+	case T_LT:
+		return OpLt
+	case T_LE:
+		return OpLe
+	case T_GT:
+		return OpGt
+	case T_GE:
+		return OpGe
+	case T_AND:
+		return OpAnd
+	case T_OR:
+		return OpOr
 	case T_LITERAL_CONCAT:
 		return OpConcat
 		// return OpLiteralConcat
@@ -32,11 +62,50 @@ func ConvertTokenTypeToOpType(tokenType TokenType) OpType {
 	return OpNone
 }
 
+// Symbol returns the operator symbol used in the stylesheet
+func (op OpType) Symbol() string {
+	switch op {
+	case OpAdd:
+		return "+"
+	case OpSub:
+		return "-"
+	case OpDiv:
+		return "/"
+	case OpMul:
+		return "*"
+	case OpPow:
+		return "^"
+	case OpMod:
+		return "%"
+	case OpNot:
+		return "not"
+	case OpEq:
+		return "=="
+	case OpNe:
+		return "!="
+	case OpLt:
+		return "<"
+	case OpLe:
+		return "<="
+	case OpGt:
+		return ">"
+	case OpGe:
+		return ">="
+	case OpAnd:
+		return "and"
+	case OpOr:
+		return "or"
+	}
+	return ""
+}
+
 type Op struct {
+	Span
+
 	Type  OpType
 	Token *Token
 }
 
 func NewOp(opType OpType, token *Token) *Op {
-	return &Op{opType, token}
+	return &Op{Span{TokenRange(token)}, opType, token}
 }
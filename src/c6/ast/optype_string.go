@@ -1,16 +1,41 @@
-// generated by stringer -type=OpType op.go token.go; DO NOT EDIT
+// Code generated by "stringer -type=OpType op.go token.go"; DO NOT EDIT.
 
 package ast
 
-import "fmt"
+import "strconv"
 
-const _OpType_name = "OpNoneOpAddOpSubOpDivOpMulOpPowOpConcatOpLiteralConcat"
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[OpNone-0]
+	_ = x[OpAdd-1]
+	_ = x[OpSub-2]
+	_ = x[OpDiv-3]
+	_ = x[OpMul-4]
+	_ = x[OpPow-5]
+	_ = x[OpConcat-6]
+	_ = x[OpLiteralConcat-7]
+	_ = x[OpMod-8]
+	_ = x[OpNot-9]
+	_ = x[OpEq-10]
+	_ = x[OpNe-11]
+	_ = x[OpLt-12]
+	_ = x[OpLe-13]
+	_ = x[OpGt-14]
+	_ = x[OpGe-15]
+	_ = x[OpAnd-16]
+	_ = x[OpOr-17]
+}
+
+const _OpType_name = "OpNoneOpAddOpSubOpDivOpMulOpPowOpConcatOpLiteralConcatOpModOpNotOpEqOpNeOpLtOpLeOpGtOpGeOpAndOpOr"
 
-var _OpType_index = [...]uint8{0, 6, 11, 16, 21, 26, 31, 39, 54}
+var _OpType_index = [...]uint8{0, 6, 11, 16, 21, 26, 31, 39, 54, 59, 64, 68, 72, 76, 80, 84, 88, 93, 97}
 
 func (i OpType) String() string {
-	if i < 0 || i+1 >= OpType(len(_OpType_index)) {
-		return fmt.Sprintf("OpType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_OpType_index)-1 {
+		return "OpType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _OpType_name[_OpType_index[i]:_OpType_index[i+1]]
+	return _OpType_name[_OpType_index[idx]:_OpType_index[idx+1]]
 }
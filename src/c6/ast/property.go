@@ -4,6 +4,8 @@ package ast
 An property may contains interpolation
 */
 type Property struct {
+	Span
+
 	Name *PropertyName
 	/**
 	property value can be something like:
@@ -18,11 +20,13 @@ Property is one of the declaration
 */
 func (self Property) CanBeDeclaration() {}
 
-func (self Property) AppendValue(value Expression) {
+func (self *Property) AppendValue(value Expression) {
 	self.Values = append(self.Values, value)
 }
 
 type PropertyName struct {
+	Span
+
 	String string
 	// If there is an interpolation in the property name
 	Interpolation bool
@@ -30,9 +34,9 @@ type PropertyName struct {
 }
 
 func NewPropertyName(tok *Token) *PropertyName {
-	return &PropertyName{tok.Str, tok.ContainsInterpolation, tok}
+	return &PropertyName{Span{TokenRange(tok)}, tok.Str, tok.ContainsInterpolation, tok}
 }
 
 func NewProperty(nameTok *Token) *Property {
-	return &Property{NewPropertyName(nameTok), []Expression{}}
+	return &Property{Span{TokenRange(nameTok)}, NewPropertyName(nameTok), []Expression{}}
 }
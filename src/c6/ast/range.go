@@ -0,0 +1,114 @@
+package ast
+
+import "fmt"
+import "reflect"
+
+/*
+Position is the position in the source code, the line and the column start
+from 1, and the column counts the runes.
+*/
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+/*
+Range is the range of the source code from Start to End, End is exclusive.
+The file is empty for the code that is not from a file.
+*/
+type Range struct {
+	File  string
+	Start Position
+	End   Position
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("%s-%s", r.Start, r.End)
+}
+
+// IsValid returns true if the range is set
+func (r Range) IsValid() bool {
+	return r.Start.Line > 0
+}
+
+/*
+Join returns the range from the start of the range to the end of the other
+range, the unset range is ignored.
+*/
+func (r Range) Join(other Range) Range {
+	if !r.IsValid() {
+		return other
+	}
+	if !other.IsValid() {
+		return r
+	}
+	if other.Start.Offset < r.Start.Offset {
+		r.Start = other.Start
+	}
+	if other.End.Offset > r.End.Offset {
+		r.End = other.End
+	}
+	return r
+}
+
+/*
+Span is embedded in the nodes to keep their source range, the range is set
+by the parser. The nodes created by the evaluator have no range unless it's
+copied from the parsed node.
+*/
+type Span struct {
+	SourceRange Range
+}
+
+func (span *Span) GetRange() Range {
+	return span.SourceRange
+}
+
+func (span *Span) SetRange(r Range) {
+	span.SourceRange = r
+}
+
+// RangedNode is implemented by the nodes with the Span
+type RangedNode interface {
+	GetRange() Range
+	SetRange(r Range)
+}
+
+/*
+NodeRange returns the source range of the node, the range is empty for the
+nodes without the range. The selectors are passed by value, their range is
+read from the Span of the value.
+*/
+func NodeRange(node Node) Range {
+	if ranged, ok := node.(RangedNode); ok && !reflect.ValueOf(node).IsNil() {
+		return ranged.GetRange()
+	}
+	if node != nil && reflect.TypeOf(node).Kind() == reflect.Struct {
+		var ptr = reflect.New(reflect.TypeOf(node))
+		ptr.Elem().Set(reflect.ValueOf(node))
+		if ranged, ok := ptr.Interface().(RangedNode); ok {
+			return ranged.GetRange()
+		}
+	}
+	return Range{}
+}
+
+// TokenRange returns the range of the token, it's empty for the nil token
+func TokenRange(token *Token) Range {
+	if token == nil {
+		return Range{}
+	}
+	return token.Range
+}
+
+// SetNodeRange sets the range of the node if it keeps the range
+func SetNodeRange(node Node, r Range) {
+	if ranged, ok := node.(RangedNode); ok && !reflect.ValueOf(node).IsNil() {
+		ranged.SetRange(r)
+	}
+}
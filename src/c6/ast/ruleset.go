@@ -1,6 +1,8 @@
 package ast
 
 type RuleSet struct {
+	Span
+
 	Selectors        []Selector
 	DeclarationBlock *DeclarationBlock
 	SymTable         *SymTable
@@ -18,13 +20,26 @@ func (self *RuleSet) AppendSubRuleSet(ruleset *RuleSet) {
 	self.DeclarationBlock.AppendSubRuleSet(ruleset)
 }
 
+/*
+GetSubRuleSets returns the nested rulesets, the parsed ones in the
+declarations and the evaluated ones.
+*/
 func (self *RuleSet) GetSubRuleSets() []*RuleSet {
-	return self.DeclarationBlock.SubRuleSets
+	var rulesets = []*RuleSet{}
+	for _, decl := range self.DeclarationBlock.Declarations {
+		if ruleset, ok := decl.(*RuleSet); ok {
+			rulesets = append(rulesets, ruleset)
+		}
+	}
+	return append(rulesets, self.DeclarationBlock.SubRuleSets...)
 }
 
 // Complete the statement interface
 func (self *RuleSet) CanBeStatement() {}
 
+// The nested ruleset is a declaration of the enclosing block
+func (self *RuleSet) CanBeDeclaration() {}
+
 func (self RuleSet) String() string {
 	return ""
 }
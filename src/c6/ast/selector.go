@@ -74,33 +74,49 @@ func (self AdjacentSelector) String() string { return " + " }
 TypeSelector
 */
 type TypeSelector struct {
+	Span
 	Type string
 }
 
+func NewTypeSelector(token *Token) TypeSelector {
+	return TypeSelector{Span{TokenRange(token)}, token.Str}
+}
+
 func (self TypeSelector) IsSelector() {}
 func (self TypeSelector) String() string {
 	return self.Type
 }
 
 type IdSelector struct {
+	Span
 	Id string
 }
 
+func NewIdSelector(token *Token) IdSelector {
+	return IdSelector{Span{TokenRange(token)}, token.Str}
+}
+
 func (self IdSelector) IsSelector() {}
 func (self IdSelector) String() string {
 	return "#" + self.Id
 }
 
 type ClassSelector struct {
+	Span
 	ClassName string
 }
 
+func NewClassSelector(token *Token) ClassSelector {
+	return ClassSelector{Span{TokenRange(token)}, token.Str}
+}
+
 func (self ClassSelector) IsSelector() {}
 func (self ClassSelector) String() string {
 	return "." + self.ClassName
 }
 
 type AttributeSelector struct {
+	Span
 	Name    string
 	Op      string
 	Pattern string
@@ -115,17 +131,71 @@ func (self AttributeSelector) String() (out string) {
 }
 
 /*
-This is a SCSS only selector
+PlaceholderSelector presents the SASS placeholder selector `%name`, the
+rulesets with placeholders are only rendered through @extend.
+*/
+type PlaceholderSelector struct {
+	Span
+	Name string
+}
+
+func NewPlaceholderSelector(token *Token) PlaceholderSelector {
+	return PlaceholderSelector{Span{TokenRange(token)}, token.Str}
+}
+
+func (self PlaceholderSelector) IsSelector() {}
+func (self PlaceholderSelector) String() string {
+	return "%" + self.Name
+}
+
+/*
+ParentSelector presents the SCSS parent selector `&`, it's replaced with the
+selectors of the parent ruleset in the evaluation. The suffix is appended to
+the last selector of the parent, e.g. `&-item`.
 */
 type ParentSelector struct {
+	Span
 	ParentRuleSet *RuleSet
+	Suffix        string
+}
+
+func NewParentSelector(parentRuleSet *RuleSet, token *Token) ParentSelector {
+	return ParentSelector{Span{TokenRange(token)}, parentRuleSet, token.Str[1:]}
 }
 
 func (self ParentSelector) IsSelector() {}
 func (self ParentSelector) String() string {
-	// TODO: get parent rule set and render the selector...
-	panic("unimplemented")
-	return ""
+	return "&" + self.Suffix
+}
+
+/*
+ComplexSelector presents one selector of a comma-separated selector list,
+for example `div.foo > a:hover`. The combinators (DescendantSelector,
+ChildSelector, AdjacentSelector) are kept in the sequence.
+*/
+type ComplexSelector struct {
+	Span
+	Selectors []Selector
+}
+
+func NewComplexSelector() *ComplexSelector {
+	return &ComplexSelector{}
+}
+
+func (self *ComplexSelector) AppendSelector(sel Selector) {
+	self.Selectors = append(self.Selectors, sel)
+}
+
+func (self *ComplexSelector) Len() int {
+	return len(self.Selectors)
+}
+
+func (self ComplexSelector) IsSelector() {}
+func (self ComplexSelector) String() (out string) {
+	for _, sel := range self.Selectors {
+		out += sel.String()
+	}
+	return out
 }
 
 /**
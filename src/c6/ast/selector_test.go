@@ -4,10 +4,10 @@ import "github.com/stretchr/testify/assert"
 import "testing"
 
 func TestCombinedSelector(t *testing.T) {
-	e := TypeSelector{"div"}
-	cls1 := ClassSelector{"foo"}
-	cls2 := ClassSelector{"bar"}
-	id := IdSelector{"myId"}
+	e := TypeSelector{Type: "div"}
+	cls1 := ClassSelector{ClassName: "foo"}
+	cls2 := ClassSelector{ClassName: "bar"}
+	id := IdSelector{Id: "myId"}
 
 	assert.Equal(t, ".foo", cls1.String())
 	assert.Equal(t, ".bar", cls2.String())
@@ -1,21 +1,26 @@
 package ast
 
 type String struct {
+	Span
+
 	// Can be `"`, `'` or ``
 	Quote byte
 	Value string
 	Token *Token
 }
 
+func (self String) GetValueType() ValueType {
+	return StringValue
+}
 
 func (self String) String() string {
 	return self.Value
 }
 
 func NewStringWithQuote(quote byte, token *Token) *String {
-	return &String{quote, token.Str, token}
+	return &String{Span{TokenRange(token)}, quote, token.Str, token}
 }
 
 func NewString(token *Token) *String {
-	return &String{0, token.Str, token}
+	return &String{Span{TokenRange(token)}, 0, token.Str, token}
 }
@@ -1,7 +1,8 @@
 package ast
 
 /*
-XXX: This smells bad, and but we don't want to put all ast node types in one classes...
+SymTable maps the variable names to the variables of one scope, the scope
+chain is maintained by the evaluation context.
 */
 type SymTable map[string]*Variable
 
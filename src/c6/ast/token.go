@@ -9,10 +9,16 @@ const LF = '\r'
 const CR = '\n'
 
 type Token struct {
-	Type                  TokenType
-	Str                   string
-	Pos                   int
-	Line                  int
+	Type TokenType
+	Str  string
+
+	// The byte offset and the line of the token start, the line starts from 0
+	Pos  int
+	Line int
+
+	// The range of the token in the source file
+	Range Range
+
 	ContainsInterpolation bool
 }
 
@@ -40,12 +46,18 @@ func (tok Token) IsSelector() bool {
 		tok.Type == T_CLASS_SELECTOR ||
 		tok.Type == T_PARENT_SELECTOR ||
 		tok.Type == T_PSEUDO_SELECTOR ||
+		tok.Type == T_PLACEHOLDER_SELECTOR ||
 		tok.Type == T_ADJACENT_SELECTOR ||
 		tok.Type == T_CHILD_SELECTOR ||
 		tok.Type == T_DESCENDANT_SELECTOR ||
 		tok.Type == T_PARENT_SELECTOR
 }
 
+// IsUnit reports whether the token is the unit of the number
+func (tok Token) IsUnit() bool {
+	return tok.Type >= T_UNIT_PERCENT && tok.Type <= T_UNIT_TURN
+}
+
 func (tok Token) IsOneOfTypes(types []TokenType) bool {
 	for _, t := range types {
 		if tok.Type == t {
@@ -79,6 +91,7 @@ const (
 	T_UNIVERSAL_SELECTOR
 	T_PARENT_SELECTOR        // SASS parent selector
 	T_PSEUDO_SELECTOR        // :hover, :visited , ...
+	T_PLACEHOLDER_SELECTOR   // SASS placeholder selector: %foo
 	T_INTERPOLATION_SELECTOR // selector with interpolation: '#{ ... }'
 	T_LITERAL_CONCAT         // used to concat selectors and interpolation
 
@@ -95,12 +108,25 @@ const (
 
 	T_IF
 	T_ELSE
+	T_EACH    // @each $var in $list
+	T_IN      // 'in' of @each
+	T_FOR     // @for $i from 1 through 3
+	T_FROM    // 'from' of @for
+	T_THROUGH // 'through' of @for, the end is included
+	T_TO      // 'to' of @for, the end is excluded
+	T_WHILE
 
 	T_OR  // 'or' used in conditional query
 	T_AND // 'and' used in conditional query
 	T_XOR
+	T_NOT  // 'not' of the expression
 	T_PLUS // E '+' F
-	T_GT   // E '>' F
+	T_GT   // E '>' F, and '>' of the expression
+	T_GE   // '>='
+	T_LT   // '<'
+	T_LE   // '<='
+	T_EQ   // '=='
+	T_NE   // '!='
 	T_BRACE_START
 	T_BRACE_END
 	T_LANG_CODE // 'en', 'fr', 'fr-ca'
@@ -113,8 +139,30 @@ const (
 	T_VARIABLE
 
 	T_IMPORT
+	T_IMPORT_ONCE // @import-once
 	T_AT_RULE
 
+	T_MIXIN   // @mixin
+	T_INCLUDE // @include
+	T_CONTENT // @content
+	T_USING   // 'using' for passing arguments to the content block
+
+	T_FUNCTION // @function
+	T_RETURN   // @return
+	T_WARN     // @warn
+
+	T_EXTEND   // @extend
+	T_OPTIONAL // '!optional' of @extend
+	T_DEFAULT  // '!default' of variable assignment
+	T_GLOBAL   // '!global' of variable assignment
+
+	T_USE     // @use
+	T_FORWARD // @forward
+	T_AS      // 'as' of @use and @forward
+	T_WITH    // 'with' of @use
+	T_SHOW    // 'show' of @forward
+	T_HIDE    // 'hide' of @forward
+
 	T_CHARSET
 	T_QQ_STRING
 	T_Q_STRING
@@ -138,6 +186,7 @@ const (
 	T_UNIT_PC
 	T_UNIT_PT
 	T_UNIT_PX
+	T_UNIT_Q
 	T_UNIT_REM
 
 	T_UNIT_HZ
@@ -170,4 +219,7 @@ const (
 	T_DIV
 	T_MUL
 	T_MINUS
+	T_MOD
+	T_ELLIPSIS // '...' for variable arguments
+	T_ERROR    // the error of the lexer, the message is in Str
 )
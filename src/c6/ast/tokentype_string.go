@@ -1,16 +1,150 @@
-// generated by stringer -type=TokenType token.go; DO NOT EDIT
+// Code generated by "stringer -type=TokenType token.go"; DO NOT EDIT.
 
 package ast
 
-import "fmt"
+import "strconv"
 
-const _TokenType_name = "T_SPACET_COMMENT_LINET_COMMENT_BLOCKT_SEMICOLONT_COMMAT_IDENTT_URLT_MEDIAT_TRUET_FALSET_NULLT_MS_PARAM_NAMET_FUNCTION_NAMET_ID_SELECTORT_CLASS_SELECTORT_TYPE_SELECTORT_UNIVERSAL_SELECTORT_PARENT_SELECTORT_PSEUDO_SELECTORT_INTERPOLATION_SELECTORT_LITERAL_CONCATT_MS_PROGIDT_AND_SELECTORT_DESCENDANT_SELECTORT_CHILD_SELECTORT_ADJACENT_SELECTORT_UNICODE_RANGET_IFT_ELSET_ORT_ANDT_XORT_PLUST_GTT_BRACE_STARTT_BRACE_ENDT_LANG_CODET_BRACKET_LEFTT_ATTRIBUTE_NAMET_BRACKET_RIGHTT_EQUALT_TILDE_EQUALT_PIPE_EQUALT_VARIABLET_IMPORTT_AT_RULET_CHARSETT_QQ_STRINGT_Q_STRINGT_UNQUOTE_STRINGT_PAREN_STARTT_PAREN_ENDT_CONSTANTT_INTEGERT_FLOATT_UNIT_PERCENTT_UNIT_SECONDT_UNIT_MILLISECONDT_UNIT_CHT_UNIT_CMT_UNIT_EMT_UNIT_EXT_UNIT_INT_UNIT_MMT_UNIT_PCT_UNIT_PTT_UNIT_PXT_UNIT_REMT_UNIT_HZT_UNIT_KHZT_UNIT_DPIT_UNIT_DPCMT_UNIT_DPPXT_UNIT_VHT_UNIT_VWT_UNIT_VMINT_UNIT_VMAXT_UNIT_DEGT_UNIT_GRADT_UNIT_RADT_UNIT_TURNT_PROPERTY_NAME_TOKENT_PROPERTY_VALUET_HEX_COLORT_COLONT_INTERPOLATION_STARTT_INTERPOLATION_INNERT_INTERPOLATION_ENDT_DIVT_MULT_MINUS"
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[T_SPACE-0]
+	_ = x[T_COMMENT_LINE-1]
+	_ = x[T_COMMENT_BLOCK-2]
+	_ = x[T_SEMICOLON-3]
+	_ = x[T_COMMA-4]
+	_ = x[T_IDENT-5]
+	_ = x[T_URL-6]
+	_ = x[T_MEDIA-7]
+	_ = x[T_TRUE-8]
+	_ = x[T_FALSE-9]
+	_ = x[T_NULL-10]
+	_ = x[T_MS_PARAM_NAME-11]
+	_ = x[T_FUNCTION_NAME-12]
+	_ = x[T_ID_SELECTOR-13]
+	_ = x[T_CLASS_SELECTOR-14]
+	_ = x[T_TYPE_SELECTOR-15]
+	_ = x[T_UNIVERSAL_SELECTOR-16]
+	_ = x[T_PARENT_SELECTOR-17]
+	_ = x[T_PSEUDO_SELECTOR-18]
+	_ = x[T_PLACEHOLDER_SELECTOR-19]
+	_ = x[T_INTERPOLATION_SELECTOR-20]
+	_ = x[T_LITERAL_CONCAT-21]
+	_ = x[T_MS_PROGID-22]
+	_ = x[T_AND_SELECTOR-23]
+	_ = x[T_DESCENDANT_SELECTOR-24]
+	_ = x[T_CHILD_SELECTOR-25]
+	_ = x[T_ADJACENT_SELECTOR-26]
+	_ = x[T_UNICODE_RANGE-27]
+	_ = x[T_IF-28]
+	_ = x[T_ELSE-29]
+	_ = x[T_EACH-30]
+	_ = x[T_IN-31]
+	_ = x[T_FOR-32]
+	_ = x[T_FROM-33]
+	_ = x[T_THROUGH-34]
+	_ = x[T_TO-35]
+	_ = x[T_WHILE-36]
+	_ = x[T_OR-37]
+	_ = x[T_AND-38]
+	_ = x[T_XOR-39]
+	_ = x[T_NOT-40]
+	_ = x[T_PLUS-41]
+	_ = x[T_GT-42]
+	_ = x[T_GE-43]
+	_ = x[T_LT-44]
+	_ = x[T_LE-45]
+	_ = x[T_EQ-46]
+	_ = x[T_NE-47]
+	_ = x[T_BRACE_START-48]
+	_ = x[T_BRACE_END-49]
+	_ = x[T_LANG_CODE-50]
+	_ = x[T_BRACKET_LEFT-51]
+	_ = x[T_ATTRIBUTE_NAME-52]
+	_ = x[T_BRACKET_RIGHT-53]
+	_ = x[T_EQUAL-54]
+	_ = x[T_TILDE_EQUAL-55]
+	_ = x[T_PIPE_EQUAL-56]
+	_ = x[T_VARIABLE-57]
+	_ = x[T_IMPORT-58]
+	_ = x[T_IMPORT_ONCE-59]
+	_ = x[T_AT_RULE-60]
+	_ = x[T_MIXIN-61]
+	_ = x[T_INCLUDE-62]
+	_ = x[T_CONTENT-63]
+	_ = x[T_USING-64]
+	_ = x[T_FUNCTION-65]
+	_ = x[T_RETURN-66]
+	_ = x[T_WARN-67]
+	_ = x[T_EXTEND-68]
+	_ = x[T_OPTIONAL-69]
+	_ = x[T_DEFAULT-70]
+	_ = x[T_GLOBAL-71]
+	_ = x[T_USE-72]
+	_ = x[T_FORWARD-73]
+	_ = x[T_AS-74]
+	_ = x[T_WITH-75]
+	_ = x[T_SHOW-76]
+	_ = x[T_HIDE-77]
+	_ = x[T_CHARSET-78]
+	_ = x[T_QQ_STRING-79]
+	_ = x[T_Q_STRING-80]
+	_ = x[T_UNQUOTE_STRING-81]
+	_ = x[T_PAREN_START-82]
+	_ = x[T_PAREN_END-83]
+	_ = x[T_CONSTANT-84]
+	_ = x[T_INTEGER-85]
+	_ = x[T_FLOAT-86]
+	_ = x[T_UNIT_PERCENT-87]
+	_ = x[T_UNIT_SECOND-88]
+	_ = x[T_UNIT_MILLISECOND-89]
+	_ = x[T_UNIT_CH-90]
+	_ = x[T_UNIT_CM-91]
+	_ = x[T_UNIT_EM-92]
+	_ = x[T_UNIT_EX-93]
+	_ = x[T_UNIT_IN-94]
+	_ = x[T_UNIT_MM-95]
+	_ = x[T_UNIT_PC-96]
+	_ = x[T_UNIT_PT-97]
+	_ = x[T_UNIT_PX-98]
+	_ = x[T_UNIT_Q-99]
+	_ = x[T_UNIT_REM-100]
+	_ = x[T_UNIT_HZ-101]
+	_ = x[T_UNIT_KHZ-102]
+	_ = x[T_UNIT_DPI-103]
+	_ = x[T_UNIT_DPCM-104]
+	_ = x[T_UNIT_DPPX-105]
+	_ = x[T_UNIT_VH-106]
+	_ = x[T_UNIT_VW-107]
+	_ = x[T_UNIT_VMIN-108]
+	_ = x[T_UNIT_VMAX-109]
+	_ = x[T_UNIT_DEG-110]
+	_ = x[T_UNIT_GRAD-111]
+	_ = x[T_UNIT_RAD-112]
+	_ = x[T_UNIT_TURN-113]
+	_ = x[T_PROPERTY_NAME_TOKEN-114]
+	_ = x[T_PROPERTY_VALUE-115]
+	_ = x[T_HEX_COLOR-116]
+	_ = x[T_COLON-117]
+	_ = x[T_INTERPOLATION_START-118]
+	_ = x[T_INTERPOLATION_INNER-119]
+	_ = x[T_INTERPOLATION_END-120]
+	_ = x[T_DIV-121]
+	_ = x[T_MUL-122]
+	_ = x[T_MINUS-123]
+	_ = x[T_MOD-124]
+	_ = x[T_ELLIPSIS-125]
+	_ = x[T_ERROR-126]
+}
+
+const _TokenType_name = "T_SPACET_COMMENT_LINET_COMMENT_BLOCKT_SEMICOLONT_COMMAT_IDENTT_URLT_MEDIAT_TRUET_FALSET_NULLT_MS_PARAM_NAMET_FUNCTION_NAMET_ID_SELECTORT_CLASS_SELECTORT_TYPE_SELECTORT_UNIVERSAL_SELECTORT_PARENT_SELECTORT_PSEUDO_SELECTORT_PLACEHOLDER_SELECTORT_INTERPOLATION_SELECTORT_LITERAL_CONCATT_MS_PROGIDT_AND_SELECTORT_DESCENDANT_SELECTORT_CHILD_SELECTORT_ADJACENT_SELECTORT_UNICODE_RANGET_IFT_ELSET_EACHT_INT_FORT_FROMT_THROUGHT_TOT_WHILET_ORT_ANDT_XORT_NOTT_PLUST_GTT_GET_LTT_LET_EQT_NET_BRACE_STARTT_BRACE_ENDT_LANG_CODET_BRACKET_LEFTT_ATTRIBUTE_NAMET_BRACKET_RIGHTT_EQUALT_TILDE_EQUALT_PIPE_EQUALT_VARIABLET_IMPORTT_IMPORT_ONCET_AT_RULET_MIXINT_INCLUDET_CONTENTT_USINGT_FUNCTIONT_RETURNT_WARNT_EXTENDT_OPTIONALT_DEFAULTT_GLOBALT_USET_FORWARDT_AST_WITHT_SHOWT_HIDET_CHARSETT_QQ_STRINGT_Q_STRINGT_UNQUOTE_STRINGT_PAREN_STARTT_PAREN_ENDT_CONSTANTT_INTEGERT_FLOATT_UNIT_PERCENTT_UNIT_SECONDT_UNIT_MILLISECONDT_UNIT_CHT_UNIT_CMT_UNIT_EMT_UNIT_EXT_UNIT_INT_UNIT_MMT_UNIT_PCT_UNIT_PTT_UNIT_PXT_UNIT_QT_UNIT_REMT_UNIT_HZT_UNIT_KHZT_UNIT_DPIT_UNIT_DPCMT_UNIT_DPPXT_UNIT_VHT_UNIT_VWT_UNIT_VMINT_UNIT_VMAXT_UNIT_DEGT_UNIT_GRADT_UNIT_RADT_UNIT_TURNT_PROPERTY_NAME_TOKENT_PROPERTY_VALUET_HEX_COLORT_COLONT_INTERPOLATION_STARTT_INTERPOLATION_INNERT_INTERPOLATION_ENDT_DIVT_MULT_MINUST_MODT_ELLIPSIST_ERROR"
 
-var _TokenType_index = [...]uint16{0, 7, 21, 36, 47, 54, 61, 66, 73, 79, 86, 92, 107, 122, 135, 151, 166, 186, 203, 220, 244, 260, 271, 285, 306, 322, 341, 356, 360, 366, 370, 375, 380, 386, 390, 403, 414, 425, 439, 455, 470, 477, 490, 502, 512, 520, 529, 538, 549, 559, 575, 588, 599, 609, 618, 625, 639, 652, 670, 679, 688, 697, 706, 715, 724, 733, 742, 751, 761, 770, 780, 790, 801, 812, 821, 830, 841, 852, 862, 873, 883, 894, 915, 931, 942, 949, 970, 991, 1010, 1015, 1020, 1027}
+var _TokenType_index = [...]uint16{0, 7, 21, 36, 47, 54, 61, 66, 73, 79, 86, 92, 107, 122, 135, 151, 166, 186, 203, 220, 242, 266, 282, 293, 307, 328, 344, 363, 378, 382, 388, 394, 398, 403, 409, 418, 422, 429, 433, 438, 443, 448, 454, 458, 462, 466, 470, 474, 478, 491, 502, 513, 527, 543, 558, 565, 578, 590, 600, 608, 621, 630, 637, 646, 655, 662, 672, 680, 686, 694, 704, 713, 721, 726, 735, 739, 745, 751, 757, 766, 777, 787, 803, 816, 827, 837, 846, 853, 867, 880, 898, 907, 916, 925, 934, 943, 952, 961, 970, 979, 987, 997, 1006, 1016, 1026, 1037, 1048, 1057, 1066, 1077, 1088, 1098, 1109, 1119, 1130, 1151, 1167, 1178, 1185, 1206, 1227, 1246, 1251, 1256, 1263, 1268, 1278, 1285}
 
 func (i TokenType) String() string {
-	if i < 0 || i+1 >= TokenType(len(_TokenType_index)) {
-		return fmt.Sprintf("TokenType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_TokenType_index)-1 {
+		return "TokenType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _TokenType_name[_TokenType_index[i]:_TokenType_index[i+1]]
+	return _TokenType_name[_TokenType_index[idx]:_TokenType_index[idx+1]]
 }
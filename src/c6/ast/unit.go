@@ -1,8 +1,8 @@
 package ast
 
 import "fmt"
-
-// import "strings"
+import "math"
+import "strings"
 
 //go:generate stringer -type=UnitType token.go unit.go
 type UnitType int
@@ -26,6 +26,7 @@ const (
 	UNIT_PC
 	UNIT_PT
 	UNIT_PX
+	UNIT_Q
 
 	// Viewport-percentage lengths
 	UNIT_VH
@@ -96,15 +97,18 @@ func (unit UnitType) UnitString() string {
 		return "dppx"
 	case UNIT_DPCM:
 		return "dpcm"
+	case UNIT_Q:
+		return "Q"
+	case UNIT_HZ:
+		return "Hz"
+	case UNIT_KHZ:
+		return "kHz"
 	case UNIT_NONE:
 		return ""
 	default:
-		panic(fmt.Errorf("Unsupported unit type: %s", unit))
-		/*
-			// For undefined unit, convert the unit name dynamically
-			var str = unit.String()
-			return strings.ToLower(str[len("UNIT_"):])
-		*/
+		// For undefined unit, convert the unit name dynamically
+		var str = unit.String()
+		return strings.ToLower(strings.TrimPrefix(str, "UNIT_"))
 	}
 }
 
@@ -136,7 +140,98 @@ func ConvertTokenTypeToUnitType(tokenType TokenType) UnitType {
 		return UNIT_DPPX
 	case T_UNIT_DPCM:
 		return UNIT_DPCM
+	case T_UNIT_EX:
+		return UNIT_EX
+	case T_UNIT_CH:
+		return UNIT_CH
+	case T_UNIT_IN:
+		return UNIT_IN
+	case T_UNIT_PC:
+		return UNIT_PC
+	case T_UNIT_Q:
+		return UNIT_Q
+	case T_UNIT_VH:
+		return UNIT_VH
+	case T_UNIT_VW:
+		return UNIT_VW
+	case T_UNIT_VMIN:
+		return UNIT_VMIN
+	case T_UNIT_VMAX:
+		return UNIT_VMAX
+	case T_UNIT_GRAD:
+		return UNIT_GRAD
+	case T_UNIT_RAD:
+		return UNIT_RAD
+	case T_UNIT_TURN:
+		return UNIT_TURN
+	case T_UNIT_HZ:
+		return UNIT_HZ
+	case T_UNIT_KHZ:
+		return UNIT_KHZ
 	default:
 		panic(fmt.Errorf("Unknown Token Type for converting unit type. Got '%s'", tokenType))
 	}
 }
+
+type unitDimension int
+
+const (
+	lengthDimension unitDimension = iota
+	angleDimension
+	timeDimension
+	frequencyDimension
+	resolutionDimension
+)
+
+type unitConversion struct {
+	Dimension unitDimension
+	Factor    float64
+}
+
+/*
+The units of the same dimension can be converted to each other, the factor is
+the size of the unit in the canonical unit of its dimension: px, deg, s, Hz
+and dppx. The relative units like em and % are only compatible with
+themselves.
+*/
+var unitConversions = map[UnitType]unitConversion{
+	UNIT_PX: {lengthDimension, 1},
+	UNIT_IN: {lengthDimension, 96},
+	UNIT_CM: {lengthDimension, 96 / 2.54},
+	UNIT_MM: {lengthDimension, 96 / 25.4},
+	UNIT_Q:  {lengthDimension, 96 / 101.6},
+	UNIT_PT: {lengthDimension, 96.0 / 72},
+	UNIT_PC: {lengthDimension, 16},
+
+	UNIT_DEG:  {angleDimension, 1},
+	UNIT_GRAD: {angleDimension, 360.0 / 400},
+	UNIT_RAD:  {angleDimension, 180 / math.Pi},
+	UNIT_TURN: {angleDimension, 360},
+
+	UNIT_SECOND:      {timeDimension, 1},
+	UNIT_MILLISECOND: {timeDimension, 0.001},
+
+	UNIT_HZ:  {frequencyDimension, 1},
+	UNIT_KHZ: {frequencyDimension, 1000},
+
+	UNIT_DPPX: {resolutionDimension, 1},
+	UNIT_DPI:  {resolutionDimension, 1.0 / 96},
+	UNIT_DPCM: {resolutionDimension, 2.54 / 96},
+}
+
+/*
+ConversionFactor returns the factor to convert the value in the unit `from`
+to the unit `to`, e.g. 96 from in to px. It returns false if the units are
+incompatible.
+*/
+func ConversionFactor(from UnitType, to UnitType) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	a, aok := unitConversions[from]
+	b, bok := unitConversions[to]
+	if !aok || !bok || a.Dimension != b.Dimension {
+		return 0, false
+	}
+	return a.Factor / b.Factor, true
+}
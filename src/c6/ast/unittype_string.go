@@ -1,16 +1,51 @@
-// generated by stringer -type=UnitType token.go unit.go; DO NOT EDIT
+// Code generated by "stringer -type=UnitType token.go unit.go"; DO NOT EDIT.
 
 package ast
 
-import "fmt"
+import "strconv"
 
-const _UnitType_name = "UNIT_NONEUNIT_EMUNIT_EXUNIT_CHUNIT_REMUNIT_CMUNIT_INUNIT_MMUNIT_PCUNIT_PTUNIT_PXUNIT_VHUNIT_VWUNIT_VMINUNIT_VMAXUNIT_DEGUNIT_GRADUNIT_RADUNIT_TURNUNIT_PERCENTUNIT_SECONDUNIT_MILLISECONDUNIT_DPIUNIT_DPPXUNIT_DPCMUNIT_HZUNIT_KHZ"
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[UNIT_NONE-0]
+	_ = x[UNIT_EM-1]
+	_ = x[UNIT_EX-2]
+	_ = x[UNIT_CH-3]
+	_ = x[UNIT_REM-4]
+	_ = x[UNIT_CM-5]
+	_ = x[UNIT_IN-6]
+	_ = x[UNIT_MM-7]
+	_ = x[UNIT_PC-8]
+	_ = x[UNIT_PT-9]
+	_ = x[UNIT_PX-10]
+	_ = x[UNIT_Q-11]
+	_ = x[UNIT_VH-12]
+	_ = x[UNIT_VW-13]
+	_ = x[UNIT_VMIN-14]
+	_ = x[UNIT_VMAX-15]
+	_ = x[UNIT_DEG-16]
+	_ = x[UNIT_GRAD-17]
+	_ = x[UNIT_RAD-18]
+	_ = x[UNIT_TURN-19]
+	_ = x[UNIT_PERCENT-20]
+	_ = x[UNIT_SECOND-21]
+	_ = x[UNIT_MILLISECOND-22]
+	_ = x[UNIT_DPI-23]
+	_ = x[UNIT_DPPX-24]
+	_ = x[UNIT_DPCM-25]
+	_ = x[UNIT_HZ-26]
+	_ = x[UNIT_KHZ-27]
+}
+
+const _UnitType_name = "UNIT_NONEUNIT_EMUNIT_EXUNIT_CHUNIT_REMUNIT_CMUNIT_INUNIT_MMUNIT_PCUNIT_PTUNIT_PXUNIT_QUNIT_VHUNIT_VWUNIT_VMINUNIT_VMAXUNIT_DEGUNIT_GRADUNIT_RADUNIT_TURNUNIT_PERCENTUNIT_SECONDUNIT_MILLISECONDUNIT_DPIUNIT_DPPXUNIT_DPCMUNIT_HZUNIT_KHZ"
 
-var _UnitType_index = [...]uint8{0, 9, 16, 23, 30, 38, 45, 52, 59, 66, 73, 80, 87, 94, 103, 112, 120, 129, 137, 146, 158, 169, 185, 193, 202, 211, 218, 226}
+var _UnitType_index = [...]uint8{0, 9, 16, 23, 30, 38, 45, 52, 59, 66, 73, 80, 86, 93, 100, 109, 118, 126, 135, 143, 152, 164, 175, 191, 199, 208, 217, 224, 232}
 
 func (i UnitType) String() string {
-	if i < 0 || i+1 >= UnitType(len(_UnitType_index)) {
-		return fmt.Sprintf("UnitType(%d)", i)
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_UnitType_index)-1 {
+		return "UnitType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _UnitType_name[_UnitType_index[i]:_UnitType_index[i+1]]
+	return _UnitType_name[_UnitType_index[idx]:_UnitType_index[idx+1]]
 }
@@ -0,0 +1,44 @@
+package ast
+
+import "strings"
+
+/*
+UseStatement presents the `@use` rule that loads the members of a module:
+
+	@use "src/corners";
+	@use "src/corners" as c;
+	@use "library" with ($black: #222, $border-radius: 0.1rem);
+
+The namespace is empty when it's derived from the url, and "*" when the
+members are accessed without the namespace.
+*/
+type UseStatement struct {
+	Span
+
+	Url       string
+	Namespace string
+	// The `$name: value` arguments of `with (...)`
+	Configuration []*KeywordArgument
+	Token         *Token
+}
+
+func NewUseStatement(url string, token *Token) *UseStatement {
+	return &UseStatement{Url: url, Token: token}
+}
+
+func (self UseStatement) CanBeStatement() {}
+
+func (self UseStatement) String() string {
+	var out = "@use \"" + self.Url + "\""
+	if self.Namespace != "" {
+		out += " as " + self.Namespace
+	}
+	if len(self.Configuration) > 0 {
+		var args []string
+		for _, arg := range self.Configuration {
+			args = append(args, arg.String())
+		}
+		out += " with (" + strings.Join(args, ", ") + ")"
+	}
+	return out + ";"
+}
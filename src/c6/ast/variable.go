@@ -1,6 +1,8 @@
 package ast
 
 type Variable struct {
+	Span
+
 	Name      string
 	Value     Expression
 	ScopeRule *RuleSet
@@ -22,5 +24,5 @@ func (self Variable) String() string {
 }
 
 func NewVariable(token *Token) *Variable {
-	return &Variable{token.Str, nil, nil, token}
+	return &Variable{Span{TokenRange(token)}, token.Str, nil, nil, token}
 }
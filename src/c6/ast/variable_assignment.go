@@ -4,8 +4,15 @@ package ast
 Variable can be used in block as statement and declaration block
 */
 type VariableAssignment struct {
+	Span
+
 	Variable   *Variable
 	Expression Expression
+
+	// `!default` assigns the variable only when it's undefined or null
+	Default bool
+	// `!global` assigns the variable in the global scope
+	Global bool
 }
 
 /*
@@ -14,10 +21,17 @@ Property is one of the declaration
 func (self VariableAssignment) CanBeDeclaration() {}
 func (self VariableAssignment) CanBeStatement()   {}
 
-func (self VariableAssignment) String() string {
-	return self.Variable.String() + " = " + self.Expression.String()
+func (self VariableAssignment) String() (out string) {
+	out = self.Variable.String() + " = " + self.Expression.String()
+	if self.Default {
+		out += " !default"
+	}
+	if self.Global {
+		out += " !global"
+	}
+	return out
 }
 
 func NewVariableAssignment(variable *Variable, expr Expression) *VariableAssignment {
-	return &VariableAssignment{variable, expr}
+	return &VariableAssignment{Span{NodeRange(variable).Join(NodeRange(expr))}, variable, expr, false, false}
 }
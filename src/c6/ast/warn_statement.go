@@ -0,0 +1,23 @@
+package ast
+
+/*
+WarnStatement presents the `@warn` directive, the message is reported as the
+warning of the compilation.
+*/
+type WarnStatement struct {
+	Span
+
+	Value Expression
+	Token *Token
+}
+
+func NewWarnStatement(value Expression, token *Token) *WarnStatement {
+	return &WarnStatement{Span{TokenRange(token)}, value, token}
+}
+
+func (self WarnStatement) CanBeStatement()   {}
+func (self WarnStatement) CanBeDeclaration() {}
+
+func (self WarnStatement) String() string {
+	return "@warn " + self.Value.String()
+}
@@ -0,0 +1,25 @@
+package ast
+
+/*
+WhileStatement runs the block while the condition is true:
+
+	@while $i > 0 { }
+*/
+type WhileStatement struct {
+	Span
+
+	Condition Expression
+	Block     *DeclarationBlock
+	Token     *Token
+}
+
+func NewWhileStatement(condition Expression, token *Token) *WhileStatement {
+	return &WhileStatement{Span{TokenRange(token)}, condition, nil, token}
+}
+
+func (self WhileStatement) CanBeStatement()   {}
+func (self WhileStatement) CanBeDeclaration() {}
+
+func (self WhileStatement) String() string {
+	return "@while " + self.Condition.String()
+}
@@ -1,6 +1,31 @@
 package c6
 
+import "sync"
+import "c6/ast"
+
 /**
-We will cache the compiled ast.Block in the map,
+We will cache the compiled ast.Block in the map, the cached block is reused
+until the contents of the file are modified.
 */
-var fileAstMap map[string]interface{} = map[string]interface{}{}
+type fileAst struct {
+	Contents string
+	Block    *ast.Block
+}
+
+var fileAstMap map[string]*fileAst = map[string]*fileAst{}
+var fileAstMapLock sync.Mutex
+
+func getCachedFileAst(path string, contents string) *ast.Block {
+	fileAstMapLock.Lock()
+	defer fileAstMapLock.Unlock()
+	if cached, ok := fileAstMap[path]; ok && cached.Contents == contents {
+		return cached.Block
+	}
+	return nil
+}
+
+func cacheFileAst(path string, contents string, block *ast.Block) {
+	fileAstMapLock.Lock()
+	defer fileAstMapLock.Unlock()
+	fileAstMap[path] = &fileAst{contents, block}
+}
@@ -0,0 +1,109 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "sync"
+import "c6/ast"
+
+type builtinFunction struct {
+	Signature string
+	Func      CustomFunction
+}
+
+/*
+builtinModuleFunctions are the functions of the built-in modules loaded by
+`@use "sass:math"`, they're implemented in Go with the Sass signatures like
+the registered functions.
+*/
+var builtinModuleFunctions = map[string][]builtinFunction{
+	"sass:math": {
+		{"div($number1, $number2)", mathDiv},
+	},
+	"sass:list": {
+		{"slash($elements...)", listSlash},
+		{"separator($list)", listSeparator},
+	},
+}
+
+var builtinModules map[string]map[string]*customFunction
+var builtinModulesOnce sync.Once
+
+/*
+lookupBuiltinModule returns the functions of the built-in module by the url,
+the signatures are parsed on the first lookup.
+*/
+func lookupBuiltinModule(url string) (map[string]*customFunction, bool) {
+	builtinModulesOnce.Do(func() {
+		builtinModules = map[string]map[string]*customFunction{}
+		for moduleUrl, fns := range builtinModuleFunctions {
+			var functions = map[string]*customFunction{}
+			for _, fn := range fns {
+				custom, err := newCustomFunction(fn.Signature, fn.Func)
+				if err != nil {
+					panic(err)
+				}
+				functions[custom.Name] = custom
+			}
+			builtinModules[moduleUrl] = functions
+		}
+	})
+	functions, ok := builtinModules[url]
+	return functions, ok
+}
+
+/*
+lookupBuiltinFunction finds the function of the built-in module used by the
+namespace like `math.div`, or used without the namespace by `as *`.
+*/
+func (context *Context) lookupBuiltinFunction(name string) *customFunction {
+	if namespace, member := splitNamespace(name); namespace != "" {
+		return context.LookupModule(namespace).Builtins[member]
+	}
+	for _, module := range context.GlobalModules {
+		if fn, ok := module.Builtins[name]; ok {
+			return fn
+		}
+	}
+	return nil
+}
+
+/*
+math.div($number1, $number2) always divides, unlike the slash that separates
+the literal numbers: `math.div(12px, 2)` is 6px.
+*/
+func mathDiv(args []ast.Value) (ast.Value, error) {
+	return ast.Compute(ast.OpDiv, args[0], args[1])
+}
+
+/*
+list.slash($elements...) creates the slash-separated list, it's the
+unambiguous form of `12px/1.5`.
+*/
+func listSlash(args []ast.Value) (ast.Value, error) {
+	var elements = args[0].(*ast.List).Expressions
+	if len(elements) < 2 {
+		return nil, fmt.Errorf("At least two elements are required.")
+	}
+	return ast.NewSlashList(elements...), nil
+}
+
+/*
+list.separator($list) returns the separator of the list, `space`, `comma` or
+`slash`. The value that is not a list is a list of one element separated by
+space.
+*/
+func listSeparator(args []ast.Value) (ast.Value, error) {
+	var separator = "space"
+	if list, ok := args[0].(*ast.List); ok {
+		switch {
+		case list.IsSlashSeparated():
+			separator = "slash"
+		case list.Separator == ", " || list.Separator == ",":
+			separator = "comma"
+		}
+	}
+	return &ast.String{Value: separator}, nil
+}
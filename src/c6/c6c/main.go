@@ -1,5 +1,231 @@
 package main
 
-func main() {
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "flag"
+import "fmt"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "strings"
+import "c6"
+import "c6/compiler"
+
+const usage = `Usage: c6c [options] [input.scss] [output.css]
+       c6c [options] --watch src:dist
+
+Compiles the SCSS input file into CSS. The input is read from stdin when the
+input file is omitted or "-", and the output is written to stdout when the
+output file is omitted or "-".
+
+In watch mode, the stylesheets in the src directory are compiled into the dist
+directory, and recompiled when they or their imported files are modified.
+
+Options:
+`
+
+// loadPaths implements flag.Value, so --load-path can be repeated.
+type loadPaths []string
+
+func (self *loadPaths) String() string {
+	return strings.Join(*self, string(os.PathListSeparator))
+}
+
+func (self *loadPaths) Set(path string) error {
+	*self = append(*self, path)
+	return nil
+}
+
+type options struct {
+	Style      string
+	LoadPaths  loadPaths
+	ImportOnce bool
+	Precision  int
+	SourceMap  sourceMapOptions
+	Watch      string
+}
+
+type sourceMapOptions struct {
+	// Write the source map to the `.map` file next to the output file
+	External bool
+	// Embed the source map in the output as the data URI
+	Inline bool
+	// Embed the source code in the source map
+	Contents bool
+}
+
+func (self sourceMapOptions) Enabled() bool {
+	return self.External || self.Inline
+}
+
+func newFlagSet(opts *options) *flag.FlagSet {
+	var flags = flag.NewFlagSet("c6c", flag.ContinueOnError)
+	flags.StringVar(&opts.Style, "style", compiler.NestedStyle, "output style: nested, expanded, compact or compressed")
+	flags.Var(&opts.LoadPaths, "load-path", "add a directory to look up the imported files, can be repeated")
+	flags.BoolVar(&opts.ImportOnce, "import-once", false, "import each file only once, like @import-once")
+	flags.IntVar(&opts.Precision, "precision", compiler.DefaultPrecision, "the number of digits after the decimal point")
+	flags.BoolVar(&opts.SourceMap.External, "sourcemap", false, "generate the source map, it's written to the output file with the .map extension")
+	flags.BoolVar(&opts.SourceMap.Inline, "sourcemap-inline", false, "embed the source map in the output as a data URI")
+	flags.BoolVar(&opts.SourceMap.Contents, "sourcemap-contents", false, "embed the source code in the source map")
+	flags.StringVar(&opts.Watch, "watch", "", "watch the src directory and compile into the dist directory, in `src:dist` form")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
+/*
+parseArgs parses the options and the positional arguments, the options are
+allowed to be placed after the file names.
+*/
+func parseArgs(args []string) (*options, []string, error) {
+	var opts = options{}
+	var flags = newFlagSet(&opts)
+	var files []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		files = append(files, args[0])
+		args = args[1:]
+	}
+	if len(files) > 2 {
+		flags.Usage()
+		return nil, nil, fmt.Errorf("Too many arguments.")
+	}
+	return &opts, files, nil
+}
+
+/*
+compile compiles the input file, or stdin if the input is empty or "-". The
+output is the path of the CSS file for the source map, it's empty for stdout,
+where only the inline source map is available.
+*/
+func compile(input string, output string, opts c6.Options, sourceMap sourceMapOptions) (c6.Result, error) {
+	if sourceMap.Enabled() {
+		if output == "" && !sourceMap.Inline {
+			return c6.Result{}, fmt.Errorf("The source map file needs the output file, use --sourcemap-inline for stdout.")
+		}
+		opts.SourceMap = c6.SourceMapOptions{
+			Enabled:  true,
+			File:     output,
+			Inline:   sourceMap.Inline,
+			Contents: sourceMap.Contents,
+		}
+		if !sourceMap.Inline {
+			opts.SourceMap.URL = filepath.Base(output) + ".map"
+		}
+	}
+
+	if input != "" && input != "-" {
+		return c6.CompileFile(input, opts)
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return c6.Result{}, err
+	}
+	return c6.Compile(data, opts)
+}
+
+/*
+writeOutput writes the CSS to the output file, and the external source map
+to the output file with the `.map` extension.
+*/
+func writeOutput(result c6.Result, output string, sourceMap sourceMapOptions) error {
+	if sourceMap.External && !sourceMap.Inline {
+		data, err := result.SourceMap.JSON()
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(output+".map", data, 0644); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(output, []byte(result.CSS), 0644)
+}
+
+func printWarnings(result c6.Result) {
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+}
+
+func run(args []string) int {
+	opts, files, err := parseArgs(args)
+	if err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if opts.Precision < 0 {
+		fmt.Fprintln(os.Stderr, "The precision must not be negative.")
+		return 2
+	}
+
+	var input, output string
+	if len(files) > 0 {
+		input = files[0]
+	}
+	if len(files) > 1 {
+		output = files[1]
+	}
+
+	if _, err := compiler.NewStyleCompiler(opts.Style, opts.Precision); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var compileOptions = c6.Options{
+		Style:      opts.Style,
+		Precision:  &opts.Precision,
+		LoadPaths:  opts.LoadPaths,
+		ImportOnce: opts.ImportOnce,
+	}
+
+	if opts.Watch != "" {
+		if len(files) > 0 {
+			fmt.Fprintln(os.Stderr, "The input and output files can't be used with --watch.")
+			return 2
+		}
+		w, err := newWatcher(opts.Watch, compileOptions)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		w.SourceMap = opts.SourceMap
+		w.Run()
+		return 0
+	}
+
+	if output == "-" {
+		output = ""
+	}
+	result, err := compile(input, output, compileOptions, opts.SourceMap)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	printWarnings(result)
+
+	if output == "" {
+		fmt.Print(result.CSS)
+		return 0
+	}
+	if err := writeOutput(result, output, opts.SourceMap); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
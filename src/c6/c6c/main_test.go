@@ -0,0 +1,43 @@
+package main
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "testing"
+import "time"
+import "c6"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestCompileSourceMapFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var input = filepath.Join(dir, "src", "app.scss")
+	var output = filepath.Join(dir, "dist", "app.css")
+	writeTestFile(t, input, ".app { color: red; }", time.Now())
+	assert.Nil(t, os.MkdirAll(filepath.Dir(output), 0755))
+
+	var sourceMap = sourceMapOptions{External: true, Contents: true}
+	result, err := compile(input, output, c6.Options{Style: compiler.CompactStyle}, sourceMap)
+	assert.Nil(t, err)
+	assert.Nil(t, writeOutput(result, output, sourceMap))
+
+	css, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, ".app { color: red; }\n/*# sourceMappingURL=app.css.map */\n", string(css))
+
+	data, err := ioutil.ReadFile(output + ".map")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":3,"file":"app.css","sources":["../src/app.scss"],"sourcesContent":[".app { color: red; }"],"names":[],"mappings":"AAAA,OAAO"}`, string(data))
+}
+
+func TestCompileSourceMapNeedsOutputFile(t *testing.T) {
+	_, err := compile("", "", c6.Options{}, sourceMapOptions{External: true})
+	assert.NotNil(t, err)
+}
+
+func TestRunNegativePrecision(t *testing.T) {
+	assert.Equal(t, 2, run([]string{"--precision", "-1"}))
+}
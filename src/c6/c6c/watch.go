@@ -0,0 +1,196 @@
+package main
+
+import "fmt"
+import "os"
+import "path/filepath"
+import "sort"
+import "strings"
+import "time"
+import "c6"
+
+// The interval of polling the modification time of the watched files
+const watchInterval = 500 * time.Millisecond
+
+/*
+watcher polls the source directory and recompiles the entry points (the
+stylesheets that are not partials) whose dependencies are modified.
+*/
+type watcher struct {
+	Src  string
+	Dist string
+
+	Options   c6.Options
+	SourceMap sourceMapOptions
+
+	// the modification time of the files in the last scan
+	ModTimes map[string]time.Time
+
+	// the files that each entry point depends on, including the entry point
+	Dependencies map[string][]string
+}
+
+/*
+newWatcher creates a watcher from the `src:dist` argument.
+*/
+func newWatcher(spec string, opts c6.Options) (*watcher, error) {
+	var dirs = strings.SplitN(spec, ":", 2)
+	if len(dirs) != 2 || dirs[0] == "" || dirs[1] == "" {
+		return nil, fmt.Errorf("Invalid watch argument '%s', expecting 'src:dist'.", spec)
+	}
+	if info, err := os.Stat(dirs[0]); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("The source directory '%s' is not a directory.", dirs[0])
+	}
+	return &watcher{
+		Src:          filepath.Clean(dirs[0]),
+		Dist:         filepath.Clean(dirs[1]),
+		Options:      opts,
+		ModTimes:     map[string]time.Time{},
+		Dependencies: map[string][]string{},
+	}, nil
+}
+
+func isStylesheet(path string) bool {
+	var ext = filepath.Ext(path)
+	return ext == ".scss" || ext == ".sass"
+}
+
+// partials are prefixed with '_', they are only compiled by being imported.
+func isEntryPoint(path string) bool {
+	return isStylesheet(path) && !strings.HasPrefix(filepath.Base(path), "_")
+}
+
+/*
+scan walks the source directory and returns the files that are created,
+modified or removed since the last scan.
+*/
+func (self *watcher) scan() []string {
+	var changed = []string{}
+	var found = map[string]bool{}
+	filepath.Walk(self.Src, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !isStylesheet(path) {
+			return nil
+		}
+		found[path] = true
+		if modTime, ok := self.ModTimes[path]; !ok || !modTime.Equal(info.ModTime()) {
+			self.ModTimes[path] = info.ModTime()
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	for path := range self.ModTimes {
+		if !found[path] {
+			delete(self.ModTimes, path)
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+/*
+affectedEntries returns the entry points that depend on the changed files.
+*/
+func (self *watcher) affectedEntries(changed []string) []string {
+	var entries = []string{}
+	var affected = map[string]bool{}
+	for _, path := range changed {
+		if _, err := os.Stat(path); err == nil && isEntryPoint(path) && !affected[path] {
+			affected[path] = true
+			entries = append(entries, path)
+		}
+	}
+	for entry, deps := range self.Dependencies {
+		if affected[entry] {
+			continue
+		}
+		if _, err := os.Stat(entry); err != nil {
+			// the entry point is removed
+			delete(self.Dependencies, entry)
+			continue
+		}
+		for _, dep := range deps {
+			if containsPath(changed, dep) {
+				affected[entry] = true
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// mergePaths appends the paths that are not in the list yet
+func mergePaths(paths []string, more []string) []string {
+	for _, path := range more {
+		if !containsPath(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *watcher) outputPath(entry string) string {
+	rel, _ := filepath.Rel(self.Src, entry)
+	return filepath.Join(self.Dist, strings.TrimSuffix(rel, filepath.Ext(rel))+".css")
+}
+
+func (self *watcher) compileEntry(entry string) error {
+	var start = time.Now()
+
+	var output = self.outputPath(entry)
+	result, err := compile(entry, output, self.Options, self.SourceMap)
+	if err != nil {
+		// keep the dependencies of the last successful compilation with the
+		// files loaded before the error, so the fix in the partials triggers
+		// the compilation again.
+		self.Dependencies[entry] = mergePaths(self.Dependencies[entry], append(result.IncludedFiles, entry))
+		return err
+	}
+	self.Dependencies[entry] = result.IncludedFiles
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	if err := writeOutput(result, output, self.SourceMap); err != nil {
+		return err
+	}
+	printWarnings(result)
+	fmt.Printf("Compiled %s => %s in %s\n", entry, output, time.Since(start))
+	return nil
+}
+
+/*
+rebuild compiles the entry points affected by the changed files, and
+returns the number of failed compilations.
+*/
+func (self *watcher) rebuild(changed []string) int {
+	var failures = 0
+	for _, entry := range self.affectedEntries(changed) {
+		if err := self.compileEntry(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %s\n", entry, err)
+			failures++
+		}
+	}
+	return failures
+}
+
+func (self *watcher) Run() {
+	fmt.Printf("Watching %s => %s\n", self.Src, self.Dist)
+	self.rebuild(self.scan())
+	for {
+		time.Sleep(watchInterval)
+		if changed := self.scan(); len(changed) > 0 {
+			self.rebuild(changed)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "testing"
+import "time"
+import "c6"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func writeTestFile(t *testing.T, path string, content string, modTime time.Time) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	assert.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestWatcherRecompilesAffectedEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var src = filepath.Join(dir, "src")
+	var dist = filepath.Join(dir, "dist")
+	var now = time.Now().Add(-time.Hour)
+	writeTestFile(t, filepath.Join(src, "_vars.scss"), `.vars { color: red; }`, now)
+	writeTestFile(t, filepath.Join(src, "_mixins.scss"), `@import "vars";`, now)
+	writeTestFile(t, filepath.Join(src, "app.scss"), `@import "mixins"; .app { color: red; }`, now)
+	writeTestFile(t, filepath.Join(src, "pages", "other.scss"), `.other { color: blue; }`, now)
+
+	w, err := newWatcher(src+":"+dist, c6.Options{Style: compiler.CompressedStyle})
+	assert.Nil(t, err)
+
+	var changed = w.scan()
+	assert.Equal(t, 4, len(changed))
+	assert.Equal(t, []string{filepath.Join(src, "app.scss"), filepath.Join(src, "pages", "other.scss")}, w.affectedEntries(changed))
+	assert.Equal(t, 0, w.rebuild(changed))
+
+	_, err = os.Stat(filepath.Join(dist, "app.css"))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dist, "pages", "other.css"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(w.Dependencies[filepath.Join(src, "app.scss")]))
+
+	// nothing changed
+	assert.Equal(t, 0, len(w.scan()))
+
+	// the partial imported through another partial
+	writeTestFile(t, filepath.Join(src, "_vars.scss"), `.vars { color: blue; }`, now.Add(time.Minute))
+	changed = w.scan()
+	assert.Equal(t, []string{filepath.Join(src, "_vars.scss")}, changed)
+	assert.Equal(t, []string{filepath.Join(src, "app.scss")}, w.affectedEntries(changed))
+}
+
+func TestWatcherTracksBrokenPartial(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6c")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var src = filepath.Join(dir, "src")
+	var dist = filepath.Join(dir, "dist")
+	var now = time.Now().Add(-time.Hour)
+	writeTestFile(t, filepath.Join(src, "_vars.scss"), `.vars { color: }`, now)
+	writeTestFile(t, filepath.Join(src, "app.scss"), `@import "vars"; .app { color: red; }`, now)
+
+	w, err := newWatcher(src+":"+dist, c6.Options{Style: compiler.CompressedStyle})
+	assert.Nil(t, err)
+
+	// the first compilation fails in the partial
+	assert.Equal(t, 1, w.rebuild(w.scan()))
+	assert.Equal(t, []string{filepath.Join(src, "_vars.scss"), filepath.Join(src, "app.scss")}, w.Dependencies[filepath.Join(src, "app.scss")])
+
+	// the fix of the partial compiles the entry again
+	writeTestFile(t, filepath.Join(src, "_vars.scss"), `.vars { color: blue; }`, now.Add(time.Minute))
+	var changed = w.scan()
+	assert.Equal(t, []string{filepath.Join(src, "app.scss")}, w.affectedEntries(changed))
+	assert.Equal(t, 0, w.rebuild(changed))
+	_, err = os.Stat(filepath.Join(dist, "app.css"))
+	assert.Nil(t, err)
+}
+
+func TestWatcherInvalidArgument(t *testing.T) {
+	_, err := newWatcher("src", c6.Options{})
+	assert.NotNil(t, err)
+}
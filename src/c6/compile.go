@@ -0,0 +1,288 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "errors"
+import "path/filepath"
+import "sort"
+import "c6/ast"
+import "c6/compiler"
+
+/*
+Options are the options of Compile and CompileFile, the zero value compiles
+SCSS in the nested style.
+*/
+type Options struct {
+	// The output style: nested (default), expanded, compact or compressed
+	Style string
+
+	// The number of digits after the decimal point, it's
+	// compiler.DefaultPrecision if it's nil, so the zero precision can be
+	// requested.
+	Precision *int
+
+	// The directories to look up the imported files
+	LoadPaths []string
+
+	// The importers to load the imported files, like the FSImporter, they
+	// are tried before the load paths.
+	Importers []Importer
+
+	// Import each file only once like `@import-once`
+	ImportOnce bool
+
+	// The path of the code passed to Compile, it's used in the errors and
+	// the source map, and the relative imports are resolved from its
+	// directory on the file system.
+	Filename string
+
+	// Parse the code passed to Compile as the indented syntax
+	Indented bool
+
+	SourceMap SourceMapOptions
+
+	// The functions implemented in Go by the Sass signature, like
+	// `asset-url($path, $v: null)`, or by the name to take the positional
+	// arguments as they are passed. See RegisterFunction.
+	Functions map[string]CustomFunction
+}
+
+type SourceMapOptions struct {
+	Enabled bool
+
+	// The path of the generated CSS, the sources of the source map are
+	// relative to its directory.
+	File string
+
+	// The URL of the source map, the sourceMappingURL comment is appended to
+	// the CSS if it's set.
+	URL string
+
+	// Embed the source map in the CSS as the data URI
+	Inline bool
+
+	// Embed the source code in the source map
+	Contents bool
+}
+
+/*
+Result is the output of the compilation.
+*/
+type Result struct {
+	CSS string
+
+	// The source map, it's nil unless the source map is enabled
+	SourceMap *compiler.SourceMap
+
+	// The files included in the compilation by @import, @use and @forward,
+	// including the compiled file. It's the files loaded before the error
+	// when the compilation fails.
+	IncludedFiles []string
+
+	// The warnings reported by `@warn`
+	Warnings []Diagnostic
+}
+
+/*
+Compile compiles the SCSS code, or the indented syntax with the Indented
+option, into CSS.
+*/
+func Compile(src []byte, opts Options) (Result, error) {
+	var context = newCompileContext(opts)
+	if opts.Filename != "" {
+		context.canonicalImporters[opts.Filename] = &FilesystemImporter{}
+	}
+	var parser = NewParser(context)
+	parser.File = opts.Filename
+	return compileWith(parser, opts, string(src), func() (*ast.Block, error) {
+		if opts.Indented {
+			return parser.ParseSass(string(src))
+		}
+		return parser.ParseScss(string(src))
+	})
+}
+
+/*
+CompileFile compiles the file loaded by the importers, or from the file
+system, into CSS. The syntax is decided by the file extension.
+*/
+func CompileFile(path string, opts Options) (Result, error) {
+	var parser = NewParser(newCompileContext(opts))
+	return compileWith(parser, opts, "", func() (*ast.Block, error) {
+		return parser.ParseFile(path)
+	})
+}
+
+func newCompileContext(opts Options) *Context {
+	var context = NewContext()
+	context.LoadPaths = append(context.LoadPaths, opts.LoadPaths...)
+	context.ImportOnce = opts.ImportOnce
+	context.Importers = append(context.Importers, opts.Importers...)
+	return context
+}
+
+// addCustomFunctions adds the functions of the options to the context
+func addCustomFunctions(context *Context, functions map[string]CustomFunction) error {
+	for signature, fn := range functions {
+		custom, err := newCustomFunction(signature, fn)
+		if err != nil {
+			return err
+		}
+		context.customFunctions[custom.Name] = custom
+	}
+	return nil
+}
+
+/*
+compileWith parses the code by the parse function, and evaluates and compiles
+it with the context of the parser. The code is the source of the code passed
+to Compile, it's embedded in the source map.
+*/
+func compileWith(parser *Parser, opts Options, code string, parse func() (*ast.Block, error)) (result Result, err error) {
+	var context = parser.Context
+	if err := addCustomFunctions(context, opts.Functions); err != nil {
+		return result, err
+	}
+
+	// the files loaded before the error are included as well
+	defer func() {
+		for file := range context.ImportedFiles {
+			result.IncludedFiles = append(result.IncludedFiles, file)
+		}
+		sort.Strings(result.IncludedFiles)
+	}()
+
+	// the evaluator panics on errors
+	defer func() {
+		if r := recover(); r != nil {
+			if compileErr, ok := r.(*CompileError); ok {
+				err = compileErr
+			} else if sourceErr, ok := r.(*sourceError); ok {
+				err = sourceCompileError(context, sourceErr, parser.File, code)
+			} else {
+				err = errors.New(panicMessage(r))
+			}
+		}
+	}()
+
+	var style = opts.Style
+	if style == "" {
+		style = compiler.NestedStyle
+	}
+	var precision = compiler.DefaultPrecision
+	if opts.Precision != nil {
+		precision = *opts.Precision
+	}
+	styleCompiler, err := compiler.NewStyleCompiler(style, precision)
+	if err != nil {
+		return result, err
+	}
+
+	parsed, err := parse()
+	if err != nil {
+		return result, err
+	}
+	if parser.File != "" {
+		context.pushImport(parser.File, nil)
+	}
+	var block = context.Evaluate(parsed)
+
+	var mappings []compiler.Mapping
+	if opts.SourceMap.Enabled {
+		result.CSS, mappings = styleCompiler.CompileBlockWithMappings(block)
+	} else {
+		result.CSS = styleCompiler.CompileBlock(block)
+	}
+
+	result.Warnings = *context.Warnings
+
+	if opts.SourceMap.Enabled {
+		if err := addSourceMap(context, &result, mappings, opts, code); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+/*
+sourceCompileError converts the error of the evaluator to the CompileError,
+the source of the range is the code passed to Compile, or the file loaded by
+the importer.
+*/
+func sourceCompileError(context *Context, err *sourceError, file string, code string) error {
+	var r = err.Range
+	if r.Start.Line == 0 {
+		// the node without the range, like the node created by the evaluator
+		return errors.New(err.Message)
+	}
+	var source = code
+	if r.File != file || code == "" {
+		source, _, _ = context.LoadImport(r.File)
+	}
+	return NewRangeCompileError(err.Message, r, source, err.Token)
+}
+
+/*
+addSourceMap creates the source map of the result, and appends the
+sourceMappingURL comment of the inline source map or the URL.
+*/
+func addSourceMap(context *Context, result *Result, mappings []compiler.Mapping, opts Options, code string) error {
+	var file = ""
+	if opts.SourceMap.File != "" {
+		file = filepath.Base(opts.SourceMap.File)
+	}
+	var sourceMap = compiler.NewSourceMap(file, mappings)
+
+	if opts.SourceMap.Contents {
+		err := sourceMap.SetSourcesContent(func(source string) (string, error) {
+			if source == compiler.AnonymousSource || (source == opts.Filename && code != "") {
+				return code, nil
+			}
+			contents, _, err := context.LoadImport(source)
+			return contents, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var dir = "."
+	if opts.SourceMap.File != "" {
+		dir = filepath.Dir(opts.SourceMap.File)
+	}
+	// the files are relative to the source map, the urls of the other
+	// importers are kept
+	if absDir, err := filepath.Abs(dir); err == nil {
+		for i, source := range sourceMap.Sources {
+			if _, ok := context.canonicalImporters[source].(*FilesystemImporter); ok {
+				sourceMap.Sources[i] = relativePath(absDir, source)
+			}
+		}
+	}
+	result.SourceMap = sourceMap
+
+	if opts.SourceMap.Inline {
+		uri, err := sourceMap.DataURI()
+		if err != nil {
+			return err
+		}
+		result.CSS += compiler.SourceMappingURLComment(uri)
+	} else if opts.SourceMap.URL != "" {
+		result.CSS += compiler.SourceMappingURLComment(opts.SourceMap.URL)
+	}
+	return nil
+}
+
+// relativePath returns the slash-separated path of the file relative to dir
+func relativePath(dir string, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(dir, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
@@ -0,0 +1,149 @@
+package c6
+
+import "errors"
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "strings"
+import "testing"
+import "c6/ast"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+/*
+compileErrorMessage checks that err is a positioned *CompileError and returns
+its message without the location and the excerpt.
+*/
+func compileErrorMessage(t *testing.T, err error) string {
+	compileErr, ok := err.(*CompileError)
+	if !assert.True(t, ok, "expected a *CompileError, got %v", err) {
+		return ""
+	}
+	assert.True(t, compileErr.Line > 0)
+	return compileErr.Message
+}
+
+func TestCompile(t *testing.T) {
+	result, err := Compile([]byte(`$w: 10px; .a { width: $w * 2; }`), Options{Style: compiler.CompressedStyle})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a{width:20px}", result.CSS)
+	assert.Nil(t, result.SourceMap)
+	assert.Equal(t, 0, len(result.IncludedFiles))
+	assert.Equal(t, 0, len(result.Warnings))
+}
+
+func TestCompileIndented(t *testing.T) {
+	result, err := Compile([]byte(".a\n  color: red\n"), Options{Style: compiler.CompactStyle, Indented: true})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red; }\n", result.CSS)
+}
+
+func TestCompilePrecision(t *testing.T) {
+	var precision = 2
+	result, err := Compile([]byte(`.a { width: (10px / 3); }`), Options{Style: compiler.CompressedStyle, Precision: &precision})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a{width:3.33px}", result.CSS)
+
+	precision = 0
+	result, err = Compile([]byte(`.a { width: (10px / 3); }`), Options{Style: compiler.CompressedStyle, Precision: &precision})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a{width:3px}", result.CSS)
+
+	result, err = Compile([]byte(`.a { width: (1 / 3); }`), Options{Style: compiler.CompressedStyle})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a{width:.33333}", result.CSS)
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile([]byte(`.a { color: red `), Options{Filename: "foo.scss"})
+	compileErr, ok := err.(*CompileError)
+	assert.True(t, ok)
+	assert.Equal(t, "foo.scss", compileErr.File)
+
+	_, err = Compile([]byte(`.a { width: $undefined; }`), Options{})
+	assert.Equal(t, "Undefined variable $undefined", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(".a {\n  width: $x;\n}"), Options{Filename: "foo.scss"})
+	assert.Equal(t, "foo.scss:2:10: Undefined variable $x\n  width: $x;\n         ^", err.Error())
+
+	_, err = Compile([]byte(".a {\n  @include missing;\n}"), Options{Filename: "foo.scss"})
+	compileErr, ok = err.(*CompileError)
+	assert.True(t, ok)
+	assert.Equal(t, "foo.scss", compileErr.File)
+	assert.Equal(t, 2, compileErr.Line)
+
+	_, err = Compile([]byte(`.a { }`), Options{Style: "pretty"})
+	assert.NotNil(t, err)
+}
+
+func TestCompileWarnings(t *testing.T) {
+	result, err := Compile([]byte("@warn \"top\";\n.a { @warn unsupported; color: red; }"), Options{Filename: "foo.scss"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result.Warnings))
+	assert.Equal(t, "foo.scss:1:1: warning: top", result.Warnings[0].String())
+	assert.Equal(t, "foo.scss:2:6: warning: unsupported", result.Warnings[1].String())
+}
+
+func TestCompileCustomFunctions(t *testing.T) {
+	var opts = Options{Style: compiler.CompressedStyle, Functions: map[string]CustomFunction{
+		"brand-color": func(args []ast.Value) (ast.Value, error) {
+			return ast.NewHexColor("#ff0000", nil), nil
+		},
+		"fail": func(args []ast.Value) (ast.Value, error) {
+			return nil, errors.New("no way")
+		},
+	}}
+	result, err := Compile([]byte(`.a { color: brand-color(); width: calc(1px); }`), opts)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a{color:red;width:calc(1px)}", result.CSS)
+
+	_, err = Compile([]byte(`.a { color: fail(); }`), opts)
+	assert.Equal(t, "Function fail failed: no way", compileErrorMessage(t, err))
+}
+
+func TestCompileFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss":       `@use "lib/colors"; @import "base"; .main { color: colors.$primary; }`,
+		"_base.scss":      `.base { margin: 0; }`,
+		"lib/colors.scss": `$primary: #333;`,
+	})
+
+	var main = filepath.Join(dir, "main.scss")
+	result, err := CompileFile(main, Options{Style: compiler.CompactStyle})
+	assert.Nil(t, err)
+	assert.Equal(t, ".base { margin: 0; }\n\n.main { color: #333; }\n", result.CSS)
+	assert.Equal(t, []string{filepath.Join(dir, "_base.scss"), filepath.Join(dir, "lib/colors.scss"), main}, result.IncludedFiles)
+
+	// errors of an imported file are reported at their position in that file
+	writeTestFiles(t, dir, map[string]string{
+		"_base.scss": ".base {\n  margin: $nope;\n}",
+	})
+	_, err = CompileFile(main, Options{})
+	compileErr, ok := err.(*CompileError)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "_base.scss"), compileErr.File)
+	assert.Equal(t, 2, compileErr.Line)
+	assert.Equal(t, 11, compileErr.Column)
+	assert.Equal(t, "Undefined variable $nope", compileErr.Message)
+}
+
+func TestCompileSourceMap(t *testing.T) {
+	var opts = Options{Style: compiler.CompactStyle, Filename: "foo.scss"}
+	opts.SourceMap = SourceMapOptions{Enabled: true, File: "foo.css", URL: "foo.css.map", Contents: true}
+	result, err := Compile([]byte(".a { color: red; }"), opts)
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red; }\n/*# sourceMappingURL=foo.css.map */\n", result.CSS)
+	assert.Equal(t, "foo.css", result.SourceMap.File)
+	assert.Equal(t, []string{"foo.scss"}, result.SourceMap.Sources)
+	assert.Equal(t, []string{".a { color: red; }"}, result.SourceMap.SourcesContent)
+	assert.Equal(t, "AAAA,KAAK", result.SourceMap.Mappings)
+
+	opts.SourceMap = SourceMapOptions{Enabled: true, Inline: true}
+	result, err = Compile([]byte(".a { color: red; }"), opts)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(result.CSS, "/*# sourceMappingURL=data:application/json;charset=utf-8;base64,"))
+}
@@ -0,0 +1,48 @@
+package compiler
+
+import "strings"
+
+/*
+CompactStyleCompiler generates the "compact" output style of SASS, each
+ruleset takes only one line:
+
+	#main { color: #fff; background-color: #000; }
+	#main p { width: 10em; }
+*/
+type CompactStyleCompiler struct {
+	StyleCompiler
+}
+
+func NewCompactStyleCompiler() *CompactStyleCompiler {
+	return &CompactStyleCompiler{StyleCompiler{Formatter: CompactFormatter{}, Precision: DefaultPrecision}}
+}
+
+type CompactFormatter struct{}
+
+func (self CompactFormatter) IsCompressed() bool { return false }
+
+func (self CompactFormatter) SelectorSeparator() string { return ", " }
+
+func (self CompactFormatter) RuleSetSeparator() string { return "\n" }
+
+func (self CompactFormatter) FormatProperty(name string, value string) string {
+	return name + ": " + value + ";"
+}
+
+func (self CompactFormatter) FormatRuleSet(indent int, selectors string, properties []string) string {
+	return selectors + " { " + strings.Join(properties, " ") + " }\n"
+}
+
+func (self CompactFormatter) FormatDirective(indent int, directive string) string {
+	return directive + "\n"
+}
+
+func (self CompactFormatter) FormatMedia(indent int, query string, body string) string {
+	var rulesets []string
+	for _, line := range strings.Split(body, "\n") {
+		if line != "" {
+			rulesets = append(rulesets, line)
+		}
+	}
+	return "@media " + query + " { " + strings.Join(rulesets, " ") + " }\n"
+}
@@ -0,0 +1,232 @@
+package compiler
+
+import "fmt"
+import "strings"
+import "c6/ast"
+
+type Compiler interface {
+	CompileBlock(block *ast.Block) string
+	CompileBlockWithMappings(block *ast.Block) (string, []Mapping)
+}
+
+// The output style names
+const (
+	NestedStyle     = "nested"
+	ExpandedStyle   = "expanded"
+	CompactStyle    = "compact"
+	CompressedStyle = "compressed"
+)
+
+/*
+NewStyleCompiler creates the compiler of the output style by the style name.
+*/
+func NewStyleCompiler(style string, precision int) (Compiler, error) {
+	switch style {
+	case NestedStyle:
+		var c = NewNestedStyleCompiler()
+		c.Precision = precision
+		return c, nil
+	case ExpandedStyle:
+		var c = NewExpandedStyleCompiler()
+		c.Precision = precision
+		return c, nil
+	case CompactStyle:
+		var c = NewCompactStyleCompiler()
+		c.Precision = precision
+		return c, nil
+	case CompressedStyle:
+		var c = NewCompressedStyleCompiler()
+		c.Precision = precision
+		return c, nil
+	}
+	return nil, fmt.Errorf("Unknown output style: %s", style)
+}
+
+// The default number of digits after the decimal point.
+const DefaultPrecision = 5
+
+/*
+Formatter renders the compiled nodes in a specific output style.
+*/
+type Formatter interface {
+	// Compressed formatter renders values in the shortest form.
+	IsCompressed() bool
+
+	// The separator between the selectors of a selector list.
+	SelectorSeparator() string
+
+	FormatProperty(name string, value string) string
+	FormatRuleSet(indent int, selectors string, properties []string) string
+	FormatDirective(indent int, directive string) string
+	// The body is the compiled statements of the @media block.
+	FormatMedia(indent int, query string, body string) string
+
+	// The separator between the top-level rulesets.
+	RuleSetSeparator() string
+}
+
+/*
+StyleCompiler walks the AST and renders the nodes through the Formatter, all
+the output styles share this tree walk.
+*/
+type StyleCompiler struct {
+	Formatter Formatter
+
+	// The number of digits after the decimal point of numbers.
+	Precision int
+
+	Indent int
+	Output string
+
+	// The source ranges of the markers, it's nil unless the mappings are
+	// recorded.
+	origins []ast.Range
+}
+
+/*
+isNullProperty reports whether the value of the property is `null`, the
+property is omitted like `$opt: null; a { b: $opt }`.
+*/
+func isNullProperty(property *ast.Property) bool {
+	for _, val := range property.Values {
+		if !isNullValue(val) {
+			return false
+		}
+	}
+	return len(property.Values) > 0
+}
+
+/*
+isNullValue reports whether the value is `null` or the list of the `null`
+items, it's left out of the output.
+*/
+func isNullValue(expr ast.Expression) bool {
+	switch t := expr.(type) {
+	case *ast.Null:
+		return true
+	case *ast.List:
+		for _, item := range t.Expressions {
+			if !isNullValue(item) {
+				return false
+			}
+		}
+		return len(t.Expressions) > 0
+	}
+	return false
+}
+
+func (self *StyleCompiler) CompileProperty(property *ast.Property) string {
+	var values []string
+	for _, val := range property.Values {
+		values = append(values, self.CompileValue(val))
+	}
+	return self.mark(property.GetRange(), self.Formatter.FormatProperty(property.Name.String, strings.Join(values, " ")))
+}
+
+func (self *StyleCompiler) CompileSelectors(selectors []ast.Selector) string {
+	var out []string
+	for _, sel := range selectors {
+		out = append(out, self.CompileSelector(sel))
+	}
+	return strings.Join(out, self.Formatter.SelectorSeparator())
+}
+
+func (self *StyleCompiler) CompileSelector(sel ast.Selector) string {
+	switch t := sel.(type) {
+	case *ast.ComplexSelector:
+		var out = ""
+		for _, sub := range t.Selectors {
+			out += self.CompileSelector(sub)
+		}
+		return out
+	case ast.ChildSelector:
+		if self.Formatter.IsCompressed() {
+			return ">"
+		}
+	case ast.AdjacentSelector:
+		if self.Formatter.IsCompressed() {
+			return "+"
+		}
+	}
+	return sel.String()
+}
+
+func (self *StyleCompiler) CompileRuleSet(ruleset *ast.RuleSet) {
+	var block = ruleset.DeclarationBlock
+	if block == nil {
+		return
+	}
+
+	var properties []string
+	for _, decl := range block.Declarations {
+		if property, ok := decl.(*ast.Property); ok && !isNullProperty(property) {
+			properties = append(properties, self.CompileProperty(property))
+		}
+	}
+
+	// empty rulesets are not rendered, and the sub-rulesets are not indented
+	// by the parent.
+	if len(properties) > 0 {
+		self.Output += self.Formatter.FormatRuleSet(self.Indent, self.mark(ruleset.GetRange(), self.CompileSelectors(ruleset.Selectors)), properties)
+		self.Indent++
+	}
+	for _, subruleset := range block.SubRuleSets {
+		self.CompileRuleSet(subruleset)
+	}
+	if len(properties) > 0 {
+		self.Indent--
+	}
+}
+
+func (self *StyleCompiler) CompileStatement(stm ast.Statement) {
+	switch t := stm.(type) {
+	case *ast.RuleSet:
+		self.CompileRuleSet(t)
+	case *ast.ImportStatement:
+		self.Output += self.Formatter.FormatDirective(self.Indent, t.String())
+	case *ast.CharsetStatement:
+		self.Output += self.Formatter.FormatDirective(self.Indent, t.String())
+	case *ast.MediaStatement:
+		self.CompileMedia(t)
+	}
+}
+
+/*
+CompileMedia compiles the statements of the @media block with one more
+indentation level, the empty @media block is not rendered.
+*/
+func (self *StyleCompiler) CompileMedia(media *ast.MediaStatement) {
+	var output = self.Output
+	self.Indent++
+	var body = self.CompileBlock(media.Block)
+	self.Indent--
+	self.Output = output
+	if body != "" {
+		self.Output += self.Formatter.FormatMedia(self.Indent, media.Query, body)
+	}
+}
+
+func (self *StyleCompiler) CompileBlock(block *ast.Block) string {
+	var output = ""
+	var lastIsRuleSet = false
+	for _, stm := range block.Statements {
+		self.Output = ""
+		self.CompileStatement(stm)
+		if self.Output == "" {
+			continue
+		}
+
+		var isRuleSet = false
+		switch stm.(type) {
+		case *ast.RuleSet, *ast.MediaStatement:
+			isRuleSet = true
+		}
+		if isRuleSet && lastIsRuleSet {
+			output += self.Formatter.RuleSetSeparator()
+		}
+		output += self.Output
+		lastIsRuleSet = isRuleSet
+	}
+	self.Output = output
+	return self.Output
+}
@@ -0,0 +1,44 @@
+package compiler
+
+import "strings"
+
+/*
+CompressedStyleCompiler generates the "compressed" output style of SASS, all
+the unnecessary whitespaces are stripped and the values are rendered in the
+shortest form:
+
+	#main{color:#fff;background-color:#000}#main p{width:10em}
+*/
+type CompressedStyleCompiler struct {
+	StyleCompiler
+}
+
+func NewCompressedStyleCompiler() *CompressedStyleCompiler {
+	return &CompressedStyleCompiler{StyleCompiler{Formatter: CompressedFormatter{}, Precision: DefaultPrecision}}
+}
+
+type CompressedFormatter struct{}
+
+func (self CompressedFormatter) IsCompressed() bool { return true }
+
+func (self CompressedFormatter) SelectorSeparator() string { return "," }
+
+func (self CompressedFormatter) RuleSetSeparator() string { return "" }
+
+func (self CompressedFormatter) FormatProperty(name string, value string) string {
+	return name + ":" + value
+}
+
+func (self CompressedFormatter) FormatRuleSet(indent int, selectors string, properties []string) string {
+	return selectors + "{" + strings.Join(properties, ";") + "}"
+}
+
+func (self CompressedFormatter) FormatDirective(indent int, directive string) string {
+	return directive
+}
+
+func (self CompressedFormatter) FormatMedia(indent int, query string, body string) string {
+	query = strings.Replace(query, ": ", ":", -1)
+	query = strings.Replace(query, ", ", ",", -1)
+	return "@media " + query + "{" + body + "}"
+}
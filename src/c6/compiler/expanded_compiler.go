@@ -0,0 +1,53 @@
+package compiler
+
+import "strings"
+
+/*
+ExpandedStyleCompiler generates the "expanded" output style of SASS, every
+declaration takes one line and the nesting is not indented:
+
+	#main {
+	  color: #fff;
+	  background-color: #000;
+	}
+	#main p {
+	  width: 10em;
+	}
+*/
+type ExpandedStyleCompiler struct {
+	StyleCompiler
+}
+
+func NewExpandedStyleCompiler() *ExpandedStyleCompiler {
+	return &ExpandedStyleCompiler{StyleCompiler{Formatter: ExpandedFormatter{}, Precision: DefaultPrecision}}
+}
+
+type ExpandedFormatter struct{}
+
+func (self ExpandedFormatter) IsCompressed() bool { return false }
+
+func (self ExpandedFormatter) SelectorSeparator() string { return ", " }
+
+func (self ExpandedFormatter) RuleSetSeparator() string { return "\n" }
+
+func (self ExpandedFormatter) FormatProperty(name string, value string) string {
+	return name + ": " + value + ";"
+}
+
+func (self ExpandedFormatter) FormatRuleSet(indent int, selectors string, properties []string) string {
+	return selectors + " {\n  " + strings.Join(properties, "\n  ") + "\n}\n"
+}
+
+func (self ExpandedFormatter) FormatDirective(indent int, directive string) string {
+	return directive + "\n"
+}
+
+func (self ExpandedFormatter) FormatMedia(indent int, query string, body string) string {
+	var lines = strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return "@media " + query + " {\n" + strings.Join(lines, "\n") + "\n}\n"
+}
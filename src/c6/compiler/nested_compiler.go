@@ -1,32 +1,48 @@
 package compiler
 
-import "c6/ast"
-
-type Compiler interface {
-	CompileBlock(block *ast.Block) string
-}
-
+import "strings"
+
+/*
+NestedStyleCompiler generates the "nested" output style of SASS, the
+declarations are indented by the nesting level of the ruleset and the closing
+brace is placed at the end of the last declaration:
+
+	#main {
+	  color: #fff;
+	  background-color: #000; }
+	  #main p {
+	    width: 10em; }
+*/
 type NestedStyleCompiler struct {
-	Indent int
-	Output string
+	StyleCompiler
 }
 
 func NewNestedStyleCompiler() *NestedStyleCompiler {
-	return &NestedStyleCompiler{}
+	return &NestedStyleCompiler{StyleCompiler{Formatter: NestedFormatter{}, Precision: DefaultPrecision}}
 }
 
-func (self *NestedStyleCompiler) CompileProperty(property *ast.Property) {
+type NestedFormatter struct{}
 
-}
+func (self NestedFormatter) IsCompressed() bool { return false }
 
-func (self *NestedStyleCompiler) CompileSeletors(selectors *ast.Selector) {
+func (self NestedFormatter) SelectorSeparator() string { return ", " }
 
-}
+func (self NestedFormatter) RuleSetSeparator() string { return "\n" }
 
-func (self *NestedStyleCompiler) CompileRuleSet(ruleset *ast.RuleSet) {
+func (self NestedFormatter) FormatProperty(name string, value string) string {
+	return name + ": " + value + ";"
+}
 
+func (self NestedFormatter) FormatRuleSet(indent int, selectors string, properties []string) string {
+	var prefix = strings.Repeat("  ", indent)
+	return prefix + selectors + " {\n" +
+		prefix + "  " + strings.Join(properties, "\n"+prefix+"  ") + " }\n"
 }
 
-func (self *NestedStyleCompiler) CompileBlock(block *ast.Block) {
+func (self NestedFormatter) FormatDirective(indent int, directive string) string {
+	return strings.Repeat("  ", indent) + directive + "\n"
+}
 
+func (self NestedFormatter) FormatMedia(indent int, query string, body string) string {
+	return strings.Repeat("  ", indent) + "@media " + query + " {\n" + strings.TrimSuffix(body, "\n") + " }\n"
 }
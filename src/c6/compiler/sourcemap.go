@@ -0,0 +1,219 @@
+package compiler
+
+import "encoding/base64"
+import "encoding/json"
+import "sort"
+import "strconv"
+import "strings"
+import "unicode/utf8"
+import "c6/ast"
+
+// The source name of the code that is not read from a file
+const AnonymousSource = "stdin"
+
+/*
+Mapping maps the generated position of the CSS to the original position of
+the source, the lines and the columns start from 0 as in the source map.
+*/
+type Mapping struct {
+	GeneratedLine   int
+	GeneratedColumn int
+
+	Source string
+	Line   int
+	Column int
+}
+
+/*
+SourceMap is the revision 3 source map:
+
+	{"version":3,"file":"out.css","sources":["in.scss"],"names":[],"mappings":"AAAA,..."}
+*/
+type SourceMap struct {
+	Version        int      `json:"version"`
+	File           string   `json:"file,omitempty"`
+	Sources        []string `json:"sources"`
+	SourcesContent []string `json:"sourcesContent,omitempty"`
+	Names          []string `json:"names"`
+	Mappings       string   `json:"mappings"`
+}
+
+/*
+NewSourceMap creates the source map of the generated file from the mappings,
+the sources are listed in the order of their first mapping.
+*/
+func NewSourceMap(file string, mappings []Mapping) *SourceMap {
+	var sorted = make([]Mapping, len(mappings))
+	copy(sorted, mappings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].GeneratedLine != sorted[j].GeneratedLine {
+			return sorted[i].GeneratedLine < sorted[j].GeneratedLine
+		}
+		return sorted[i].GeneratedColumn < sorted[j].GeneratedColumn
+	})
+
+	var m = &SourceMap{Version: 3, File: file, Sources: []string{}, Names: []string{}}
+	var sourceIndex = map[string]int{}
+
+	// the fields are encoded as the delta from the previous mapping, the
+	// generated column is reset on each line
+	var out = []byte{}
+	var line, column, source, origLine, origColumn = 0, 0, 0, 0, 0
+	for i, mapping := range sorted {
+		var name = mapping.Source
+		if name == "" {
+			name = AnonymousSource
+		}
+		index, ok := sourceIndex[name]
+		if !ok {
+			index = len(m.Sources)
+			sourceIndex[name] = index
+			m.Sources = append(m.Sources, name)
+		}
+
+		if i > 0 && mapping.GeneratedLine == line {
+			out = append(out, ',')
+		}
+		for line < mapping.GeneratedLine {
+			out = append(out, ';')
+			line++
+			column = 0
+		}
+		out = appendVLQ(out, mapping.GeneratedColumn-column)
+		out = appendVLQ(out, index-source)
+		out = appendVLQ(out, mapping.Line-origLine)
+		out = appendVLQ(out, mapping.Column-origColumn)
+		column, source, origLine, origColumn = mapping.GeneratedColumn, index, mapping.Line, mapping.Column
+	}
+	m.Mappings = string(out)
+	return m
+}
+
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+/*
+appendVLQ appends the base64 VLQ of the value, the sign is kept in the lowest
+bit and each digit carries 5 bits with the continuation bit.
+*/
+func appendVLQ(out []byte, value int) []byte {
+	var vlq = value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+	for {
+		var digit = vlq & 31
+		vlq >>= 5
+		if vlq > 0 {
+			digit |= 32
+		}
+		out = append(out, base64Digits[digit])
+		if vlq == 0 {
+			return out
+		}
+	}
+}
+
+/*
+SetSourcesContent embeds the contents of the sources, so the original code is
+available without the source files.
+*/
+func (m *SourceMap) SetSourcesContent(load func(source string) (string, error)) error {
+	var contents = []string{}
+	for _, source := range m.Sources {
+		content, err := load(source)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, content)
+	}
+	m.SourcesContent = contents
+	return nil
+}
+
+func (m *SourceMap) JSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// DataURI returns the source map as the base64 data URI to be inlined in CSS
+func (m *SourceMap) DataURI() (string, error) {
+	data, err := m.JSON()
+	if err != nil {
+		return "", err
+	}
+	return "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// SourceMappingURLComment returns the comment appended to the generated CSS
+func SourceMappingURLComment(url string) string {
+	return "/*# sourceMappingURL=" + url + " */\n"
+}
+
+/*
+The markers are placed in front of the compiled selectors and declarations
+while the mappings are recorded, they are removed after the output is
+assembled by the formatter, so the mappings follow any output style.
+*/
+const (
+	markerStart = '\uE000'
+	markerEnd   = '\uE001'
+)
+
+/*
+CompileBlockWithMappings compiles the block, and returns the mappings from
+the generated selectors and declarations to their source positions.
+*/
+func (self *StyleCompiler) CompileBlockWithMappings(block *ast.Block) (string, []Mapping) {
+	self.origins = []ast.Range{}
+	var out = self.CompileBlock(block)
+	var origins = self.origins
+	self.origins = nil
+	return extractMappings(out, origins)
+}
+
+// mark prefixes the compiled text with the marker of the source range
+func (self *StyleCompiler) mark(r ast.Range, text string) string {
+	if self.origins == nil || !r.IsValid() {
+		return text
+	}
+	self.origins = append(self.origins, r)
+	return string(markerStart) + strconv.Itoa(len(self.origins)-1) + string(markerEnd) + text
+}
+
+/*
+extractMappings removes the markers from the output and records their
+positions, the generated columns count the UTF-16 code units.
+*/
+func extractMappings(out string, origins []ast.Range) (string, []Mapping) {
+	var mappings = []Mapping{}
+	var css strings.Builder
+	var line, column = 0, 0
+	for i := 0; i < len(out); {
+		var r, width = utf8.DecodeRuneInString(out[i:])
+		if r == markerStart {
+			var end = strings.IndexRune(out[i:], markerEnd)
+			index, _ := strconv.Atoi(out[i+width : i+end])
+			var origin = origins[index]
+			mappings = append(mappings, Mapping{
+				GeneratedLine:   line,
+				GeneratedColumn: column,
+				Source:          origin.File,
+				Line:            origin.Start.Line - 1,
+				Column:          origin.Start.Column - 1,
+			})
+			i += end + utf8.RuneLen(markerEnd)
+			continue
+		}
+
+		css.WriteString(out[i : i+width])
+		i += width
+		if r == '\n' {
+			line++
+			column = 0
+		} else if r >= 0x10000 {
+			column += 2
+		} else {
+			column++
+		}
+	}
+	return css.String(), mappings
+}
@@ -0,0 +1,189 @@
+package compiler
+
+import "fmt"
+import "math"
+import "sort"
+import "strconv"
+import "strings"
+import "c6/ast"
+
+/*
+CompileValue renders an expression of the property value into CSS.
+
+The expressions that can be evaluated are rendered with the computed value,
+the others are rendered as they are written.
+*/
+func (self *StyleCompiler) CompileValue(expr ast.Expression) string {
+	switch t := expr.(type) {
+	case *ast.List:
+		var out []string
+		for _, item := range t.Expressions {
+			// the null items are left out like `1 null 2`
+			if isNullValue(item) {
+				continue
+			}
+			out = append(out, self.CompileValue(item))
+		}
+		var sep = t.Separator
+		if self.Formatter.IsCompressed() {
+			sep = strings.TrimSpace(sep)
+			if sep == "" {
+				sep = " "
+			}
+		}
+		return strings.Join(out, sep)
+	case *ast.Number:
+		return self.CompileNumber(t.Value)
+	case *ast.Length:
+		return self.CompileLength(t)
+	case *ast.HexColor:
+		if self.Formatter.IsCompressed() {
+			return shortestColor(t.R, t.G, t.B)
+		}
+		return t.String()
+	case *ast.RGBColor:
+		if self.Formatter.IsCompressed() {
+			return shortestColor(t.R, t.G, t.B)
+		}
+		return t.String()
+	case *ast.RGBAColor:
+		if self.Formatter.IsCompressed() {
+			return fmt.Sprintf("rgba(%d,%d,%d,%s)", t.R, t.G, t.B, self.CompileNumber(float64(t.A)))
+		}
+		return t.String()
+	case *ast.String:
+		if t.Quote != 0 {
+			return string(t.Quote) + t.Value + string(t.Quote)
+		}
+		// color keywords may be replaced with a shorter hex code
+		if self.Formatter.IsCompressed() {
+			if hex, ok := ast.ColorKeywords[strings.ToLower(t.Value)]; ok {
+				var r, g, b, _ = ast.HexToRGBA(hex)
+				if color := shortestColor(r, g, b); len(color) < len(t.Value) {
+					return color
+				}
+			}
+		}
+		return t.Value
+	case ast.FunctionCall:
+		return self.compileFunctionCall(&t)
+	case *ast.FunctionCall:
+		return self.compileFunctionCall(t)
+	case *ast.Interpolation:
+		return self.CompileValue(t.Expression)
+	case *ast.LiteralConcat:
+		return self.CompileValue(t.Left) + self.CompileValue(t.Right)
+	case *ast.UnaryExpression:
+		if val := t.Evaluate(nil); val != nil {
+			return self.CompileValue(val)
+		}
+		return t.Op.Symbol() + self.CompileValue(t.Expr)
+	case *ast.BinaryExpression:
+		// the slash is rendered as the separator
+		if t.Op == ast.OpDiv {
+			return self.compileOperand(t.Left) + "/" + self.compileOperand(t.Right)
+		}
+		if val := t.Evaluate(nil); val != nil {
+			return self.CompileValue(val)
+		}
+		return self.compileOperand(t.Left) + " " + t.Op.Symbol() + " " + self.compileOperand(t.Right)
+	}
+	return expr.String()
+}
+
+// compileOperand keeps the parenthesis of the grouped operation, like the
+// `(100% - 10px)` of `calc((100% - 10px)/3)`.
+func (self *StyleCompiler) compileOperand(expr ast.Expression) string {
+	if bexpr, ok := expr.(*ast.BinaryExpression); ok && bexpr.Grouped {
+		return "(" + self.CompileValue(expr) + ")"
+	}
+	return self.CompileValue(expr)
+}
+
+func (self *StyleCompiler) compileFunctionCall(fcall *ast.FunctionCall) string {
+	var args []string
+	for _, arg := range fcall.Arguments {
+		args = append(args, self.CompileValue(arg))
+	}
+	if self.Formatter.IsCompressed() {
+		return fcall.Function + "(" + strings.Join(args, ",") + ")"
+	}
+	return fcall.Function + "(" + strings.Join(args, ", ") + ")"
+}
+
+/*
+CompileNumber rounds the number by the precision and strips the trailing
+zeros, the compressed style also strips the leading zero: `0.5` => `.5`.
+*/
+func (self *StyleCompiler) CompileNumber(val float64) string {
+	if out, ok := ast.FormatNonFinite(val); ok {
+		return out
+	}
+	var scale = math.Pow(10, float64(self.Precision))
+	val = math.Round(val*scale) / scale
+	if val == 0 {
+		// avoid "-0"
+		val = 0
+	}
+	var out = strconv.FormatFloat(val, 'f', -1, 64)
+	if self.Formatter.IsCompressed() {
+		if strings.HasPrefix(out, "0.") {
+			out = out[1:]
+		} else if strings.HasPrefix(out, "-0.") {
+			out = "-" + out[2:]
+		}
+	}
+	return out
+}
+
+func (self *StyleCompiler) CompileLength(length *ast.Length) string {
+	var out = self.CompileNumber(length.Value)
+	// the unit of zero length is useless
+	if self.Formatter.IsCompressed() && out == "0" && isLengthUnit(length.Unit) {
+		return out
+	}
+	return out + length.UnitString()
+}
+
+func isLengthUnit(unit ast.UnitType) bool {
+	switch unit {
+	case ast.UNIT_EM, ast.UNIT_EX, ast.UNIT_CH, ast.UNIT_REM,
+		ast.UNIT_CM, ast.UNIT_IN, ast.UNIT_MM, ast.UNIT_PC, ast.UNIT_PT, ast.UNIT_PX,
+		ast.UNIT_VH, ast.UNIT_VW, ast.UNIT_VMIN, ast.UNIT_VMAX:
+		return true
+	}
+	return false
+}
+
+// The shortest color keyword of the hex codes, e.g. "#ff0000" => "red"
+var colorKeywordsByHex = map[string]string{}
+
+func init() {
+	var names []string
+	for name := range ast.ColorKeywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var hex = strings.ToLower(ast.ColorKeywords[name])
+		if found, ok := colorKeywordsByHex[hex]; !ok || len(name) < len(found) {
+			colorKeywordsByHex[hex] = name
+		}
+	}
+}
+
+/*
+shortestColor returns the shortest representation of the color, it could be
+the 3 characters hex code, the 6 characters hex code or the color keyword.
+*/
+func shortestColor(r, g, b uint32) string {
+	var hex = fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	var out = hex
+	if hex[1] == hex[2] && hex[3] == hex[4] && hex[5] == hex[6] {
+		out = "#" + hex[1:2] + hex[3:4] + hex[5:6]
+	}
+	if name, ok := colorKeywordsByHex[hex]; ok && len(name) < len(out) {
+		return name
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package c6
+
+import "testing"
+import "c6/ast"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func RunCompilerTest(code string, c compiler.Compiler) string {
+	var block = RunParserTest(code)
+	return c.CompileBlock(NewContext().Evaluate(block))
+}
+
+func TestNestedStyleCompilerRuleSet(t *testing.T) {
+	var out = RunCompilerTest(`div { width: 1px; height: 2px }`, compiler.NewNestedStyleCompiler())
+	assert.Equal(t, "div {\n  width: 1px;\n  height: 2px; }\n", out)
+}
+
+func TestNestedStyleCompilerSelectorList(t *testing.T) {
+	var out = RunCompilerTest(`div.foo, #bar > a:hover { color: #fff; }`, compiler.NewNestedStyleCompiler())
+	assert.Equal(t, "div.foo, #bar > a:hover {\n  color: #fff; }\n", out)
+}
+
+func TestNestedStyleCompilerMultipleRuleSets(t *testing.T) {
+	var out = RunCompilerTest(`.a { width: 10px + 2px; } .empty { } .b { margin: 0 auto; }`, compiler.NewNestedStyleCompiler())
+	assert.Equal(t, ".a {\n  width: 12px; }\n\n.b {\n  margin: 0 auto; }\n", out)
+}
+
+func TestNestedStyleCompilerPropertyValues(t *testing.T) {
+	var out = RunCompilerTest(`.x { background: url("a.png") no-repeat; font-family: "Helvetica", arial; }`, compiler.NewNestedStyleCompiler())
+	assert.Equal(t, ".x {\n  background: url(\"a.png\") no-repeat;\n  font-family: \"Helvetica\", arial; }\n", out)
+}
+
+func TestNestedStyleCompilerCharsetAndImport(t *testing.T) {
+	var out = RunCompilerTest(`@charset "UTF-8"; @import url("foo.css") screen; a[href] { color: red; }`, compiler.NewNestedStyleCompiler())
+	assert.Equal(t, "@charset \"UTF-8\";\n@import url(foo.css) screen;\na[href] {\n  color: red; }\n", out)
+}
+
+func TestNestedStyleCompilerSubRuleSet(t *testing.T) {
+	var block = RunParserTest(`.a { color: red; }`)
+	var ruleset = block.Statements[0].(*ast.RuleSet)
+	var sub = RunParserTest(`.a .b { color: blue; }`).Statements[0].(*ast.RuleSet)
+	ruleset.AppendSubRuleSet(sub)
+	var out = compiler.NewNestedStyleCompiler().CompileBlock(block)
+	assert.Equal(t, ".a {\n  color: red; }\n  .a .b {\n    color: blue; }\n", out)
+}
+
+const styleTestCode = `@import url("foo.css"); div.foo, #bar > a { color: #ffffff; margin: 0px auto; } .b { width: 0.5em; }`
+
+func TestExpandedStyleCompiler(t *testing.T) {
+	var out = RunCompilerTest(styleTestCode, compiler.NewExpandedStyleCompiler())
+	assert.Equal(t, "@import url(foo.css);\ndiv.foo, #bar > a {\n  color: #ffffff;\n  margin: 0px auto;\n}\n\n.b {\n  width: 0.5em;\n}\n", out)
+}
+
+func TestCompactStyleCompiler(t *testing.T) {
+	var out = RunCompilerTest(styleTestCode, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, "@import url(foo.css);\ndiv.foo, #bar > a { color: #ffffff; margin: 0px auto; }\n\n.b { width: 0.5em; }\n", out)
+}
+
+func TestCompressedStyleCompiler(t *testing.T) {
+	var out = RunCompilerTest(styleTestCode, compiler.NewCompressedStyleCompiler())
+	assert.Equal(t, "@import url(foo.css);div.foo,#bar>a{color:#fff;margin:0 auto}.b{width:.5em}", out)
+}
+
+func TestCompressedStyleCompilerColors(t *testing.T) {
+	var out = RunCompilerTest(`a { color: #ff0000; background: white; border-color: #abcdef; }`, compiler.NewCompressedStyleCompiler())
+	assert.Equal(t, "a{color:red;background:#fff;border-color:#abcdef}", out)
+}
+
+func TestStyleCompilerPrecision(t *testing.T) {
+	var c = compiler.NewExpandedStyleCompiler()
+	c.Precision = 2
+	var out = RunCompilerTest(`a { width: 1.23456px; }`, c)
+	assert.Equal(t, "a {\n  width: 1.23px;\n}\n", out)
+}
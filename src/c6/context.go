@@ -1,5 +1,6 @@
 package c6
 
+import "fmt"
 import "c6/ast"
 
 /**
@@ -8,15 +9,136 @@ The Context contains all runtime variables and ruleset stack
 type Context struct {
 	RuleSetStack []*ast.RuleSet
 
-	// SymTableStack  []*ast.SymTable
+	// The local scopes, the innermost scope is at the end
+	Scopes         []*Scope
 	GlobalSymTable ast.SymTable
+
+	// The declared mixins by name
+	Mixins map[string]*Mixin
+
+	// The user-defined functions by name
+	Functions map[string]*Function
+
+	// The functions implemented in Go by name, they are shared by the
+	// contexts of the modules.
+	customFunctions map[string]*customFunction
+
+	// The content block passed to the mixin being included
+	Content *ContentBlock
+
+	// The directories to look up the imported files
+	LoadPaths []string
+
+	// The importers to load the imported files, they are tried before the
+	// load paths.
+	Importers []Importer
+
+	// The importers of the canonical urls resolved in the compilation, it's
+	// shared by the contexts of the modules.
+	canonicalImporters map[string]Importer
+
+	// The files being evaluated, the current file is at the end
+	ImportStack []*ImportFrame
+
+	// Import each file only once like `@import-once`
+	ImportOnce bool
+
+	// The files included in the compilation, it's shared by the contexts
+	// of the modules.
+	ImportedFiles map[string]bool
+
+	// The modules loaded in the compilation by file path, it's shared by
+	// the contexts of the modules, so each module is evaluated once.
+	Modules map[string]*Module
+
+	// The modules loaded by `@use` by namespace
+	Namespaces map[string]*Module
+
+	// The modules loaded by `@use ... as *`, their members are accessed
+	// without the namespace.
+	GlobalModules []*Module
+
+	// The modules forwarded by `@forward`
+	Forwards []*ModuleForward
+
+	// The variables configured by `@use ... with (...)`, they override the
+	// `!default` variables of the module.
+	Configuration map[string]ast.Expression
+
+	// The warnings reported by `@warn`, they are shared by the contexts of
+	// the modules.
+	Warnings *[]Diagnostic
+
+	// The depth of the nested mixin and function calls, it's shared by the
+	// contexts of the modules.
+	callDepth *int
+}
+
+// MaxCallDepth is the limit of the nested mixin and function calls
+const MaxCallDepth = 1000
+
+/*
+Scope is one level of the lexical scope chain, the rulesets, the mixins, the
+functions and the flow control blocks create their own scopes.
+*/
+type Scope struct {
+	SymTable ast.SymTable
+
+	// The scope of the flow control blocks (@if, @each, @for and @while)
+	// doesn't shadow the variables: the assignments update the existing
+	// variables of the enclosing scopes, and also the global variables if
+	// the flow control blocks are at the top level.
+	FlowControl bool
 }
 
 func NewContext() *Context {
-	var context = &Context{[]*ast.RuleSet{}, ast.SymTable{}}
+	var context = &Context{
+		RuleSetStack:       []*ast.RuleSet{},
+		Scopes:             []*Scope{},
+		GlobalSymTable:     ast.SymTable{},
+		Mixins:             map[string]*Mixin{},
+		Functions:          map[string]*Function{},
+		customFunctions:    map[string]*customFunction{},
+		LoadPaths:          []string{},
+		Importers:          []Importer{},
+		canonicalImporters: map[string]Importer{},
+		ImportStack:        []*ImportFrame{},
+		ImportedFiles:      map[string]bool{},
+		Modules:            map[string]*Module{},
+		Namespaces:         map[string]*Module{},
+		Warnings:           &[]Diagnostic{},
+		callDepth:          new(int),
+	}
 	return context
 }
 
+/*
+enterCall counts the nested call of the mixin or the function, the infinite
+recursion is stopped before it overflows the stack.
+*/
+func (context *Context) enterCall() {
+	*context.callDepth++
+	if *context.callDepth > MaxCallDepth {
+		panic(fmt.Errorf("Stack depth exceeded"))
+	}
+}
+
+func (context *Context) leaveCall() {
+	*context.callDepth--
+}
+
+/*
+Warn reports the warning at the statement of the token in the current file.
+*/
+func (context *Context) Warn(message string, token *ast.Token) {
+	*context.Warnings = append(*context.Warnings, Diagnostic{
+		Severity: SeverityWarning,
+		File:     context.CurrentFile(),
+		Range:    ast.TokenRange(token),
+		Message:  message,
+	})
+}
+
 func (context *Context) PushRuleSet(ruleSet *ast.RuleSet) {
 	var newStack = append(context.RuleSetStack, ruleSet)
 	context.RuleSetStack = newStack
@@ -29,23 +151,98 @@ func (context *Context) PopRuleSet() *ast.RuleSet {
 	}
 	var idx = len(context.RuleSetStack) - 1
 	ruleSet := context.RuleSetStack[idx]
-	context.RuleSetStack = context.RuleSetStack[:idx-1]
+	context.RuleSetStack = context.RuleSetStack[:idx]
 	return ruleSet
 }
 
+func (context *Context) PushScope(flowControl bool) *Scope {
+	var scope = &Scope{SymTable: ast.SymTable{}, FlowControl: flowControl}
+	context.Scopes = append(context.Scopes, scope)
+	return scope
+}
+
+func (context *Context) PopScope() {
+	if len(context.Scopes) > 0 {
+		context.Scopes = context.Scopes[:len(context.Scopes)-1]
+	}
+}
+
+/*
+GetVariable looks up the variable from the innermost scope to the global
+scope, and then the modules used without namespace. The namespaced variable
+like `math.$pi` is looked up from the module.
+*/
 func (context *Context) GetVariable(name string) *ast.Variable {
-	/*
-		var idx = len(context.SymTableStack) - 1
-		for ; idx > 0; idx-- {
-			stack := context.SymTableStack[idx]
-			if variable := stack.FindVariable(name); variable != nil {
-				return variable
-			}
+	if namespace, member := splitNamespace(name); namespace != "" {
+		return context.LookupModule(namespace).Variable(member)
+	}
+	for idx := len(context.Scopes) - 1; idx >= 0; idx-- {
+		if variable := context.Scopes[idx].SymTable.FindVariable(name); variable != nil {
+			return variable
 		}
-	*/
+	}
+	if variable := context.GlobalSymTable.FindVariable(name); variable != nil {
+		return variable
+	}
+	for _, module := range context.GlobalModules {
+		if variable := module.Variable(name); variable != nil {
+			return variable
+		}
+	}
 	return nil
 }
 
+/*
+SetVariable assigns the variable with the Sass semantics:
+
+The variable defined in the enclosing local scopes is updated. Otherwise the
+variable is defined in the innermost scope, which shadows the global variable
+with the same name, except in the flow control blocks at the top level, they
+update the global variable.
+*/
+func (context *Context) SetVariable(variable *ast.Variable) {
+	for idx := len(context.Scopes) - 1; idx >= 0; idx-- {
+		if context.Scopes[idx].SymTable.HasVariable(variable) {
+			context.Scopes[idx].SymTable.AddVariable(variable)
+			return
+		}
+	}
+	if context.inSemiGlobalScope() && context.GlobalSymTable.HasVariable(variable) {
+		context.GlobalSymTable.AddVariable(variable)
+		return
+	}
+	context.DefineVariable(variable)
+}
+
+/*
+DefineVariable defines the variable in the innermost scope without looking up
+the enclosing scopes, e.g. the arguments and the loop variables.
+*/
+func (context *Context) DefineVariable(variable *ast.Variable) {
+	if len(context.Scopes) > 0 {
+		context.Scopes[len(context.Scopes)-1].SymTable.AddVariable(variable)
+	} else {
+		context.GlobalSymTable.AddVariable(variable)
+	}
+}
+
+/*
+SetGlobalVariable assigns the variable in the global scope for `!global`.
+*/
+func (context *Context) SetGlobalVariable(variable *ast.Variable) {
+	context.GlobalSymTable.AddVariable(variable)
+}
+
+// all the local scopes are flow control blocks at the top level
+func (context *Context) inSemiGlobalScope() bool {
+	for _, scope := range context.Scopes {
+		if !scope.FlowControl {
+			return false
+		}
+	}
+	return true
+}
+
 func (context *Context) TopRuleSet() *ast.RuleSet {
 	if len(context.RuleSetStack) > 0 {
 		return context.RuleSetStack[len(context.RuleSetStack)-1]
@@ -0,0 +1,174 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "math"
+import "c6/ast"
+
+/*
+IsTrue reports the truthiness of the value, only `false` and `null` are
+falsey.
+*/
+func IsTrue(val ast.Expression) bool {
+	return ast.IsTruthy(val)
+}
+
+/*
+IsNull reports whether the value is `null`.
+*/
+func IsNull(val ast.Expression) bool {
+	if _, ok := val.(*ast.Null); ok {
+		return true
+	}
+	return val == nil
+}
+
+/*
+ExecuteControlStatement runs the control directive, the block of each
+iteration is passed to the run function, and the iteration stops when the
+run function returns true, e.g. the function body returns a value.
+
+Each block is run in a new flow control scope, the assignments in the block
+update the existing variables, and the new variables and the loop variables
+are local to the block.
+*/
+func (context *Context) ExecuteControlStatement(stm ast.Statement, run func(block *ast.DeclarationBlock) bool) {
+	switch t := stm.(type) {
+	case *ast.IfStatement:
+		if IsTrue(context.EvaluateExpression(t.Condition)) {
+			context.runFlowControlBlock(t.Block, nil, run)
+			return
+		}
+		for _, elseIf := range t.ElseIfs {
+			if IsTrue(context.EvaluateExpression(elseIf.Condition)) {
+				context.runFlowControlBlock(elseIf.Block, nil, run)
+				return
+			}
+		}
+		if t.ElseBlock != nil {
+			context.runFlowControlBlock(t.ElseBlock, nil, run)
+		}
+
+	case *ast.EachStatement:
+		for _, item := range eachItems(context.EvaluateExpression(t.List)) {
+			var bind = func() {
+				context.bindEachVariables(t.Variables, item)
+			}
+			if context.runFlowControlBlock(t.Block, bind, run) {
+				return
+			}
+		}
+
+	case *ast.ForStatement:
+		var from, unit = forBound(context.EvaluateExpression(t.From))
+		var to, _ = forBound(context.EvaluateExpression(t.To))
+		var step = 1
+		if from > to {
+			step = -1
+		}
+		if t.Inclusive {
+			to += step
+		}
+		for i := from; i != to; i += step {
+			var value ast.Expression = ast.NewNumber(float64(i), nil)
+			if unit != ast.UNIT_NONE {
+				value = ast.NewLength(float64(i), unit, nil)
+			}
+			var bind = func() {
+				context.DefineVariable(&ast.Variable{Name: t.Variable.Name, Value: value, Token: t.Variable.Token})
+			}
+			if context.runFlowControlBlock(t.Block, bind, run) {
+				return
+			}
+		}
+
+	case *ast.WhileStatement:
+		for IsTrue(context.EvaluateExpression(t.Condition)) {
+			if context.runFlowControlBlock(t.Block, nil, run) {
+				return
+			}
+		}
+
+	default:
+		panic(fmt.Errorf("Unsupported control directive: %+v", stm))
+	}
+}
+
+/*
+runFlowControlBlock runs the block in a new flow control scope, the bind
+function defines the loop variables in the scope.
+*/
+func (context *Context) runFlowControlBlock(block *ast.DeclarationBlock, bind func(), run func(block *ast.DeclarationBlock) bool) bool {
+	context.PushScope(true)
+	defer context.PopScope()
+	if bind != nil {
+		bind()
+	}
+	return run(block)
+}
+
+/*
+eachItems returns the items to iterate, the map items are the key-value
+pairs.
+*/
+func eachItems(val ast.Expression) []ast.Expression {
+	switch t := val.(type) {
+	case *ast.List:
+		return t.Expressions
+	case *ast.Map:
+		var items = []ast.Expression{}
+		for i, key := range t.Keys {
+			var pair = ast.NewList()
+			pair.Append(key)
+			pair.Append(t.Values[i])
+			items = append(items, pair)
+		}
+		return items
+	}
+	return []ast.Expression{val}
+}
+
+/*
+bindEachVariables assigns the item to the variable, or destructures the item
+into the variables when there are more than one variable. The missing values
+are null.
+*/
+func (context *Context) bindEachVariables(variables []*ast.Variable, item ast.Expression) {
+	if len(variables) == 1 {
+		context.DefineVariable(&ast.Variable{Name: variables[0].Name, Value: item, Token: variables[0].Token})
+		return
+	}
+
+	var values = []ast.Expression{item}
+	if list, ok := item.(*ast.List); ok {
+		values = list.Expressions
+	}
+	for i, variable := range variables {
+		var value ast.Expression = ast.NewNull(nil)
+		if i < len(values) {
+			value = values[i]
+		}
+		context.DefineVariable(&ast.Variable{Name: variable.Name, Value: value, Token: variable.Token})
+	}
+}
+
+// the bound of @for must be an integer, the unit is kept for the variable.
+func forBound(val ast.Expression) (int, ast.UnitType) {
+	var num float64
+	var unit = ast.UNIT_NONE
+	switch t := val.(type) {
+	case *ast.Number:
+		num = t.Value
+	case *ast.Length:
+		num, unit = t.Value, t.Unit
+	default:
+		panic(fmt.Errorf("Expecting integer for @for, got %s", val))
+	}
+	if num != math.Trunc(num) {
+		panic(fmt.Errorf("Expecting integer for @for, got %s", val))
+	}
+	return int(num), unit
+}
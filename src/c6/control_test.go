@@ -0,0 +1,76 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestControlIfElse(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin theme($dark, $contrast: null) {
+	@if $dark { color: white; } @else if $contrast { color: black; } @else { color: gray; }
+}
+.a { @include theme(true); }
+.b { @include theme(false, true); }
+.c { @include theme(null); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: white; }\n\n.b { color: black; }\n\n.c { color: gray; }\n", out)
+}
+
+func TestControlEachList(t *testing.T) {
+	var out = RunCompilerTest(`
+$sizes: 1px 2px 3px;
+.a { @each $size in $sizes { margin: $size; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { margin: 1px; margin: 2px; margin: 3px; }\n", out)
+}
+
+func TestControlEachDestructuring(t *testing.T) {
+	var out = RunCompilerTest(`
+$icons: (home: 1px, menu: 2px);
+$pairs: (a 1px, b 2px 3px);
+.a { @each $name, $size in $icons { icon: $name $size; } }
+.b { @each $x, $y, $z in $pairs { width: $x $y; height: $z; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { icon: home 1px; icon: menu 2px; }\n\n.b { width: a 1px; width: b 2px; height: 3px; }\n", out)
+
+	// the missing items are null
+	out = RunCompilerTest(`@each $a, $b, $c in (1 2, 3 4) { .c { w: $a $b $c; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".c { w: 1 2; }\n\n.c { w: 3 4; }\n", out)
+}
+
+func TestControlFor(t *testing.T) {
+	var out = RunCompilerTest(`
+$n: 3;
+.a { @for $i from 1 through $n { width: $i * 10px; } }
+.b { @for $i from 3 to 1 { width: $i; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 10px; width: 20px; width: 30px; }\n\n.b { width: 3; width: 2; }\n", out)
+}
+
+func TestControlWhile(t *testing.T) {
+	var out = RunCompilerTest(`
+$items: a b c;
+$more: true;
+@while $more { $more: false; $items: $items d; }
+.a { content: $items; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { content: a b c d; }\n", out)
+}
+
+func TestControlInFunction(t *testing.T) {
+	var out = RunCompilerTest(`
+@function sum($numbers...) {
+	$total: 0px;
+	@each $n in $numbers { $total: $total + $n; }
+	@return $total;
+}
+@function pick($a) {
+	@for $i from 1 through 10 { @if $a { @return $i; } }
+	@return 0;
+}
+.a { width: sum(1px, 2px, 3px); height: pick(true); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 6px; height: 1; }\n", out)
+}
+
+func TestControlRuleSetBeforeAssignment(t *testing.T) {
+	var out = RunCompilerTest(`
+$i: 3;
+@while $i > 0 { .w { w: $i; } $i: $i - 1; }
+.a { $n: 1; @if true { .b { n: $n; } $n: 2; } .c { n: $n; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".w { w: 3; }\n\n.w { w: 2; }\n\n.w { w: 1; }\n\n.a .b { n: 1; }\n.a .c { n: 2; }\n", out)
+}
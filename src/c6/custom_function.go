@@ -0,0 +1,138 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "strings"
+import "sync"
+import "c6/ast"
+
+/*
+CustomFunction is the function implemented in Go, it's called with the
+evaluated arguments.
+*/
+type CustomFunction func(args []ast.Value) (ast.Value, error)
+
+/*
+customFunction is the custom function with its signature, the arguments are
+bound by the argument list like the user-defined function. The function
+registered by the name without the argument list takes the positional
+arguments as they are passed.
+*/
+type customFunction struct {
+	Name         string
+	ArgumentList *ast.ArgumentList
+	Func         CustomFunction
+}
+
+var registeredFunctions = map[string]*customFunction{}
+var registeredFunctionsLock sync.RWMutex
+
+/*
+RegisterFunction registers the custom function for all the compilations by
+the Sass signature:
+
+	c6.RegisterFunction("asset-url($path, $v: null)", func(args []ast.Value) (ast.Value, error) {
+		...
+	})
+
+The function is called with the arguments in the order of the signature, the
+keyword arguments and the default values are bound like the user-defined
+functions, and the rest argument `$args...` is passed as a list. The
+user-defined functions of the stylesheet take precedence over the registered
+functions. It panics if the signature is invalid.
+*/
+func RegisterFunction(signature string, fn CustomFunction) {
+	custom, err := newCustomFunction(signature, fn)
+	if err != nil {
+		panic(err)
+	}
+	registeredFunctionsLock.Lock()
+	defer registeredFunctionsLock.Unlock()
+	registeredFunctions[custom.Name] = custom
+}
+
+// UnregisterFunction removes the function registered by RegisterFunction
+func UnregisterFunction(name string) {
+	registeredFunctionsLock.Lock()
+	defer registeredFunctionsLock.Unlock()
+	delete(registeredFunctions, name)
+}
+
+func lookupRegisteredFunction(name string) *customFunction {
+	registeredFunctionsLock.RLock()
+	defer registeredFunctionsLock.RUnlock()
+	return registeredFunctions[name]
+}
+
+/*
+newCustomFunction parses the signature, like `asset-url($path, $v: null)`, as
+the signature of `@function`. The signature without the parentheses is the
+name of the function.
+*/
+func newCustomFunction(signature string, fn CustomFunction) (*customFunction, error) {
+	signature = strings.TrimSpace(signature)
+	if !strings.Contains(signature, "(") {
+		if signature == "" || strings.ContainsAny(signature, " \t\n;{}$") {
+			return nil, fmt.Errorf("Invalid function signature: %s", signature)
+		}
+		return &customFunction{Name: signature, Func: fn}, nil
+	}
+
+	block, err := NewParser(NewContext()).ParseScss("@function " + signature + " {}")
+	if err != nil || len(block.Statements) != 1 {
+		return nil, fmt.Errorf("Invalid function signature: %s", signature)
+	}
+	stm, ok := block.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		return nil, fmt.Errorf("Invalid function signature: %s", signature)
+	}
+	return &customFunction{Name: stm.Name, ArgumentList: stm.ArgumentList, Func: fn}, nil
+}
+
+/*
+lookupCustomFunction finds the custom function of the compilation, and then
+the registered function.
+*/
+func (context *Context) lookupCustomFunction(name string) *customFunction {
+	if fn, ok := context.customFunctions[name]; ok {
+		return fn
+	}
+	return lookupRegisteredFunction(name)
+}
+
+/*
+callCustomFunction evaluates the arguments in the current scope, binds them by
+the signature and calls the Go function.
+*/
+func (context *Context) callCustomFunction(fn *customFunction, argExprs []ast.Expression) ast.Expression {
+	var args = context.evaluateCallArguments(argExprs)
+	var values = []ast.Value{}
+	if fn.ArgumentList == nil {
+		if len(args.Keywords) > 0 {
+			panic(fmt.Errorf("Function %s doesn't accept keyword arguments", fn.Name))
+		}
+		for _, arg := range args.Positional {
+			values = append(values, arg)
+		}
+	} else {
+		// the default values are evaluated in the scope of the arguments
+		var scope = context.PushScope(false)
+		context.bindArguments("function "+fn.Name, fn.ArgumentList, args, scope.SymTable)
+		context.PopScope()
+		for _, arg := range fn.ArgumentList.Arguments {
+			values = append(values, scope.SymTable.FindVariable(arg.Name).Value)
+		}
+	}
+
+	value, err := fn.Func(values)
+	if err != nil {
+		panic(fmt.Errorf("Function %s failed: %s", fn.Name, err))
+	}
+	if value == nil {
+		panic(fmt.Errorf("Function %s returned no value", fn.Name))
+	}
+	return value
+}
@@ -0,0 +1,78 @@
+package c6
+
+import "strings"
+import "testing"
+import "c6/ast"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func joinValues(args []ast.Value) (ast.Value, error) {
+	var strs = []string{}
+	for _, arg := range args {
+		strs = append(strs, arg.String())
+	}
+	return &ast.String{Value: strings.Join(strs, "|")}, nil
+}
+
+func TestRegisterFunction(t *testing.T) {
+	RegisterFunction("asset-url($path, $v: null)", func(args []ast.Value) (ast.Value, error) {
+		var url = args[0].(*ast.String).Value
+		if !IsNull(args[1]) {
+			url += "?v=" + args[1].String()
+		}
+		return &ast.String{Quote: '"', Value: url}, nil
+	})
+	defer UnregisterFunction("asset-url")
+
+	var out = RunCompilerTest(`
+.a { background: url(asset-url("a.png")); }
+.b { background: url(asset-url("b.png", 2)); }
+.c { background: url(asset-url($v: 3, $path: "c.png")); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { background: url(\"a.png\"); }\n\n.b { background: url(\"b.png?v=2\"); }\n\n.c { background: url(\"c.png?v=3\"); }\n", out)
+
+	assert.Panics(t, func() {
+		RunCompilerTest(`.a { background: asset-url(); }`, compiler.NewCompactStyleCompiler())
+	})
+	assert.Panics(t, func() {
+		RunCompilerTest(`.a { background: asset-url("a", $size: 1); }`, compiler.NewCompactStyleCompiler())
+	})
+}
+
+func TestRegisterFunctionDefaultsAndRest(t *testing.T) {
+	RegisterFunction("join-all($first, $second: $first * 2, $rest...)", joinValues)
+	defer UnregisterFunction("join-all")
+
+	var out = RunCompilerTest(`.a { a: join-all(1px); b: join-all(1px, 3px, 4px, 5px); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: 1px|2px|; b: 1px|3px|4px, 5px; }\n", out)
+}
+
+func TestRegisterFunctionPrecedence(t *testing.T) {
+	RegisterFunction("shade($color)", func(args []ast.Value) (ast.Value, error) {
+		return &ast.String{Value: "registered"}, nil
+	})
+	defer UnregisterFunction("shade")
+
+	// the registered function is not rendered as the plain CSS function
+	var out = RunCompilerTest(`.a { color: shade(red); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: registered; }\n", out)
+
+	// the user-defined function takes precedence
+	out = RunCompilerTest(`@function shade($c) { @return defined; } .a { color: shade(red); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: defined; }\n", out)
+
+	// the function of the compilation takes precedence
+	result, err := Compile([]byte(`.a { color: shade(red); }`), Options{Style: compiler.CompactStyle, Functions: map[string]CustomFunction{
+		"shade($color, $amount: 10%)": joinValues,
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, ".a { color: red|10%; }\n", result.CSS)
+}
+
+func TestRegisterFunctionInvalidSignature(t *testing.T) {
+	assert.Panics(t, func() { RegisterFunction("broken(", joinValues) })
+	assert.Panics(t, func() { RegisterFunction("", joinValues) })
+	assert.Panics(t, func() { RegisterFunction("a b", joinValues) })
+
+	_, err := Compile([]byte(`.a { }`), Options{Functions: map[string]CustomFunction{"(": joinValues}})
+	assert.Equal(t, "Invalid function signature: (", err.Error())
+}
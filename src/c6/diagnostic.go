@@ -0,0 +1,49 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "c6/ast"
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(severity))
+}
+
+/*
+Diagnostic is the problem found in the source code. The parser collects the
+diagnostics and continues with the next statement, so the editors and the CI
+tools get all the problems of the file at once.
+*/
+type Diagnostic struct {
+	Severity Severity
+	File     string
+	Range    ast.Range
+	Message  string
+}
+
+func NewErrorDiagnostic(err *CompileError) Diagnostic {
+	return Diagnostic{Severity: SeverityError, File: err.File, Range: err.Range, Message: err.Message}
+}
+
+func (diag Diagnostic) String() string {
+	var file = diag.File
+	if file == "" {
+		file = "{anonymous}"
+	}
+	return fmt.Sprintf("%s:%s: %s: %s", file, diag.Range.Start, diag.Severity, diag.Message)
+}
@@ -0,0 +1,63 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func parseWithDiagnostics(code string) (string, []Diagnostic) {
+	var parser = NewParser(NewContext())
+	block, _ := parser.ParseScss(code)
+	var css = compiler.NewCompactStyleCompiler().CompileBlock(NewContext().Evaluate(block))
+	return css, parser.Diagnostics
+}
+
+func TestParserRecoversAtSemicolon(t *testing.T) {
+	css, diags := parseWithDiagnostics(".a {\n  color: red;\n  @include ;\n  width: 1px;\n  @include foo(1px;\n  height: 2px;\n}")
+	assert.Equal(t, ".a { color: red; width: 1px; height: 2px; }\n", css)
+	if assert.Equal(t, 2, len(diags)) {
+		assert.Equal(t, SeverityError, diags[0].Severity)
+		assert.Equal(t, "Expecting mixin name, got ';'", diags[0].Message)
+		assert.Equal(t, 3, diags[0].Range.Start.Line)
+		assert.Equal(t, 12, diags[0].Range.Start.Column)
+
+		assert.Equal(t, 5, diags[1].Range.Start.Line)
+		assert.Equal(t, 19, diags[1].Range.Start.Column)
+		assert.Equal(t, 20, diags[1].Range.End.Column)
+	}
+}
+
+func TestParserRecoversAtBraceEnd(t *testing.T) {
+	css, diags := parseWithDiagnostics(".a { b: c; .x { color: red; @mixin (; } top: 1px; }\n}\n.c$ { x: 1 }\n.d { top: 0; }")
+	assert.Equal(t, ".a { b: c; top: 1px; }\n.a .x { color: red; }\n\n.d { top: 0; }\n", css)
+	if assert.Equal(t, 3, len(diags)) {
+		assert.Equal(t, "Expecting mixin name, got '('", diags[0].Message)
+		assert.Equal(t, "Unexpected token '}'", diags[1].Message)
+		assert.Equal(t, 3, diags[2].Range.Start.Line)
+	}
+}
+
+func TestParserRecoversInMediaBlock(t *testing.T) {
+	css, diags := parseWithDiagnostics("@media screen { .m { @include foo(; } .n { a: b; } }")
+	assert.Equal(t, "@media screen { .n { a: b; } }\n", css)
+	assert.Equal(t, 1, len(diags))
+}
+
+func TestParserDiagnosticsOfUnterminatedInput(t *testing.T) {
+	for _, code := range []string{"#{", ".a { #{ }", ".a { b: c", "/-", "url(", ".a { content: \"x }"} {
+		var parser = NewParser(NewContext())
+		block, err := parser.ParseScss(code)
+		assert.NotNil(t, block)
+		assert.NotNil(t, err, code)
+		assert.Equal(t, 1, len(parser.Diagnostics), code)
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	var parser = NewParser(NewContext())
+	parser.File = "foo.scss"
+	parser.ParseScss(".a { @include ; }")
+	if assert.Equal(t, 1, len(parser.Diagnostics)) {
+		assert.Equal(t, "foo.scss", parser.Diagnostics[0].File)
+		assert.Equal(t, "foo.scss:1:15: error: Expecting mixin name, got ';'", parser.Diagnostics[0].String())
+	}
+}
@@ -0,0 +1,192 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "runtime"
+import "strings"
+import "unicode/utf8"
+import "c6/ast"
+
+/*
+CompileError is the error of the source code with its position, the excerpt
+is the source line with a caret under the error column:
+
+	foo.scss:3:14: Expecting ';', but the actual token we got was '}'.
+	  color: red }
+	             ^
+*/
+type CompileError struct {
+	Message string
+	File    string
+
+	// The line and the column start from 1, the column counts the runes
+	Line   int
+	Column int
+
+	// The range of the offending token, it ends at the start without the token
+	Range ast.Range
+
+	// The offending token, it's nil for the errors of the lexer
+	Token *ast.Token
+
+	Excerpt string
+}
+
+/*
+NewCompileError creates the error at the byte offset of the source code.
+*/
+func NewCompileError(message string, file string, source string, offset int, token *ast.Token) *CompileError {
+	var start = sourcePosition(source, offset)
+	offset = start.Offset
+	var end = start
+	if token != nil {
+		end = sourcePosition(source, offset+len(token.Str))
+	}
+
+	return &CompileError{
+		Message: message,
+		File:    file,
+		Line:    start.Line,
+		Column:  start.Column,
+		Range:   ast.Range{File: file, Start: start, End: end},
+		Token:   token,
+		Excerpt: sourceExcerpt(source, start.Line, start.Column),
+	}
+}
+
+/*
+NewRangeCompileError creates the error at the source range, like the range of
+the token evaluated in an imported file. The source is the code of the file
+for the excerpt, the excerpt is empty if the source is unavailable.
+*/
+func NewRangeCompileError(message string, r ast.Range, source string, token *ast.Token) *CompileError {
+	return &CompileError{
+		Message: message,
+		File:    r.File,
+		Line:    r.Start.Line,
+		Column:  r.Start.Column,
+		Range:   r,
+		Token:   token,
+		Excerpt: sourceExcerpt(source, r.Start.Line, r.Start.Column),
+	}
+}
+
+/*
+sourceExcerpt returns the line of the source code with the caret under the
+column, the line and the column start from 1.
+*/
+func sourceExcerpt(source string, line int, column int) string {
+	var lines = strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	var text = strings.TrimRight(lines[line-1], "\r")
+
+	// the tabs are kept in front of the caret, so it's aligned with the line
+	var indent = []rune{}
+	for i, r := range []rune(text) {
+		if i >= column-1 {
+			break
+		}
+		if r != '\t' {
+			r = ' '
+		}
+		indent = append(indent, r)
+	}
+	for len(indent) < column-1 {
+		indent = append(indent, ' ')
+	}
+	return text + "\n" + string(indent) + "^"
+}
+
+/*
+sourceError is the error of the evaluator at the token or the node, it's
+converted to the CompileError with the excerpt of the source code when the
+compilation fails.
+*/
+type sourceError struct {
+	Message string
+	Range   ast.Range
+	Token   *ast.Token
+}
+
+func (err *sourceError) Error() string {
+	return err.Message
+}
+
+// tokenErrorf creates the error at the token
+func tokenErrorf(token *ast.Token, format string, args ...interface{}) *sourceError {
+	return &sourceError{fmt.Sprintf(format, args...), ast.TokenRange(token), token}
+}
+
+// nodeErrorf creates the error at the source range of the node
+func nodeErrorf(node ast.Node, format string, args ...interface{}) *sourceError {
+	return &sourceError{fmt.Sprintf(format, args...), ast.NodeRange(node), nil}
+}
+
+/*
+recoverAt raises the recovered error again at the source range of the node,
+it's deferred by the evaluation of the statements and the expressions, so the
+error is reported at the innermost node with the range.
+*/
+func recoverAt(node ast.Node) {
+	if r := recover(); r != nil {
+		panic(positionedError(r, ast.NodeRange(node)))
+	}
+}
+
+/*
+positionedError returns the error at the range, the errors that already have
+the position and the runtime errors are returned as they are.
+*/
+func positionedError(r interface{}, rng ast.Range) interface{} {
+	var err, ok = r.(error)
+	if !ok || rng.Start.Line == 0 {
+		return r
+	}
+	switch err.(type) {
+	case *sourceError, *CompileError, runtime.Error:
+		return r
+	}
+	return &sourceError{err.Error(), rng, nil}
+}
+
+// sourcePosition returns the position of the byte offset in the source code
+func sourcePosition(source string, offset int) ast.Position {
+	if offset > len(source) {
+		offset = len(source)
+	} else if offset < 0 {
+		offset = 0
+	}
+	var lineStart = strings.LastIndex(source[:offset], "\n") + 1
+	return ast.Position{
+		Offset: offset,
+		Line:   strings.Count(source[:offset], "\n") + 1,
+		Column: utf8.RuneCountInString(source[lineStart:offset]) + 1,
+	}
+}
+
+func (err *CompileError) Error() string {
+	var file = err.File
+	if file == "" {
+		file = "{anonymous}"
+	}
+	if err.Excerpt == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", file, err.Line, err.Column, err.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s\n%s", file, err.Line, err.Column, err.Message, err.Excerpt)
+}
+
+// the message of the recovered panic
+func panicMessage(r interface{}) string {
+	switch t := r.(type) {
+	case error:
+		return t.Error()
+	case string:
+		return t
+	}
+	return fmt.Sprintf("%v", r)
+}
@@ -0,0 +1,160 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+import "runtime"
+import "strings"
+import "time"
+
+func parseError(t *testing.T, code string) *CompileError {
+	var parser = NewParser(NewContext())
+	block, err := parser.ParseScss(code)
+	// the partial block is returned with the error
+	assert.NotNil(t, block)
+	if !assert.NotNil(t, err) {
+		return nil
+	}
+	compileErr, ok := err.(*CompileError)
+	assert.True(t, ok)
+	return compileErr
+}
+
+func TestCompileErrorOfLexer(t *testing.T) {
+	var err = parseError(t, ".a {\n  width: 1px;\n  @include ;\n}")
+	assert.Equal(t, 3, err.Line)
+	assert.Equal(t, 12, err.Column)
+	assert.Nil(t, err.Token)
+	assert.Equal(t, "{anonymous}:3:12: Expecting mixin name, got ';'\n  @include ;\n           ^", err.Error())
+}
+
+func TestCompileErrorOfFunctionName(t *testing.T) {
+	var err = parseError(t, "@function ($a) { @return $a; }")
+	assert.Equal(t, "Expecting function name, got '('", err.Message)
+	assert.Equal(t, 11, err.Column)
+}
+
+func TestCompileErrorOfRuleUrl(t *testing.T) {
+	var err = parseError(t, "@use ;")
+	assert.Equal(t, "Unexpected token for @use rule. Got ;", err.Message)
+
+	err = parseError(t, "@forward 1;")
+	assert.Equal(t, "Unexpected token for @forward rule. Got 1", err.Message)
+
+	err = parseError(t, "@import ;")
+	assert.Equal(t, "Unexpected token for @import rule. Got ;", err.Message)
+}
+
+func TestCompileErrorOfParser(t *testing.T) {
+	var err = parseError(t, ".a {\n  @include foo(1px;\n}")
+	assert.Equal(t, 2, err.Line)
+	assert.Equal(t, 19, err.Column)
+	assert.NotNil(t, err.Token)
+	assert.Equal(t, ";", err.Token.Str)
+	assert.Equal(t, "  @include foo(1px;\n                  ^", err.Excerpt)
+}
+
+func TestCompileErrorOfUnexpectedToken(t *testing.T) {
+	var err = parseError(t, ".a {\n  @return ;\n}")
+	assert.Equal(t, "{anonymous}:2:11: Expecting value after @return. Got ';'\n  @return ;\n          ^", err.Error())
+
+	err = parseError(t, "@if {\n}")
+	assert.Equal(t, "{anonymous}:1:5: Expecting condition, got '{'\n@if {\n    ^", err.Error())
+}
+
+func TestCompileErrorColumnCountsRunes(t *testing.T) {
+	var err = parseError(t, ".é { content: \"ü\"; @include ; }")
+	assert.Equal(t, 1, err.Line)
+	assert.Equal(t, 29, err.Column)
+}
+
+func TestCompileErrorUnexpectedEndOfFile(t *testing.T) {
+	var err = parseError(t, ".a { width: 1px")
+	assert.Equal(t, 1, err.Line)
+	assert.Equal(t, 16, err.Column)
+
+	err = parseError(t, "/* comment")
+	assert.Equal(t, "Expecting comment end mark '*/', got end of file.", err.Message)
+}
+
+func TestCompileErrorUnexpectedEndOfRule(t *testing.T) {
+	for _, code := range []string{".a", "@mixin m(", "@each $k", "@media screen", ".a { @extend .b", "@for $i"} {
+		var err = parseError(t, code)
+		assert.Equal(t, "Unexpected end of file", err.Message, code)
+		assert.Equal(t, len(code)+1, err.Column, code)
+		assert.Nil(t, err.Token, code)
+	}
+}
+
+func TestCompileErrorOfEmptyPropertyValue(t *testing.T) {
+	var err = parseError(t, ".b {\n  width: ;\n}")
+	assert.Equal(t, "{anonymous}:2:10: Expecting value for the property width. Got ';'\n  width: ;\n         ^", err.Error())
+
+	err = parseError(t, ".b { width: }")
+	assert.Equal(t, 1, err.Line)
+	assert.Equal(t, 13, err.Column)
+	assert.Equal(t, "Expecting value for the property width. Got '}'", err.Message)
+}
+
+func TestCompileErrorOfParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "broken.scss")
+	writeTestFiles(t, dir, map[string]string{"broken.scss": "// comment\n.a { @include ; }"})
+
+	_, err = NewParser(NewContext()).ParseFile(path)
+	compileErr, ok := err.(*CompileError)
+	if assert.True(t, ok) {
+		assert.Equal(t, path, compileErr.File)
+		assert.Equal(t, 2, compileErr.Line)
+		assert.True(t, strings.HasPrefix(err.Error(), path+":2:15: "))
+	}
+}
+
+func TestCompileErrorDoesNotLeakLexer(t *testing.T) {
+	// the input is larger than the token channel buffer
+	var code = strings.Repeat(".a { width: 1px; }\n", 1000) + "@include ;\n" + strings.Repeat(".b { width: 1px; }\n", 1000)
+	var before = runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		parseError(t, code)
+		parseError(t, "@include foo(1px;\n"+code)
+	}
+	// the lexer goroutines are finishing after the parser returns
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= before)
+}
+
+func TestCompileErrorOfExtendInMedia(t *testing.T) {
+	_, err := Compile([]byte(".a { color: red; }\n@media print { .b { @extend .a; } }"), Options{Filename: "foo.scss"})
+	compileErr, ok := err.(*CompileError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "foo.scss", compileErr.File)
+		assert.Equal(t, 2, compileErr.Line)
+		assert.Equal(t, 21, compileErr.Column)
+		assert.Equal(t, "@media print { .b { @extend .a; } }\n                    ^", compileErr.Excerpt)
+	}
+
+	// the error in the imported file has the position in that file
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss":   `@import "print"; .a { color: red; }`,
+		"_print.scss": `@media print { .b { @extend .a; } }`,
+	})
+	_, err = CompileFile(filepath.Join(dir, "main.scss"), Options{})
+	compileErr, ok = err.(*CompileError)
+	if assert.True(t, ok) {
+		assert.Equal(t, filepath.Join(dir, "_print.scss"), compileErr.File)
+		assert.Equal(t, 1, compileErr.Line)
+		assert.Equal(t, "@media print { .b { @extend .a; } }\n                    ^", compileErr.Excerpt)
+	}
+}
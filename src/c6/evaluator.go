@@ -0,0 +1,453 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "c6/ast"
+
+/*
+Evaluate runs the parsed block with the context, and returns a new block that
+only contains the plain CSS statements: the variables are substituted, the
+expressions are computed and the mixins are expanded.
+
+The parsed block is not modified, since the parsed files are cached and
+could be evaluated again with another context.
+*/
+func (context *Context) Evaluate(block *ast.Block) *ast.Block {
+	var out = &ast.Block{}
+	for _, stm := range block.Statements {
+		context.EvaluateStatement(stm, out)
+	}
+	ApplyExtends(out)
+	return out
+}
+
+func (context *Context) EvaluateStatement(stm ast.Statement, out *ast.Block) {
+	defer recoverAt(stm)
+
+	switch t := stm.(type) {
+	case *ast.VariableAssignment:
+		context.AssignVariable(t)
+	case *ast.MixinStatement:
+		context.DefineMixin(t)
+	case *ast.FunctionStatement:
+		context.DefineFunction(t)
+	case *ast.IncludeStatement:
+		var declBlock = &ast.DeclarationBlock{}
+		context.IncludeMixin(t, declBlock)
+		appendTopLevelDeclarations(stm, declBlock, out)
+	case *ast.IfStatement, *ast.EachStatement, *ast.ForStatement, *ast.WhileStatement:
+		var declBlock = &ast.DeclarationBlock{}
+		context.ExecuteControlStatement(stm, func(block *ast.DeclarationBlock) bool {
+			context.EvaluateDeclarations(block, declBlock)
+			return false
+		})
+		appendTopLevelDeclarations(stm, declBlock, out)
+	case *ast.RuleSet:
+		out.AppendStatement(context.EvaluateRuleSet(t))
+	case *ast.MediaStatement:
+		out.AppendStatement(context.EvaluateMediaStatement(t))
+	case *ast.ImportStatement:
+		if IsPlainCssImport(t) {
+			out.AppendStatement(t)
+		} else {
+			context.ImportFile(t, out)
+		}
+	case *ast.ImportList:
+		for _, stm := range t.Imports {
+			context.EvaluateStatement(stm, out)
+		}
+	case *ast.UseStatement:
+		context.UseModule(t, out)
+	case *ast.ForwardStatement:
+		context.ForwardModule(t, out)
+	case *ast.WarnStatement:
+		context.EvaluateWarn(t)
+	default:
+		out.AppendStatement(stm)
+	}
+}
+
+/*
+appendTopLevelDeclarations appends the rulesets generated by the statement
+to the top level, properties are not allowed at the top level.
+*/
+func appendTopLevelDeclarations(stm ast.Statement, declBlock *ast.DeclarationBlock, out *ast.Block) {
+	if len(declBlock.Declarations) > 0 {
+		panic(fmt.Errorf("Declarations may only be used within rulesets: %s", stm))
+	}
+	for _, ruleset := range declBlock.SubRuleSets {
+		out.AppendStatement(ruleset)
+	}
+}
+
+/*
+EvaluateRuleSet resolves the selectors with the enclosing ruleset, and
+evaluates the declaration block with the ruleset on the stack so the nested
+rulesets are resolved with it.
+*/
+func (context *Context) EvaluateRuleSet(ruleset *ast.RuleSet) *ast.RuleSet {
+	var result = ast.NewRuleSet()
+	result.SetRange(ruleset.GetRange())
+	var parents []ast.Selector
+	if parent := context.TopRuleSet(); parent != nil {
+		parents = parent.Selectors
+	}
+	result.Selectors = ResolveSelectors(ruleset.Selectors, parents)
+	if ruleset.DeclarationBlock != nil {
+		context.PushRuleSet(result)
+		result.DeclarationBlock = context.EvaluateDeclarationBlock(ruleset.DeclarationBlock)
+		context.PopRuleSet()
+	}
+	return result
+}
+
+/*
+EvaluateMediaStatement evaluates the statements of the @media block in a new
+scope.
+*/
+func (context *Context) EvaluateMediaStatement(media *ast.MediaStatement) *ast.MediaStatement {
+	var result = ast.NewMediaStatement(media.Query, media.Token)
+	context.PushScope(false)
+	for _, stm := range media.Block.Statements {
+		context.EvaluateStatement(stm, result.Block)
+	}
+	context.PopScope()
+	return result
+}
+
+/*
+EvaluateDeclarationBlock evaluates the declaration block in a new scope.
+*/
+func (context *Context) EvaluateDeclarationBlock(block *ast.DeclarationBlock) *ast.DeclarationBlock {
+	var out = &ast.DeclarationBlock{}
+	context.PushScope(false)
+	context.EvaluateDeclarations(block, out)
+	context.PopScope()
+	return out
+}
+
+/*
+EvaluateDeclarations evaluates the declarations into the output block in the
+current scope.
+*/
+func (context *Context) EvaluateDeclarations(block *ast.DeclarationBlock, out *ast.DeclarationBlock) {
+	for _, decl := range block.Declarations {
+		context.evaluateDeclaration(decl, out)
+	}
+}
+
+func (context *Context) evaluateDeclaration(decl ast.Declaration, out *ast.DeclarationBlock) {
+	defer recoverAt(decl)
+
+	switch t := decl.(type) {
+	case *ast.Property:
+		out.Append(context.EvaluateProperty(t))
+	case *ast.RuleSet:
+		out.AppendSubRuleSet(context.EvaluateRuleSet(t))
+	case *ast.VariableAssignment:
+		context.AssignVariable(t)
+	case *ast.MixinStatement:
+		context.DefineMixin(t)
+	case *ast.FunctionStatement:
+		context.DefineFunction(t)
+	case *ast.IncludeStatement:
+		context.IncludeMixin(t, out)
+	case *ast.ContentStatement:
+		context.IncludeContent(t, out)
+	case *ast.ExtendStatement:
+		// the extends are applied after the evaluation
+		out.Append(t)
+	case *ast.WarnStatement:
+		context.EvaluateWarn(t)
+	case *ast.IfStatement, *ast.EachStatement, *ast.ForStatement, *ast.WhileStatement:
+		context.ExecuteControlStatement(decl.(ast.Statement), func(block *ast.DeclarationBlock) bool {
+			context.EvaluateDeclarations(block, out)
+			return false
+		})
+	default:
+		panic(nodeErrorf(decl, "Unsupported declaration"))
+	}
+}
+
+func (context *Context) EvaluateProperty(property *ast.Property) *ast.Property {
+	var result = &ast.Property{Name: property.Name, Values: []ast.Expression{}}
+	result.SetRange(property.GetRange())
+	for _, val := range property.Values {
+		var value = context.EvaluateExpression(val)
+		checkCSSValue(value)
+		result.AppendValue(value)
+	}
+	return result
+}
+
+/*
+checkCSSValue panics if the value can't be written in CSS, like the length of
+the compound units `10px*px`, or the empty list of the rest argument.
+*/
+func checkCSSValue(val ast.Expression) {
+	switch t := val.(type) {
+	case *ast.Length:
+		if t.IsCompound() {
+			panic(fmt.Errorf("%s isn't a valid CSS value", t))
+		}
+	case *ast.List:
+		if len(t.Expressions) == 0 {
+			panic(fmt.Errorf("() isn't a valid CSS value"))
+		}
+		for _, item := range t.Expressions {
+			checkCSSValue(item)
+		}
+	case *ast.FunctionCall:
+		for _, arg := range t.Arguments {
+			checkCSSValue(arg)
+		}
+	}
+}
+
+// EvaluateWarn reports the evaluated message of `@warn` as the warning
+func (context *Context) EvaluateWarn(stm *ast.WarnStatement) {
+	var message = context.EvaluateExpression(stm.Value)
+	if str, ok := message.(*ast.String); ok {
+		context.Warn(str.Value, stm.Token)
+	} else {
+		context.Warn(message.String(), stm.Token)
+	}
+}
+
+/*
+AssignVariable evaluates the assignment, `!default` skips the assignment if
+the variable is defined and not null, `!global` assigns the global variable.
+
+The `!default` variables at the top level of the module are overridden by the
+configuration of `@use ... with (...)`.
+*/
+func (context *Context) AssignVariable(assignment *ast.VariableAssignment) {
+	var variable = assignment.Variable
+	if assignment.Default {
+		if value, ok := context.configuredValue(variable.Name); ok {
+			context.SetGlobalVariable(&ast.Variable{Name: variable.Name, Value: value, Token: variable.Token})
+			return
+		}
+
+		var current *ast.Variable
+		if assignment.Global {
+			current = context.GlobalSymTable.FindVariable(variable.Name)
+		} else {
+			current = context.GetVariable(variable.Name)
+		}
+		if current != nil && !IsNull(current.Value) {
+			return
+		}
+	}
+
+	var value = context.EvaluateDividedExpression(assignment.Expression)
+	var result = &ast.Variable{Name: variable.Name, Value: value, Token: variable.Token}
+	if assignment.Global {
+		context.SetGlobalVariable(result)
+	} else {
+		context.SetVariable(result)
+	}
+}
+
+/*
+EvaluateExpression substitutes the variables of the expression and computes
+the result. The expressions that are not values, like the plain CSS function
+calls, are returned with the evaluated operands, and the invalid operations,
+like `1px + #fff`, panic with the ast.OperationError.
+*/
+func (context *Context) EvaluateExpression(expr ast.Expression) ast.Expression {
+	switch t := expr.(type) {
+	case *ast.Variable:
+		var variable = context.GetVariable(t.Name)
+		if variable == nil {
+			panic(tokenErrorf(t.Token, "Undefined variable %s", t.Name))
+		}
+		return variable.Value
+
+	case *ast.List:
+		var list = &ast.List{Separator: t.Separator, Expressions: []ast.Expression{}}
+		for _, item := range t.Expressions {
+			list.Append(context.EvaluateExpression(item))
+		}
+		return list
+
+	case *ast.BinaryExpression:
+		// `and` and `or` return the operand that decides the result, the
+		// right operand is only evaluated if it's needed.
+		if t.Op == ast.OpAnd || t.Op == ast.OpOr {
+			var left = context.EvaluateExpression(t.Left)
+			if IsTrue(left) == (t.Op == ast.OpOr) {
+				return left
+			}
+			return context.EvaluateExpression(t.Right)
+		}
+
+		// the slash between the literal numbers is a separator in plain CSS,
+		// e.g. `font: 12px/1.5`, it's evaluated as the slash-separated list.
+		if t.Op == ast.OpDiv && !t.ShallDivide() {
+			var left = context.EvaluateExpression(t.Left)
+			if list, ok := left.(*ast.List); ok && list.IsSlashSeparated() {
+				// 12px/2/3
+				list.Append(context.EvaluateExpression(t.Right))
+				return list
+			}
+			return ast.NewSlashList(left, context.EvaluateExpression(t.Right))
+		}
+		return context.computeBinaryExpression(t)
+
+	case *ast.UnaryExpression:
+		var val = context.EvaluateExpression(t.Expr)
+		if t.Op == ast.OpNot {
+			return ast.NewBoolean(!IsTrue(val), nil)
+		}
+		if _, ok := val.(ast.ComputableValue); ok {
+			result, err := ast.ComputeUnary(t.Op, val)
+			if err != nil {
+				panic(err)
+			}
+			return result
+		}
+		return ast.NewUnaryExpression(t.Op, val)
+
+	case ast.FunctionCall:
+		return context.EvaluateFunctionCall(&t)
+
+	case *ast.FunctionCall:
+		return context.EvaluateFunctionCall(t)
+
+	case *ast.Interpolation:
+		// the interpolated strings are unquoted
+		var val = context.EvaluateExpression(t.Expression)
+		if str, ok := val.(*ast.String); ok && str.Quote != 0 {
+			return &ast.String{Value: str.Value, Token: str.Token}
+		}
+		return val
+
+	case *ast.LiteralConcat:
+		return ast.NewLiteralConcat(context.EvaluateExpression(t.Left), context.EvaluateExpression(t.Right))
+
+	case *ast.Map:
+		var m = ast.NewMap()
+		for i, key := range t.Keys {
+			m.Set(context.EvaluateExpression(key), context.EvaluateExpression(t.Values[i]))
+		}
+		return m
+
+	case *ast.KeywordArgument:
+		return ast.NewKeywordArgument(t.Name, context.EvaluateExpression(t.Value))
+
+	case *ast.RestArgument:
+		return ast.NewRestArgument(context.EvaluateExpression(t.Value))
+	}
+	return expr
+}
+
+/*
+computeBinaryExpression applies the arithmetic or the comparison operator to
+the evaluated operands, the slash in the operand divides: `1 + 12px/2` is 7px.
+*/
+func (context *Context) computeBinaryExpression(expr *ast.BinaryExpression) ast.Expression {
+	defer recoverAt(expr)
+
+	var left = context.EvaluateDividedExpression(expr.Left)
+	var right = context.EvaluateDividedExpression(expr.Right)
+	if ast.IsComparisonOp(expr.Op) {
+		val, err := ast.Compare(expr.Op, left, right)
+		if err != nil {
+			panic(err)
+		}
+		return val
+	}
+	// the results of the plain CSS functions are unquoted strings
+	val, err := ast.Compute(expr.Op, plainFunctionString(left), plainFunctionString(right))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// colorFunctions are the CSS functions of the colors, they can't be operands
+var colorFunctions = map[string]bool{"rgb": true, "rgba": true, "hsl": true, "hsla": true}
+
+/*
+plainFunctionString converts the plain CSS function call to the unquoted
+string, so `foo() + 1` is `foo()1` like the string concatenation. The color
+functions are kept, their operations are undefined.
+*/
+func plainFunctionString(val ast.Expression) ast.Expression {
+	if fcall, ok := val.(*ast.FunctionCall); ok && !colorFunctions[fcall.Function] {
+		return &ast.String{Value: fcall.String(), Token: fcall.Token}
+	}
+	return val
+}
+
+/*
+EvaluateDividedExpression evaluates the expression that is stored in a
+variable, returned by a function, passed to a function or used as an operand,
+the slash between the literal numbers divides there: `$a: 12px/2` is 6px.
+*/
+func (context *Context) EvaluateDividedExpression(expr ast.Expression) ast.Expression {
+	if bexpr, ok := expr.(*ast.BinaryExpression); ok && bexpr.Op == ast.OpDiv && !bexpr.HasInterpolation() {
+		return context.computeBinaryExpression(bexpr)
+	}
+	return context.EvaluateExpression(expr)
+}
+
+/*
+EvaluateFunctionCall calls the user-defined function or the custom function,
+the unknown functions are rendered as plain CSS functions with the evaluated arguments.
+*/
+func (context *Context) EvaluateFunctionCall(fcall *ast.FunctionCall) ast.Expression {
+	defer recoverAt(fcall)
+
+	if fn := context.lookupBuiltinFunction(fcall.Function); fn != nil {
+		return context.callCustomFunction(fn, fcall.Arguments)
+	}
+	if fn := context.LookupFunction(fcall.Function); fn != nil {
+		return context.CallFunction(fn, fcall.Arguments)
+	}
+	if fn := context.lookupCustomFunction(fcall.Function); fn != nil {
+		return context.callCustomFunction(fn, fcall.Arguments)
+	}
+
+	var result = &ast.FunctionCall{Function: fcall.Function, Arguments: []ast.Expression{}, Token: fcall.Token}
+	for _, arg := range fcall.Arguments {
+		if calcFunctions[fcall.Function] {
+			result.AppendArgument(context.evaluateCalcExpression(arg))
+		} else {
+			result.AppendArgument(context.EvaluateExpression(arg))
+		}
+	}
+	return result
+}
+
+// calcFunctions are the CSS functions that compute the mixed units
+var calcFunctions = map[string]bool{"calc": true, "min": true, "max": true, "clamp": true}
+
+/*
+evaluateCalcExpression evaluates the argument of calc(), the operations of
+the incompatible units like `100% - 10px` are computed by the browser, so
+they're kept with the evaluated operands.
+*/
+func (context *Context) evaluateCalcExpression(expr ast.Expression) ast.Expression {
+	var bexpr, ok = expr.(*ast.BinaryExpression)
+	if !ok {
+		return context.EvaluateExpression(expr)
+	}
+	switch bexpr.Op {
+	case ast.OpAdd, ast.OpSub, ast.OpMul, ast.OpDiv:
+	default:
+		return context.EvaluateExpression(expr)
+	}
+	var left = context.evaluateCalcExpression(bexpr.Left)
+	var right = context.evaluateCalcExpression(bexpr.Right)
+	if ast.IsComputable(bexpr.Op, left, right) {
+		if val, err := ast.Compute(bexpr.Op, left, right); err == nil {
+			return val
+		}
+	}
+	return ast.NewBinaryExpression(bexpr.Op, left, right, bexpr.Grouped)
+}
@@ -0,0 +1,399 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "c6/ast"
+
+/*
+extension presents one target of an @extend directive with one selector of
+the ruleset that contains the directive.
+*/
+type extension struct {
+	Extender *ast.ComplexSelector
+	Target   *ast.ComplexSelector
+	Stm      *ast.ExtendStatement
+	// The @media block of the directive, nil for the top level.
+	Media *ast.MediaStatement
+	// The target is found in the stylesheet
+	Found bool
+}
+
+/*
+extendedRuleSet is the ruleset with the @media block it belongs to.
+*/
+type extendedRuleSet struct {
+	RuleSet *ast.RuleSet
+	Media   *ast.MediaStatement
+}
+
+/*
+ApplyExtends runs the extension pass on the evaluated block: the @extend
+directives are removed, the selectors that extend the target are added to
+the rulesets containing the target, and the selectors with placeholders are
+removed since they are never rendered.
+
+	.error { color: red; }
+	.serious-error { @extend .error; }
+
+The above code generates:
+
+	.error, .serious-error { color: red; }
+*/
+func ApplyExtends(block *ast.Block) {
+	var extensions = []*extension{}
+	var rulesets = []extendedRuleSet{}
+	collectExtends(block, nil, &extensions, &rulesets)
+
+	if len(extensions) > 0 {
+		for _, item := range rulesets {
+			item.RuleSet.Selectors = extendSelectorList(item.RuleSet.Selectors, item.Media, extensions)
+		}
+		for _, ext := range extensions {
+			if !ext.Found && !ext.Stm.Optional {
+				panic(tokenErrorf(ext.Stm.Token, "The target selector was not found.\nUse \"@extend %s !optional\" to avoid this error.", ext.Target))
+			}
+		}
+	}
+	removePlaceholders(block)
+}
+
+func collectExtends(block *ast.Block, media *ast.MediaStatement, extensions *[]*extension, rulesets *[]extendedRuleSet) {
+	for _, stm := range block.Statements {
+		switch t := stm.(type) {
+		case *ast.RuleSet:
+			collectRuleSetExtends(t, media, extensions, rulesets)
+		case *ast.MediaStatement:
+			collectExtends(t.Block, t, extensions, rulesets)
+		}
+	}
+}
+
+func collectRuleSetExtends(ruleset *ast.RuleSet, media *ast.MediaStatement, extensions *[]*extension, rulesets *[]extendedRuleSet) {
+	*rulesets = append(*rulesets, extendedRuleSet{ruleset, media})
+	if ruleset.DeclarationBlock == nil {
+		return
+	}
+
+	var declarations = []ast.Declaration{}
+	for _, decl := range ruleset.DeclarationBlock.Declarations {
+		stm, ok := decl.(*ast.ExtendStatement)
+		if !ok {
+			declarations = append(declarations, decl)
+			continue
+		}
+		for _, sel := range ruleset.Selectors {
+			extender, ok := sel.(*ast.ComplexSelector)
+			if !ok {
+				continue
+			}
+			for _, target := range stm.Selectors {
+				*extensions = append(*extensions, &extension{Extender: extender, Target: target, Stm: stm, Media: media})
+			}
+		}
+	}
+	ruleset.DeclarationBlock.Declarations = declarations
+
+	for _, sub := range ruleset.DeclarationBlock.SubRuleSets {
+		collectRuleSetExtends(sub, media, extensions, rulesets)
+	}
+}
+
+/*
+extendSelectorList appends the extended selectors to the selector list, the
+generated selectors are extended again so the extends are transitive.
+*/
+func extendSelectorList(selectors []ast.Selector, media *ast.MediaStatement, extensions []*extension) []ast.Selector {
+	var result = append([]ast.Selector{}, selectors...)
+	var seen = map[string]bool{}
+	for _, sel := range selectors {
+		seen[sel.String()] = true
+	}
+
+	// a chain of extends can't be longer than the number of extensions,
+	// this also stops the circular extends.
+	var queue = selectors
+	for round := 0; round < len(extensions) && len(queue) > 0; round++ {
+		var next = []ast.Selector{}
+		for _, sel := range queue {
+			complex, ok := sel.(*ast.ComplexSelector)
+			if !ok {
+				continue
+			}
+			for _, ext := range extensions {
+				extended, found := extendComplexSelector(complex, ext)
+				if !found {
+					continue
+				}
+				if ext.Media != nil && ext.Media != media {
+					panic(tokenErrorf(ext.Stm.Token, "You may not @extend an outer selector from within @media.\nYou may only @extend selectors within the same directive.\nFrom \"%s\".", ext.Stm))
+				}
+				ext.Found = true
+				for _, newSel := range extended {
+					if !seen[newSel.String()] {
+						seen[newSel.String()] = true
+						result = append(result, newSel)
+						next = append(next, newSel)
+					}
+				}
+			}
+		}
+		queue = next
+	}
+	return result
+}
+
+/*
+splitCompounds splits the complex selector into the compound selectors, the
+combinators[i] is the combinator before compounds[i], nil for the first one.
+*/
+func splitCompounds(complex *ast.ComplexSelector) (compounds [][]ast.Selector, combinators []ast.Selector) {
+	var compound = []ast.Selector{}
+	var combinator ast.Selector
+	for _, sel := range complex.Selectors {
+		if isCombinator(sel) {
+			compounds = append(compounds, compound)
+			combinators = append(combinators, combinator)
+			compound = []ast.Selector{}
+			combinator = sel
+			continue
+		}
+		compound = append(compound, sel)
+	}
+	compounds = append(compounds, compound)
+	combinators = append(combinators, combinator)
+	return compounds, combinators
+}
+
+func isCombinator(sel ast.Selector) bool {
+	switch sel.(type) {
+	case ast.DescendantSelector, ast.ChildSelector, ast.AdjacentSelector:
+		return true
+	}
+	return false
+}
+
+/*
+joinCompounds flattens the compound selectors back into a sequence, the
+combinator after the last compound is appended if there is one.
+*/
+func joinCompounds(compounds [][]ast.Selector, combinators []ast.Selector, trailing ast.Selector) []ast.Selector {
+	var out = []ast.Selector{}
+	for i, compound := range compounds {
+		if i > 0 && combinators[i] != nil {
+			out = append(out, combinators[i])
+		}
+		out = append(out, compound...)
+	}
+	if len(out) > 0 && trailing != nil {
+		out = append(out, trailing)
+	}
+	return out
+}
+
+func containsSelector(selectors []ast.Selector, sel ast.Selector) bool {
+	for _, s := range selectors {
+		if s.String() == sel.String() {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+extendComplexSelector replaces the target in each compound of the selector
+with the extender, found is false if no compound contains the target.
+*/
+func extendComplexSelector(complex *ast.ComplexSelector, ext *extension) (extended []*ast.ComplexSelector, found bool) {
+	var compounds, combinators = splitCompounds(complex)
+	var extCompounds, extCombinators = splitCompounds(ext.Extender)
+	var last = len(extCompounds) - 1
+
+	for i, compound := range compounds {
+		var matched = true
+		for _, sel := range ext.Target.Selectors {
+			if !containsSelector(compound, sel) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		found = true
+
+		var rest = []ast.Selector{}
+		for _, sel := range compound {
+			if !containsSelector(ext.Target.Selectors, sel) {
+				rest = append(rest, sel)
+			}
+		}
+		var unified = unifyCompound(extCompounds[last], rest)
+		if unified == nil {
+			continue
+		}
+
+		var prefix = joinCompounds(compounds[:i], combinators[:i], combinators[i])
+		var extPrefix = joinCompounds(extCompounds[:last], extCombinators[:last], extCombinators[last])
+		var suffix = joinCompounds(compounds[i+1:], combinators[i+1:], nil)
+		if i+1 < len(compounds) {
+			suffix = append([]ast.Selector{combinators[i+1]}, suffix...)
+		}
+
+		for _, woven := range weavePrefixes(prefix, extPrefix) {
+			var sel = ast.NewComplexSelector()
+			sel.Selectors = append(append(append(sel.Selectors, woven...), unified...), suffix...)
+			extended = append(extended, sel)
+		}
+	}
+	return extended, found
+}
+
+/*
+unifyCompound merges the compound selector of the extender with the rest of
+the target compound, it returns nil when they can't match the same element,
+e.g. `a` and `span`, or `#foo` and `#bar`.
+
+The type selector is placed first and the pseudo selectors are placed last:
+`a:hover` extended by `.foo` generates `a.foo:hover`.
+*/
+func unifyCompound(extender []ast.Selector, rest []ast.Selector) []ast.Selector {
+	var typeSel ast.Selector
+	var id = ""
+	var simples = []ast.Selector{}
+	var pseudos = []ast.Selector{}
+
+	for _, sel := range append(append([]ast.Selector{}, extender...), rest...) {
+		switch t := sel.(type) {
+		case ast.UniversalSelector:
+			if typeSel == nil {
+				typeSel = t
+			}
+		case ast.TypeSelector:
+			if typeSel == nil {
+				typeSel = t
+			} else if _, ok := typeSel.(ast.UniversalSelector); ok {
+				typeSel = t
+			} else if typeSel.String() != t.String() {
+				return nil
+			}
+		case ast.IdSelector:
+			if id != "" && id != t.Id {
+				return nil
+			}
+			id = t.Id
+			if !containsSelector(simples, t) {
+				simples = append(simples, t)
+			}
+		case ast.PseudoSelector:
+			if !containsSelector(pseudos, t) {
+				pseudos = append(pseudos, t)
+			}
+		default:
+			if !containsSelector(simples, t) {
+				simples = append(simples, t)
+			}
+		}
+	}
+
+	var out = []ast.Selector{}
+	if typeSel != nil {
+		// the universal selector is useless with the other selectors
+		if _, ok := typeSel.(ast.UniversalSelector); !ok || len(simples)+len(pseudos) == 0 {
+			out = append(out, typeSel)
+		}
+	}
+	out = append(out, simples...)
+	return append(out, pseudos...)
+}
+
+/*
+weavePrefixes returns the possible orders of the parent selectors of the
+target and the extender:
+
+	.a .b { }
+	.x .y { @extend .b; }
+
+generates `.a .x .y` and `.x .a .y`. The prefix followed by a child or an
+adjacent combinator must be placed right before the unified compound.
+*/
+func weavePrefixes(prefix []ast.Selector, extPrefix []ast.Selector) [][]ast.Selector {
+	if len(extPrefix) == 0 {
+		return [][]ast.Selector{prefix}
+	}
+	if len(prefix) == 0 {
+		return [][]ast.Selector{extPrefix}
+	}
+	var join = func(a, b []ast.Selector) []ast.Selector {
+		return append(append([]ast.Selector{}, a...), b...)
+	}
+	if (&ast.ComplexSelector{Selectors: prefix}).String() == (&ast.ComplexSelector{Selectors: extPrefix}).String() {
+		return [][]ast.Selector{prefix}
+	}
+
+	_, isDescendant := prefix[len(prefix)-1].(ast.DescendantSelector)
+	_, extIsDescendant := extPrefix[len(extPrefix)-1].(ast.DescendantSelector)
+	if isDescendant && extIsDescendant {
+		return [][]ast.Selector{join(prefix, extPrefix), join(extPrefix, prefix)}
+	} else if !isDescendant && extIsDescendant {
+		return [][]ast.Selector{join(extPrefix, prefix)}
+	}
+	return [][]ast.Selector{join(prefix, extPrefix)}
+}
+
+func hasPlaceholder(sel ast.Selector) bool {
+	if complex, ok := sel.(*ast.ComplexSelector); ok {
+		for _, sub := range complex.Selectors {
+			if _, ok := sub.(ast.PlaceholderSelector); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+removePlaceholders removes the selectors with placeholders, and the rulesets
+that have no selector left.
+*/
+func removePlaceholders(block *ast.Block) {
+	var statements = []ast.Statement{}
+	for _, stm := range block.Statements {
+		switch t := stm.(type) {
+		case *ast.RuleSet:
+			if !removeRuleSetPlaceholders(t) {
+				continue
+			}
+		case *ast.MediaStatement:
+			removePlaceholders(t.Block)
+		}
+		statements = append(statements, stm)
+	}
+	block.SetStatements(statements)
+}
+
+// returns false if the ruleset should be removed
+func removeRuleSetPlaceholders(ruleset *ast.RuleSet) bool {
+	var selectors = []ast.Selector{}
+	for _, sel := range ruleset.Selectors {
+		if !hasPlaceholder(sel) {
+			selectors = append(selectors, sel)
+		}
+	}
+	ruleset.Selectors = selectors
+
+	if ruleset.DeclarationBlock != nil && len(selectors) == 0 {
+		ruleset.DeclarationBlock.Declarations = nil
+	}
+	if ruleset.DeclarationBlock != nil {
+		var subRuleSets = []*ast.RuleSet{}
+		for _, sub := range ruleset.DeclarationBlock.SubRuleSets {
+			if removeRuleSetPlaceholders(sub) {
+				subRuleSets = append(subRuleSets, sub)
+			}
+		}
+		ruleset.DeclarationBlock.SubRuleSets = subRuleSets
+	}
+	return len(selectors) > 0 || (ruleset.DeclarationBlock != nil && len(ruleset.DeclarationBlock.SubRuleSets) > 0)
+}
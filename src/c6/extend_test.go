@@ -0,0 +1,89 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestExtendClass(t *testing.T) {
+	var out = RunCompilerTest(`
+.error { color: red; }
+.error:hover { color: blue; }
+a.error { color: green; }
+.serious-error { @extend .error; border: 1px; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".error, .serious-error { color: red; }\n\n"+
+		".error:hover, .serious-error:hover { color: blue; }\n\n"+
+		"a.error, a.serious-error { color: green; }\n\n"+
+		".serious-error { border: 1px; }\n", out)
+}
+
+func TestExtendPlaceholder(t *testing.T) {
+	var out = RunCompilerTest(`
+%message { padding: 10px; }
+%unused { color: red; }
+.success { @extend %message; color: green; }
+.warning { @extend %message; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".success, .warning { padding: 10px; }\n\n.success { color: green; }\n", out)
+}
+
+func TestExtendTransitive(t *testing.T) {
+	var out = RunCompilerTest(`
+.a { color: red; }
+.b { @extend .a; }
+.c { @extend .b; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a, .b, .c { color: red; }\n", out)
+}
+
+func TestExtendWeaveParents(t *testing.T) {
+	var out = RunCompilerTest(`
+.a .b { color: red; }
+.x .y { @extend .b; }
+.a > .c { color: blue; }
+.z .w { @extend .c; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a .b, .a .x .y, .x .a .y { color: red; }\n\n.a > .c, .z .a > .w { color: blue; }\n", out)
+}
+
+func TestExtendUnifyConflict(t *testing.T) {
+	var out = RunCompilerTest(`
+a.foo { color: red; }
+span { @extend .foo; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, "a.foo { color: red; }\n", out)
+}
+
+func TestExtendMissingTarget(t *testing.T) {
+	assert.Panics(t, func() {
+		RunCompilerTest(`.a { @extend .missing; }`, compiler.NewCompactStyleCompiler())
+	})
+	var out = RunCompilerTest(`.a { @extend .missing !optional; color: red; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: red; }\n", out)
+}
+
+func TestExtendMedia(t *testing.T) {
+	var out = RunCompilerTest(`
+@media print {
+	.a { color: red; }
+	.b { @extend .a; }
+}`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, "@media print { .a, .b { color: red; } }\n", out)
+
+	// the selectors in @media can be extended from the outside
+	out = RunCompilerTest(`
+@media print { .a { color: red; } }
+.b { @extend .a; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, "@media print { .a, .b { color: red; } }\n", out)
+
+	assert.Panics(t, func() {
+		RunCompilerTest(`
+.a { color: red; }
+@media print { .b { @extend .a; } }`, compiler.NewCompactStyleCompiler())
+	})
+}
+
+func TestMediaOutputStyles(t *testing.T) {
+	var code = `@media screen and (max-width: 100px) { .a { color: red; } }`
+	assert.Equal(t, "@media screen and (max-width: 100px) {\n  .a {\n    color: red; } }\n",
+		RunCompilerTest(code, compiler.NewNestedStyleCompiler()))
+	assert.Equal(t, "@media screen and (max-width: 100px) {\n  .a {\n    color: red;\n  }\n}\n",
+		RunCompilerTest(code, compiler.NewExpandedStyleCompiler()))
+	assert.Equal(t, "@media screen and (max-width:100px){.a{color:red}}",
+		RunCompilerTest(code, compiler.NewCompressedStyleCompiler()))
+}
@@ -0,0 +1,109 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "c6/ast"
+
+/*
+Function is the user-defined function with the scope stack of the
+declaration, and the context of the module that declares the function.
+*/
+type Function struct {
+	Statement *ast.FunctionStatement
+	Scopes    []*Scope
+	Context   *Context
+}
+
+func (context *Context) DefineFunction(fn *ast.FunctionStatement) {
+	context.Functions[fn.Name] = &Function{fn, append([]*Scope{}, context.Scopes...), context}
+}
+
+/*
+LookupFunction finds the user-defined function by name, the namespaced
+function like `math.div` is looked up from the module. It returns nil for the
+plain CSS functions.
+*/
+func (context *Context) LookupFunction(name string) *Function {
+	if namespace, member := splitNamespace(name); namespace != "" {
+		var fn = context.LookupModule(namespace).Function(member)
+		if fn == nil {
+			panic(fmt.Errorf("Undefined function %s", name))
+		}
+		return fn
+	}
+	if fn, ok := context.Functions[name]; ok {
+		return fn
+	}
+	for _, module := range context.GlobalModules {
+		if fn := module.Function(name); fn != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+/*
+CallFunction evaluates the arguments in the current scope, and runs the
+function body in a new scope on top of the declaration scope.
+*/
+func (context *Context) CallFunction(fn *Function, argExprs []ast.Expression) ast.Expression {
+	var args = context.evaluateCallArguments(argExprs)
+	return fn.Context.invokeFunction(fn, args)
+}
+
+func (context *Context) invokeFunction(fn *Function, args *callArguments) ast.Expression {
+	var name = fn.Statement.Name
+
+	context.enterCall()
+	defer context.leaveCall()
+
+	var stack, content = context.Scopes, context.Content
+	context.Scopes = append([]*Scope{}, fn.Scopes...)
+	context.Content = nil
+
+	var scope = context.PushScope(false)
+	context.bindArguments("function "+name, fn.Statement.ArgumentList, args, scope.SymTable)
+	value, ok := context.ExecuteFunctionBody(name, fn.Statement.Block)
+
+	context.Scopes, context.Content = stack, content
+
+	if !ok {
+		panic(fmt.Errorf("Function %s finished without @return", name))
+	}
+	return value
+}
+
+/*
+ExecuteFunctionBody runs the declarations of the function body until
+`@return`, the returned bool reports whether a value is returned.
+*/
+func (context *Context) ExecuteFunctionBody(name string, block *ast.DeclarationBlock) (ast.Expression, bool) {
+	for _, decl := range block.Declarations {
+		switch t := decl.(type) {
+		case *ast.VariableAssignment:
+			context.AssignVariable(t)
+		case *ast.ReturnStatement:
+			return context.EvaluateDividedExpression(t.Value), true
+		case *ast.WarnStatement:
+			context.EvaluateWarn(t)
+		case *ast.IfStatement, *ast.EachStatement, *ast.ForStatement, *ast.WhileStatement:
+			var value ast.Expression
+			var returned = false
+			context.ExecuteControlStatement(decl.(ast.Statement), func(block *ast.DeclarationBlock) bool {
+				value, returned = context.ExecuteFunctionBody(name, block)
+				return returned
+			})
+			if returned {
+				return value, true
+			}
+		case *ast.Property:
+			panic(nodeErrorf(t, "Properties are not allowed in function %s: %s", name, t.Name.String))
+		default:
+			panic(nodeErrorf(decl.(ast.Node), "Unsupported declaration in function %s", name))
+		}
+	}
+	return nil, false
+}
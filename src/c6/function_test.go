@@ -0,0 +1,60 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestFunctionCall(t *testing.T) {
+	var out = RunCompilerTest(`
+@function rem($px, $base: 16px) { @return $px / $base * 1rem; }
+.a { font-size: rem(32px); margin: rem(8px, $base: 4px) 0; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { font-size: 2rem; margin: 2rem 0; }\n", out)
+}
+
+func TestFunctionLocalVariables(t *testing.T) {
+	var out = RunCompilerTest(`
+$gutter: 10px;
+@function double($x) { $result: $x * 2; @return $result; }
+@function gutter($n) { @return double($gutter) * $n; }
+.a { padding: gutter(3); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { padding: 60px; }\n", out)
+}
+
+func TestFunctionCallPassThrough(t *testing.T) {
+	var out = RunCompilerTest(`
+$c: 10px;
+.a { width: calc($c); color: rgba(0, 0, 0, 0.5); font: 12px/1.5 serif; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: calc(10px); color: rgba(0, 0, 0, 0.5); font: 12px/1.5 serif; }\n", out)
+}
+
+func TestFunctionCalc(t *testing.T) {
+	// the incompatible units are computed by the browser
+	var out = RunCompilerTest(`
+$gap: 2px;
+.a { w: calc(100% - 10px); h: calc(100vh - 2em); x: calc($gap * 2 + 1px); y: calc((100% - $gap) / 3); z: clamp(1rem, 2vw + 1rem, 3rem); m: max(100% - 10px, 2px); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { w: calc(100% - 10px); h: calc(100vh - 2em); x: calc(5px); y: calc((100% - 2px)/3); z: clamp(1rem, 2vw + 1rem, 3rem); m: max(100% - 10px, 2px); }\n", out)
+}
+
+func TestFunctionWithoutReturn(t *testing.T) {
+	assert.Panics(t, func() {
+		RunCompilerTest(`@function f() { $a: 1; } .a { width: f(); }`, compiler.NewCompactStyleCompiler())
+	})
+}
+
+func TestFunctionUnsupportedDeclaration(t *testing.T) {
+	_, err := Compile([]byte("@function f() {\n  color: red;\n  @return 1;\n}\n.a { w: f(); }"), Options{Filename: "foo.scss"})
+	compileErr, ok := err.(*CompileError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Properties are not allowed in function f: color", compileErr.Message)
+		assert.Equal(t, 2, compileErr.Line)
+		assert.Equal(t, 3, compileErr.Column)
+	}
+}
+
+func TestFunctionStackDepthExceeded(t *testing.T) {
+	_, err := Compile([]byte(`@function f($n) { @return f($n); } .a { w: f(1); }`), Options{})
+	assert.Equal(t, "Stack depth exceeded", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`@mixin m { @include m; } .a { @include m; }`), Options{})
+	assert.Equal(t, "Stack depth exceeded", compileErrorMessage(t, err))
+}
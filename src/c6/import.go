@@ -0,0 +1,169 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "strings"
+import "c6/ast"
+
+/*
+ResolveImportPath finds the canonical url of the imported url by the importer
+chain. The url is looked up from the directory of the importing file first,
+and then the importers and the load paths.
+
+For `@import "foo/bar"`, these files are tried in each directory:
+
+	foo/_bar.scss
+	foo/bar.scss
+	foo/_bar.sass
+	foo/bar.sass
+	foo/bar.css
+	foo/bar/_index.scss
+	foo/bar/index.scss
+	foo/bar/_index.sass
+	foo/bar/index.sass
+
+It's an error if more than one of the stylesheets are found, like
+`foo/_bar.scss` and `foo/bar.scss`, or the index files of the directory.
+*/
+func (context *Context) ResolveImportPath(url string, from string) (string, error) {
+	return context.CanonicalizeImport(url, from)
+}
+
+/*
+IsPlainCssImport reports whether the import is kept as a plain CSS
+`@import` in the output instead of being imported by the compiler:
+
+	@import url(foo.css);
+	@import "http://foo.com/bar";
+	@import "foo.css";
+	@import "foo" screen;
+*/
+func IsPlainCssImport(stm *ast.ImportStatement) bool {
+	url, ok := stm.Url.(ast.RelativeUrl)
+	if !ok || len(stm.MediaList) > 0 {
+		return true
+	}
+	var str = string(url)
+	return strings.HasPrefix(str, "http://") ||
+		strings.HasPrefix(str, "https://") ||
+		strings.HasPrefix(str, "//") ||
+		strings.HasSuffix(str, ".css")
+}
+
+/*
+ImportFrame is a file on the import stack, with the line of the statement
+that imports it in the previous file.
+*/
+type ImportFrame struct {
+	Path string
+
+	// The line of @import, @use or @forward, it's 0 for the entry file
+	Line int
+}
+
+/*
+CurrentFile returns the file being evaluated, it's empty when the evaluated
+code is not from a file.
+*/
+func (context *Context) CurrentFile() string {
+	if len(context.ImportStack) > 0 {
+		return context.ImportStack[len(context.ImportStack)-1].Path
+	}
+	return ""
+}
+
+/*
+EvaluateFile parses and evaluates the file, the imports are resolved from the
+directory of the file.
+*/
+func (context *Context) EvaluateFile(path string) (*ast.Block, error) {
+	var parser = NewParser(context)
+	block, err := parser.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	context.pushImport(parser.File, nil)
+	defer context.popImport()
+	return context.Evaluate(block), nil
+}
+
+/*
+pushImport pushes the file imported by the statement of the token onto the
+import stack, the file already on the stack is an import loop.
+*/
+func (context *Context) pushImport(path string, token *ast.Token) {
+	context.checkImportLoop(path, token)
+	var line = 0
+	if token != nil {
+		line = token.Line + 1
+	}
+	context.ImportStack = append(context.ImportStack, &ImportFrame{Path: path, Line: line})
+	context.ImportedFiles[path] = true
+}
+
+func (context *Context) popImport() {
+	context.ImportStack = context.ImportStack[:len(context.ImportStack)-1]
+}
+
+/*
+checkImportLoop panics with the import chain if the file is being evaluated:
+
+	An import loop has been found:
+	    main.scss:1 imports a.scss
+	    a.scss:3 imports b.scss
+	    b.scss:1 imports a.scss
+*/
+func (context *Context) checkImportLoop(path string, token *ast.Token) {
+	var found = false
+	for _, frame := range context.ImportStack {
+		if frame.Path == path {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	var hops = []string{}
+	var stack = append(append([]*ImportFrame{}, context.ImportStack...), &ImportFrame{Path: path})
+	if token != nil {
+		stack[len(stack)-1].Line = token.Line + 1
+	}
+	for i := 1; i < len(stack); i++ {
+		hops = append(hops, fmt.Sprintf("%s:%d imports %s", stack[i-1].Path, stack[i].Line, stack[i].Path))
+	}
+	panic(fmt.Errorf("An import loop has been found:\n    %s", strings.Join(hops, "\n    ")))
+}
+
+/*
+ImportFile resolves the imported file, and evaluates the statements of the
+file into the output block as they are written in the place of the import
+statement. The file that is already included is skipped by `@import-once` or
+the ImportOnce option.
+*/
+func (context *Context) ImportFile(stm *ast.ImportStatement, out *ast.Block) {
+	var url = string(stm.Url.(ast.RelativeUrl))
+	path, err := context.ResolveImportPath(url, context.CurrentFile())
+	if err != nil {
+		panic(err)
+	}
+	if (stm.Once || context.ImportOnce) && context.ImportedFiles[path] {
+		return
+	}
+	context.checkImportLoop(path, stm.Token)
+
+	block, err := NewParser(context).ParseFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	context.pushImport(path, stm.Token)
+	defer context.popImport()
+	for _, importedStm := range block.Statements {
+		context.EvaluateStatement(importedStm, out)
+	}
+}
@@ -0,0 +1,159 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		var path = filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestImportResolution(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"src/main.scss":               `@import "partials/vars"; @import "components"; @import "reset"; @import "mixins"; .main { color: $color; @include box; }`,
+		"src/partials/_vars.scss":     `$color: red;`,
+		"src/components/_index.scss":  `@import "button";`,
+		"src/components/_button.scss": `.btn { color: $color; }`,
+		"src/reset.css":               `a { margin: 0; }`,
+		"lib/_mixins.scss":            `@mixin box { padding: 1px; }`,
+	})
+
+	var context = NewContext()
+	context.LoadPaths = []string{filepath.Join(dir, "lib")}
+	block, err := context.EvaluateFile(filepath.Join(dir, "src", "main.scss"))
+	assert.Nil(t, err)
+	var out = compiler.NewCompactStyleCompiler().CompileBlock(block)
+	assert.Equal(t, ".btn { color: red; }\n\na { margin: 0; }\n\n.main { color: red; padding: 1px; }\n", out)
+}
+
+func TestImportAmbiguous(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss":  `@import "vars";`,
+		"_vars.scss": `$color: red;`,
+		"vars.scss":  `$color: blue;`,
+		"lib.scss":   `@use "theme";`,
+		"_lib.sass":  `$a: 1`,
+	})
+
+	_, err = CompileFile(filepath.Join(dir, "main.scss"), Options{})
+	compileErr, ok := err.(*CompileError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, compileErr.Line)
+	assert.Equal(t, "It's not clear which file to import. Found:\n    "+filepath.Join(dir, "_vars.scss")+"\n    "+filepath.Join(dir, "vars.scss"), compileErr.Message)
+
+	// the partial and the non-partial of the other syntax
+	_, err = NewFSImporter(os.DirFS(dir), "").Canonicalize("lib", "")
+	assert.Equal(t, "It's not clear which file to import. Found:\n    lib.scss\n    _lib.sass", err.Error())
+}
+
+func TestImportList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss":      `@import "partial", "lib/comp", "theme.css"; .main { color: $color; }`,
+		"_partial.scss":  `$color: red; .partial { color: $color; }`,
+		"lib/_comp.scss": `.comp { color: $color; }`,
+	})
+
+	block, err := NewContext().EvaluateFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	var out = compiler.NewCompactStyleCompiler().CompileBlock(block)
+	assert.Equal(t, ".partial { color: red; }\n\n.comp { color: red; }\n@import \"theme.css\";\n.main { color: red; }\n", out)
+}
+
+func TestImportPlainCss(t *testing.T) {
+	var out = RunCompilerTest(`
+@import "theme.css";
+@import url(foo.css);
+@import "http://foo.com/bar";
+@import "print" print;
+a { color: red; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, "@import \"theme.css\";\n@import url(foo.css);\n@import \"http://foo.com/bar\";\n@import \"print\" print;\na { color: red; }\n", out)
+}
+
+func TestImportNotFound(t *testing.T) {
+	assert.Panics(t, func() {
+		RunCompilerTest(`@import "missing";`, compiler.NewCompactStyleCompiler())
+	})
+}
+
+func TestImportOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss":   `@import "a"; @import "b"; @import-once "base";`,
+		"_a.scss":     `@import-once "base"; .a { color: red; }`,
+		"_b.scss":     `@import "base"; .b { color: blue; }`,
+		"_base.scss":  `.base { margin: 0; }`,
+		"option.scss": `@import "a"; @import "base";`,
+		"plain.scss":  `@import-once "foo.css";`,
+	})
+
+	// the plain @import is still imported again
+	block, err := NewContext().EvaluateFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	var out = compiler.NewCompactStyleCompiler().CompileBlock(block)
+	assert.Equal(t, ".base { margin: 0; }\n\n.a { color: red; }\n\n.base { margin: 0; }\n\n.b { color: blue; }\n", out)
+
+	var context = NewContext()
+	context.ImportOnce = true
+	block, err = context.EvaluateFile(filepath.Join(dir, "option.scss"))
+	assert.Nil(t, err)
+	out = compiler.NewCompactStyleCompiler().CompileBlock(block)
+	assert.Equal(t, ".base { margin: 0; }\n\n.a { color: red; }\n", out)
+
+	block, err = NewContext().EvaluateFile(filepath.Join(dir, "plain.scss"))
+	assert.Nil(t, err)
+	out = compiler.NewCompactStyleCompiler().CompileBlock(block)
+	assert.Equal(t, "@import \"foo.css\";\n", out)
+}
+
+func TestImportLoop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss": "@import \"a\";",
+		"_a.scss":   ".a { color: red; }\n@import \"b\";",
+		"_b.scss":   "\n\n@import \"a\";",
+		"use.scss":  "@use \"c\";",
+		"c.scss":    "@forward \"use\";",
+	})
+
+	var main = filepath.Join(dir, "main.scss")
+	var a = filepath.Join(dir, "_a.scss")
+	var b = filepath.Join(dir, "_b.scss")
+	defer func() {
+		var r = recover()
+		assert.NotNil(t, r)
+		assert.Equal(t, "An import loop has been found:\n"+
+			"    "+main+":1 imports "+a+"\n"+
+			"    "+a+":2 imports "+b+"\n"+
+			"    "+b+":3 imports "+a, r.(error).Error())
+	}()
+	assert.Panics(t, func() {
+		NewContext().EvaluateFile(filepath.Join(dir, "use.scss"))
+	})
+	NewContext().EvaluateFile(main)
+}
@@ -0,0 +1,223 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "io/fs"
+import "io/ioutil"
+import "os"
+import "path"
+import "path/filepath"
+import "strings"
+
+/*
+Importer loads the stylesheets of @import, @use and @forward, and the file
+compiled by ParseFile.
+
+Canonicalize resolves the url imported from the canonical url of the
+importing file, `from` is empty when the url is not relative to a file, like
+the entry file and the lookup of the load paths. It returns an empty string
+if the importer can't find the url, so the next importer is tried.
+
+Load returns the contents and the syntax of the canonical url.
+*/
+type Importer interface {
+	Canonicalize(url string, from string) (string, error)
+	Load(canonical string) (contents string, syntax Syntax, err error)
+}
+
+/*
+FilesystemImporter imports the files from the directory, the canonical urls
+are the file paths. The empty directory is the working directory.
+*/
+type FilesystemImporter struct {
+	Dir string
+}
+
+func (self *FilesystemImporter) Canonicalize(url string, from string) (string, error) {
+	var base = filepath.Join(self.Dir, filepath.FromSlash(url))
+	if from != "" {
+		base = filepath.Join(filepath.Dir(from), filepath.FromSlash(url))
+	}
+	candidate, err := findImportCandidate(filepath.ToSlash(base), func(candidate string) bool {
+		info, err := os.Stat(filepath.FromSlash(candidate))
+		return err == nil && !info.IsDir()
+	})
+	return filepath.FromSlash(candidate), err
+}
+
+func (self *FilesystemImporter) Load(canonical string) (string, Syntax, error) {
+	data, err := ioutil.ReadFile(canonical)
+	if err != nil {
+		return "", UnknownFileType, err
+	}
+	return string(data), syntaxOf(canonical), nil
+}
+
+/*
+FSImporter imports the files from the directory of the file system, like the
+embedded files of `embed.FS`. The canonical urls are the slash-separated paths
+in the file system.
+*/
+type FSImporter struct {
+	FS  fs.FS
+	Dir string
+}
+
+func NewFSImporter(fsys fs.FS, dir string) *FSImporter {
+	return &FSImporter{FS: fsys, Dir: dir}
+}
+
+func (self *FSImporter) Canonicalize(url string, from string) (string, error) {
+	var base = path.Join(self.Dir, url)
+	if from != "" {
+		base = path.Join(path.Dir(from), url)
+	}
+	return findImportCandidate(base, func(candidate string) bool {
+		if !fs.ValidPath(candidate) {
+			return false
+		}
+		info, err := fs.Stat(self.FS, candidate)
+		return err == nil && !info.IsDir()
+	})
+}
+
+func (self *FSImporter) Load(canonical string) (string, Syntax, error) {
+	data, err := fs.ReadFile(self.FS, canonical)
+	if err != nil {
+		return "", UnknownFileType, err
+	}
+	return string(data), syntaxOf(canonical), nil
+}
+
+/*
+importCandidates returns the groups of the slash-separated paths tried for
+the imported path, see ResolveImportPath. The files found in the same group
+are ambiguous.
+*/
+func importCandidates(p string) [][]string {
+	var dir, base = path.Split(p)
+	switch path.Ext(base) {
+	case ".scss", ".sass", ".css":
+		return [][]string{{path.Join(dir, "_"+base), p}}
+	}
+	return [][]string{
+		{
+			path.Join(dir, "_"+base+".scss"),
+			p + ".scss",
+			path.Join(dir, "_"+base+".sass"),
+			p + ".sass",
+		},
+		{p + ".css"},
+		// the directory import
+		{
+			path.Join(p, "_index.scss"),
+			path.Join(p, "index.scss"),
+			path.Join(p, "_index.sass"),
+			path.Join(p, "index.sass"),
+		},
+	}
+}
+
+/*
+findImportCandidate returns the candidate of the imported path that exists,
+it's an error if the partial and the non-partial file both exist like
+`_foo.scss` and `foo.scss`.
+*/
+func findImportCandidate(p string, exists func(candidate string) bool) (string, error) {
+	for _, group := range importCandidates(p) {
+		var found = []string{}
+		for _, candidate := range group {
+			if exists(candidate) {
+				found = append(found, candidate)
+			}
+		}
+		if len(found) > 1 {
+			return "", fmt.Errorf("It's not clear which file to import. Found:\n    %s", strings.Join(found, "\n    "))
+		}
+		if len(found) == 1 {
+			return found[0], nil
+		}
+	}
+	return "", nil
+}
+
+func syntaxOf(canonical string) Syntax {
+	return getFileTypeByExtension(strings.TrimPrefix(path.Ext(canonical), "."))
+}
+
+/*
+importers returns the importer chain of the context, the configured
+importers are tried before the load paths.
+*/
+func (context *Context) importers() []Importer {
+	var importers = append([]Importer{}, context.Importers...)
+	for _, dir := range context.LoadPaths {
+		importers = append(importers, &FilesystemImporter{Dir: dir})
+	}
+	return importers
+}
+
+/*
+CanonicalizeImport resolves the url imported from the file by the importer
+chain, the importer of the file is tried first for the relative url.
+*/
+func (context *Context) CanonicalizeImport(url string, from string) (string, error) {
+	if importer, ok := context.canonicalImporters[from]; ok && from != "" {
+		canonical, err := importer.Canonicalize(url, from)
+		if err != nil {
+			return "", err
+		}
+		if canonical != "" {
+			context.canonicalImporters[canonical] = importer
+			return canonical, nil
+		}
+	}
+	if canonical, err := context.canonicalizeBy(context.importers(), url); canonical != "" || err != nil {
+		return canonical, err
+	}
+	return "", fmt.Errorf("File to import not found or unreadable: %s", url)
+}
+
+/*
+canonicalizeFile resolves the file compiled by ParseFile. The url that is
+already resolved by the importer chain is kept, the others are looked up by
+the configured importers and the working directory.
+*/
+func (context *Context) canonicalizeFile(url string) (string, error) {
+	if _, ok := context.canonicalImporters[url]; ok {
+		return url, nil
+	}
+	var importers = append(append([]Importer{}, context.Importers...), &FilesystemImporter{})
+	if canonical, err := context.canonicalizeBy(importers, url); canonical != "" || err != nil {
+		return canonical, err
+	}
+	return "", fmt.Errorf("File not found or unreadable: %s", url)
+}
+
+func (context *Context) canonicalizeBy(importers []Importer, url string) (string, error) {
+	for _, importer := range importers {
+		canonical, err := importer.Canonicalize(url, "")
+		if err != nil {
+			return "", err
+		}
+		if canonical != "" {
+			context.canonicalImporters[canonical] = importer
+			return canonical, nil
+		}
+	}
+	return "", nil
+}
+
+/*
+LoadImport loads the contents of the canonical url by its importer.
+*/
+func (context *Context) LoadImport(canonical string) (string, Syntax, error) {
+	importer, ok := context.canonicalImporters[canonical]
+	if !ok {
+		return "", UnknownFileType, fmt.Errorf("File not found or unreadable: %s", canonical)
+	}
+	return importer.Load(canonical)
+}
@@ -0,0 +1,80 @@
+package c6
+
+import "errors"
+import "strings"
+import "testing"
+import "testing/fstest"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+// mapImporter imports the stylesheets from the map, like the templates
+// stored in a database
+type mapImporter map[string]string
+
+func (self mapImporter) Canonicalize(url string, from string) (string, error) {
+	if strings.HasPrefix(url, "broken") {
+		return "", errors.New("the database is down")
+	}
+	if _, ok := self["db:"+url]; ok {
+		return "db:" + url, nil
+	}
+	return "", nil
+}
+
+func (self mapImporter) Load(canonical string) (string, Syntax, error) {
+	return self[canonical], ScssFileType, nil
+}
+
+func TestFSImporter(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"styles/main.scss":            {Data: []byte(`@use "theme"; @import "partials/vars"; .main { color: $color; background: theme.$bg; }`)},
+		"styles/partials/_vars.scss":  {Data: []byte(`@import "more"; $color: red;`)},
+		"styles/partials/_more.scss":  {Data: []byte(`.more { margin: 0; }`)},
+		"styles/theme/_index.sass":    {Data: []byte("$bg: blue\n")},
+		"styles/unused/_ignored.scss": {Data: []byte(`.ignored { }`)},
+	}
+
+	result, err := CompileFile("styles/main.scss", Options{Style: compiler.CompactStyle, Importers: []Importer{NewFSImporter(fsys, "")}})
+	assert.Nil(t, err)
+	assert.Equal(t, ".more { margin: 0; }\n\n.main { color: red; background: blue; }\n", result.CSS)
+	assert.Equal(t, []string{"styles/main.scss", "styles/partials/_more.scss", "styles/partials/_vars.scss", "styles/theme/_index.sass"}, result.IncludedFiles)
+
+	// the directory of the importer works like a load path
+	result, err = Compile([]byte(`@import "partials/more";`), Options{Style: compiler.CompactStyle, Importers: []Importer{NewFSImporter(fsys, "styles")}})
+	assert.Nil(t, err)
+	assert.Equal(t, ".more { margin: 0; }\n", result.CSS)
+
+	_, err = CompileFile("styles/missing.scss", Options{Importers: []Importer{NewFSImporter(fsys, "")}})
+	assert.Equal(t, "File not found or unreadable: styles/missing.scss", err.Error())
+}
+
+func TestFSImporterSourceMap(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"main.scss": {Data: []byte(".main { color: red; }")},
+	}
+	var opts = Options{Style: compiler.CompactStyle, Importers: []Importer{NewFSImporter(fsys, "")}}
+	opts.SourceMap = SourceMapOptions{Enabled: true, File: "dist/main.css", Contents: true}
+	result, err := CompileFile("main.scss", opts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main.scss"}, result.SourceMap.Sources)
+	assert.Equal(t, []string{".main { color: red; }"}, result.SourceMap.SourcesContent)
+}
+
+func TestImporterChain(t *testing.T) {
+	var db = mapImporter{
+		"db:brand": `@import "base"; $brand: #123;`,
+		"db:base":  `.base { margin: 0; }`,
+	}
+	var opts = Options{Style: compiler.CompactStyle, Importers: []Importer{db}}
+
+	result, err := Compile([]byte(`@use "brand"; .a { color: brand.$brand; }`), opts)
+	assert.Nil(t, err)
+	assert.Equal(t, ".base { margin: 0; }\n\n.a { color: #123; }\n", result.CSS)
+	assert.Equal(t, []string{"db:base", "db:brand"}, result.IncludedFiles)
+
+	_, err = Compile([]byte(`@import "broken";`), opts)
+	assert.Equal(t, "the database is down", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`@import "missing";`), opts)
+	assert.Equal(t, "File to import not found or unreadable: missing", compileErrorMessage(t, err))
+}
@@ -0,0 +1,345 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "strings"
+import "c6/ast"
+
+/*
+ParseSass parses the code of the indented syntax, the code is converted to
+SCSS and parsed by the SCSS parser, so it produces the same ast.Block.
+*/
+func (parser *Parser) ParseSass(code string) (*ast.Block, error) {
+	scss, err := ConvertSassToScss(code)
+	if err != nil {
+		if compileErr, ok := err.(*CompileError); ok {
+			compileErr.File = parser.File
+		}
+		return nil, err
+	}
+
+	var diagnostics = len(parser.Diagnostics)
+	block, err := parser.ParseScss(scss)
+	if err != nil {
+		// the errors are reported with the lines of the indented code
+		var source = newSassSource(code, scss)
+		if compileErr, ok := err.(*CompileError); ok && compileErr.File == parser.File {
+			source.mapError(compileErr)
+		}
+		for i := diagnostics; i < len(parser.Diagnostics); i++ {
+			if parser.Diagnostics[i].File == parser.File {
+				parser.Diagnostics[i].Range = source.mapRange(parser.Diagnostics[i].Range)
+			}
+		}
+	}
+	return block, err
+}
+
+/*
+sassSource maps the positions of the converted SCSS back to the indented
+code. The lines are kept by the conversion, and the columns are aligned by the
+text shared at the end of the lines, since the shorthands and the closing
+braces are rewritten in front of the statements.
+*/
+type sassSource struct {
+	code  string
+	lines []string
+	scss  []string
+}
+
+func newSassSource(code string, scss string) sassSource {
+	code = strings.Replace(code, "\r\n", "\n", -1)
+	return sassSource{code, strings.Split(code, "\n"), strings.Split(scss, "\n")}
+}
+
+func (source sassSource) mapError(err *CompileError) {
+	err.Range = source.mapRange(err.Range)
+	err.Line = err.Range.Start.Line
+	err.Column = err.Range.Start.Column
+	err.Excerpt = sourceExcerpt(source.code, err.Line, err.Column)
+}
+
+func (source sassSource) mapRange(r ast.Range) ast.Range {
+	r.Start = source.mapPosition(r.Start)
+	r.End = source.mapPosition(r.End)
+	return r
+}
+
+func (source sassSource) mapPosition(pos ast.Position) ast.Position {
+	if pos.Line < 1 {
+		return pos
+	}
+	if pos.Line > len(source.lines) {
+		// the braces closed at the end of the code
+		pos.Line = len(source.lines)
+		pos.Column = len([]rune(source.lines[pos.Line-1])) + 1
+	} else if pos.Line <= len(source.scss) {
+		pos.Column = mapSassColumn(source.lines[pos.Line-1], source.scss[pos.Line-1], pos.Column-1) + 1
+	}
+
+	pos.Offset = 0
+	for _, line := range source.lines[:pos.Line-1] {
+		pos.Offset += len(line) + 1
+	}
+	pos.Offset += len(string([]rune(source.lines[pos.Line-1])[:pos.Column-1]))
+	return pos
+}
+
+/*
+mapSassColumn maps the column of the converted line to the original line, the
+columns start from 0. The columns of the rewritten text are mapped to the
+start of the statement, and the columns of the appended `{` or `;` to the end
+of the line.
+*/
+func mapSassColumn(line string, converted string, column int) int {
+	var text = []rune(strings.TrimRight(stripSassComment(line), " \t"))
+	var body = []rune(strings.TrimSuffix(strings.TrimSuffix(converted, " {"), ";"))
+	if column >= len(body) {
+		return len(text)
+	}
+
+	var shared = 0
+	for shared < len(text) && shared < len(body) && text[len(text)-1-shared] == body[len(body)-1-shared] {
+		shared++
+	}
+	if column >= len(body)-shared {
+		return column - len(body) + len(text)
+	}
+	return len([]rune(line[:sassIndent(line)]))
+}
+
+/*
+ConvertSassToScss converts the indented syntax to SCSS:
+
+	=button($color)
+	  color: $color
+	  &:hover
+	    +highlight
+
+	.a,
+	.b
+	  +button(red)
+
+is converted to
+
+	@mixin button($color) {
+	  color: $color;
+	  &:hover {
+	    @include highlight;
+
+	} } .a,
+	.b {
+	  @include button(red);
+	}
+
+The lines followed by the deeper indented lines open the blocks, the blocks
+are closed in front of the next line with less indentation, and the other
+lines are terminated by semicolons. The selector list is continued on the
+next line after the trailing comma. The comments are removed, and the line
+numbers are kept, so the tokens refer to the lines of the indented code.
+
+The inconsistent indentation is returned as *CompileError.
+*/
+func ConvertSassToScss(code string) (scss string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if compileErr, ok := r.(*CompileError); ok {
+				err = compileErr
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	var lines = strings.Split(strings.Replace(code, "\r\n", "\n", -1), "\n")
+	var out = make([]string, len(lines))
+
+	// the indentation of the lines in the open blocks, the top level is 0
+	var blocks = []int{0}
+	var indentChar byte = 0
+
+	var i = 0
+	for i < len(lines) {
+		var text = strings.TrimSpace(lines[i])
+		if text == "" {
+			i++
+			continue
+		}
+
+		var indent = sassIndent(lines[i])
+		for _, c := range []byte(lines[i][:indent]) {
+			if indentChar == 0 {
+				indentChar = c
+			} else if c != indentChar {
+				panic(sassError("Inconsistent indentation, tabs and spaces are mixed", lines, i))
+			}
+		}
+
+		// the comment and its deeper indented lines are removed
+		if strings.HasPrefix(text, "//") || strings.HasPrefix(text, "/*") {
+			i = skipSassBlock(lines, i+1, indent)
+			continue
+		}
+
+		var closing = ""
+		for indent < blocks[len(blocks)-1] {
+			blocks = blocks[:len(blocks)-1]
+			closing += "} "
+		}
+		if indent != blocks[len(blocks)-1] {
+			panic(sassError("Inconsistent indentation", lines, i))
+		}
+
+		// the selector list continues after the trailing comma
+		var last = i
+		for strings.HasSuffix(stripSassComment(lines[last]), ",") && last+1 < len(lines) && strings.TrimSpace(lines[last+1]) != "" {
+			last++
+		}
+
+		var next = nextSassLine(lines, last+1)
+		var opener = next < len(lines) && sassIndent(lines[next]) > indent
+		if opener && isSassDeclaration(text) {
+			// nothing is nested in the declaration, the line is over-indented
+			panic(sassError("Inconsistent indentation", lines, next))
+		}
+
+		for j := i; j <= last; j++ {
+			out[j] = strings.TrimRight(stripSassComment(lines[j]), " \t")
+		}
+		out[i] = lines[i][:indent] + closing + convertSassStatement(strings.TrimSpace(out[i]), opener)
+		if opener {
+			out[last] += " {"
+			blocks = append(blocks, sassIndent(lines[next]))
+		} else {
+			out[last] += ";"
+		}
+		i = last + 1
+	}
+
+	if len(blocks) > 1 {
+		out = append(out, strings.Repeat("} ", len(blocks)-1))
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// sassError creates the error at the indentation of the line
+func sassError(message string, lines []string, line int) *CompileError {
+	var offset = 0
+	for _, l := range lines[:line] {
+		offset += len(l) + 1
+	}
+	var code = strings.Join(lines, "\n")
+	return NewCompileError(message, "", code, offset+sassIndent(lines[line]), nil)
+}
+
+// nextSassLine returns the next non-blank line from the start
+func nextSassLine(lines []string, start int) int {
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	return start
+}
+
+// skipSassBlock skips the lines indented deeper than the indent
+func skipSassBlock(lines []string, start int, indent int) int {
+	var next = nextSassLine(lines, start)
+	for next < len(lines) && sassIndent(lines[next]) > indent {
+		next = nextSassLine(lines, next+1)
+	}
+	return next
+}
+
+func sassIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+/*
+stripSassComment removes the trailing `//` comment of the line, the slashes
+in the strings and the url like `url(http://foo.com)` are not comments.
+*/
+func stripSassComment(line string) string {
+	var quote byte = 0
+	var parens = 0
+	for i := 0; i < len(line); i++ {
+		var c = line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			parens++
+		case c == ')':
+			parens--
+		case c == '/' && parens == 0 && i+1 < len(line) && line[i+1] == '/':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+/*
+convertSassStatement converts the shorthands of the indented syntax:
+
+	=button       @mixin button
+	+button       @include button
+	:color red    color: red
+	@import a, b  @import "a"; @import "b"
+*/
+func convertSassStatement(text string, opener bool) string {
+	switch {
+	case strings.HasPrefix(text, "=") && len(text) > 1 && isSassNameStart(text[1]):
+		return "@mixin " + text[1:]
+	case strings.HasPrefix(text, "+") && len(text) > 1 && isSassNameStart(text[1]):
+		return "@include " + text[1:]
+	case strings.HasPrefix(text, ":") && len(text) > 1 && isSassNameStart(text[1]) && !opener:
+		// the old property syntax `:name value`
+		var parts = strings.SplitN(text[1:], " ", 2)
+		if len(parts) == 2 {
+			return parts[0] + ": " + strings.TrimSpace(parts[1])
+		}
+	case strings.HasPrefix(text, "@import "):
+		var urls = []string{}
+		for _, url := range strings.Split(text[len("@import "):], ",") {
+			url = strings.TrimSpace(url)
+			if !strings.HasPrefix(url, "\"") && !strings.HasPrefix(url, "'") && !strings.HasPrefix(url, "url(") {
+				url = "\"" + url + "\""
+			}
+			urls = append(urls, "@import "+url)
+		}
+		return strings.Join(urls, "; ")
+	}
+	return text
+}
+
+/*
+isSassDeclaration reports whether the line is the property or the variable
+declaration with the value like `color: red` or `$a: 1`, the selectors like
+`a:hover` have no space after the colon.
+*/
+func isSassDeclaration(text string) bool {
+	var name = strings.TrimPrefix(text, "$")
+	if name == "" || !isSassNameStart(name[0]) {
+		return false
+	}
+	var colon = strings.IndexByte(name, ':')
+	if colon < 0 || colon+1 >= len(name) || (name[colon+1] != ' ' && name[colon+1] != '\t') {
+		return false
+	}
+	for _, c := range []byte(name[:colon]) {
+		if !isSassNameStart(c) && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return strings.TrimSpace(name[colon+1:]) != ""
+}
+
+func isSassNameStart(c byte) bool {
+	return c == '-' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,111 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+
+func RunSassCompilerTest(code string, styleCompiler compiler.Compiler) string {
+	block, err := NewParser(NewContext()).ParseSass(code)
+	if err != nil {
+		panic(err)
+	}
+	return styleCompiler.CompileBlock(NewContext().Evaluate(block))
+}
+
+func TestConvertSassToScss(t *testing.T) {
+	var code = `// the mixin
+  with the nested comment
+=button($color)
+  color: $color // the color
+  &:hover
+    +highlight
+
+.a,
+.b
+  +button(red)
+  :margin 0
+@import foo, "bar"`
+	scss, err := ConvertSassToScss(code)
+	assert.Nil(t, err)
+	assert.Equal(t, `
+
+@mixin button($color) {
+  color: $color;
+  &:hover {
+    @include highlight;
+
+} } .a,
+.b {
+  @include button(red);
+  margin: 0;
+} @import "foo"; @import "bar";`, scss)
+}
+
+func TestSassCompile(t *testing.T) {
+	var out = RunSassCompilerTest(`
+$color: red !default
+
+=box($padding: 1px)
+  padding: $padding
+  @content
+
+@function double($n)
+  @return $n * 2
+
+.a
+  color: $color
+  +box(double(2px))
+    margin: 0
+  .b
+    width: 10px
+`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: red; padding: 4px; margin: 0; }\n.a .b { width: 10px; }\n", out)
+}
+
+func TestSassInconsistentIndentation(t *testing.T) {
+	_, err := ConvertSassToScss(".a\n    color: red\n  width: 1px")
+	assert.Equal(t, "{anonymous}:3:3: Inconsistent indentation\n  width: 1px\n  ^", err.Error())
+
+	_, err = ConvertSassToScss(".a\n  color: red\n\twidth: 1px")
+	assert.NotNil(t, err)
+
+	// the line indented deeper than the declaration
+	_, err = ConvertSassToScss(".a\n  color: red\n   x: y")
+	assert.Equal(t, "{anonymous}:3:4: Inconsistent indentation\n   x: y\n   ^", err.Error())
+
+	_, err = ConvertSassToScss("$a: 1\n  .b\n    x: y")
+	assert.Equal(t, "{anonymous}:2:3: Inconsistent indentation\n  .b\n  ^", err.Error())
+
+	// the pseudo selector opens the block
+	_, err = ConvertSassToScss(".a\n  &:hover\n    x: y")
+	assert.Nil(t, err)
+}
+
+func TestSassParseErrorExcerpt(t *testing.T) {
+	var parser = NewParser(NewContext())
+	_, err := parser.ParseSass(".a\n  +button(red\n  width: 1px ^ 2px")
+	assert.Equal(t, "{anonymous}:2:14: Expecting 'T_PAREN_END', but the actual token we got was ';'.\n  +button(red\n             ^", err.Error())
+	if assert.Equal(t, 2, len(parser.Diagnostics)) {
+		assert.Equal(t, "{anonymous}:3:14: error: Unexpected '^' in property value", parser.Diagnostics[1].String())
+	}
+}
+
+func TestSassImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"main.scss":   `@use "theme"; @import "vars"; .a { color: $color; @include theme.box; }`,
+		"_vars.sass":  "$color: red",
+		"_theme.sass": "=box\n  padding: 1px",
+	})
+	block, err := NewContext().EvaluateFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	var out = compiler.NewCompactStyleCompiler().CompileBlock(block)
+	assert.Equal(t, ".a { color: red; padding: 1px; }\n", out)
+}
@@ -17,7 +17,7 @@ const TOKEN_CHANNEL_BUFFER = 1024
 
 const EOF = -1
 
-const DEBUG_EMIT = true
+const DEBUG_EMIT = false
 
 type Lexer struct {
 	// lex input
@@ -50,9 +50,17 @@ type Lexer struct {
 	// current line number of the input
 	Line int
 
+	// the column of the offset in the current line, it counts the runes, so
+	// the multi-byte characters take one column. The line and the column
+	// start from 0, and they are updated by next() and seek().
+	Column int
+
 	// the token output channel
 	Output chan *ast.Token
 
+	// The errors of the lexer, the lexing is continued after the errors
+	Errors []*CompileError
+
 	Tokens []ast.Token
 }
 
@@ -124,7 +132,7 @@ func (l *Lexer) remember() int {
 // rollback reset the offset to the backup point (this is a rune-wise
 // rollback)
 func (l *Lexer) rollback() {
-	l.Offset = l.RollbackOffset
+	l.seek(l.RollbackOffset)
 }
 
 func (l *Lexer) acceptAndEmit(valid string, tokenType ast.TokenType) bool {
@@ -205,19 +213,69 @@ func (l *Lexer) next() (r rune) {
 	l.LastWidth = l.Width
 	r, l.Width = utf8.DecodeRuneInString(l.Input[l.Offset:])
 	l.Offset += l.Width
+	if r == '\n' {
+		l.Line++
+		l.Column = 0
+	} else {
+		l.Column++
+	}
 	return r
 }
 
 // backup steps back one rune.
 // Can be called only once per call of next.
 func (l *Lexer) backup() {
-	l.Offset -= l.Width
+	l.seek(l.Offset - l.Width)
 }
 
 // backup steps back one rune.
 // Can be called only once per call of next.
 func (l *Lexer) backupByWidth(w int) {
-	l.Offset -= w
+	l.seek(l.Offset - w)
+}
+
+/*
+seek moves the offset, and updates the line and the column by the runes in
+between.
+*/
+func (l *Lexer) seek(offset int) {
+	if offset >= l.Offset {
+		var text = l.Input[l.Offset:offset]
+		if lines := strings.Count(text, "\n"); lines > 0 {
+			l.Line += lines
+			l.Column = utf8.RuneCountInString(text[strings.LastIndex(text, "\n")+1:])
+		} else {
+			l.Column += utf8.RuneCountInString(text)
+		}
+	} else {
+		var text = l.Input[offset:l.Offset]
+		if lines := strings.Count(text, "\n"); lines > 0 {
+			l.Line -= lines
+			l.Column = utf8.RuneCountInString(l.Input[strings.LastIndex(l.Input[:offset], "\n")+1 : offset])
+		} else {
+			l.Column -= utf8.RuneCountInString(text)
+		}
+	}
+	l.Offset = offset
+}
+
+/*
+position returns the position of the offset before the current offset, it's
+counted back from the line and the column of the current offset.
+*/
+func (l *Lexer) position(offset int) ast.Position {
+	var text = l.Input[offset:l.Offset]
+	var lines = strings.Count(text, "\n")
+	if lines == 0 {
+		return ast.Position{Offset: offset, Line: l.Line + 1, Column: l.Column - utf8.RuneCountInString(text) + 1}
+	}
+	var lineStart = strings.LastIndex(l.Input[:offset], "\n") + 1
+	return ast.Position{Offset: offset, Line: l.Line - lines + 1, Column: utf8.RuneCountInString(l.Input[lineStart:offset]) + 1}
+}
+
+// the range of the pending input from the token start offset
+func (l *Lexer) tokenRange() ast.Range {
+	return ast.Range{File: l.File, Start: l.position(l.Start), End: l.position(l.Offset)}
 }
 
 // peek returns but does not consume
@@ -230,7 +288,7 @@ func (l *Lexer) peek() (r rune) {
 
 // advance offset by specific width
 func (l *Lexer) advance(w int) {
-	l.Offset += w
+	l.seek(l.Offset + w)
 }
 
 // peek more characters
@@ -241,7 +299,7 @@ func (l *Lexer) peekBy(p int) (r rune) {
 		r = l.next()
 		w += l.Width
 	}
-	l.Offset -= w
+	l.seek(l.Offset - w)
 	return r
 }
 
@@ -260,11 +318,13 @@ func (l *Lexer) emitToken(token *ast.Token) {
 }
 
 func (l *Lexer) createTokenWith0Offset(tokenType ast.TokenType) *ast.Token {
+	var start = l.position(l.Start)
 	var token = ast.Token{
-		Type: tokenType,
-		Str:  "",
-		Pos:  l.Start,
-		Line: l.Line,
+		Type:  tokenType,
+		Str:   "",
+		Pos:   l.Start,
+		Line:  start.Line - 1,
+		Range: ast.Range{File: l.File, Start: start, End: start},
 	}
 	return &token
 }
@@ -275,11 +335,13 @@ func (l *Lexer) createToken(tokenType ast.TokenType) *ast.Token {
 			panic(fmt.Sprintf("out of range at '%s': start:%d, offset:%d, length: %d", l.Input[l.Start:], l.Start, l.Offset, len(l.Input)))
 		}
 	*/
+	var tokenRange = l.tokenRange()
 	var token = ast.Token{
-		Type: tokenType,
-		Str:  l.Input[l.Start:l.Offset],
-		Pos:  l.Start,
-		Line: l.Line,
+		Type:  tokenType,
+		Str:   l.Input[l.Start:l.Offset],
+		Pos:   l.Start,
+		Line:  tokenRange.Start.Line - 1,
+		Range: tokenRange,
 	}
 	return &token
 }
@@ -290,6 +352,16 @@ func (l *Lexer) emit(tokenType ast.TokenType) {
 	l.emitToken(token)
 }
 
+/*
+emitWithPrefix emits the token without the ignored prefix in its string, like
+the `.` of the class selector, but the range of the token covers the prefix.
+*/
+func (l *Lexer) emitWithPrefix(tokenType ast.TokenType, prefixStart int) {
+	token := l.createToken(tokenType)
+	token.Range.Start = l.position(prefixStart)
+	l.emitToken(token)
+}
+
 // lookahead a string til {string}
 func (l *Lexer) lookaheadTil(stop string) string {
 	l.remember()
@@ -328,13 +400,30 @@ func (l *Lexer) match(str string) bool {
 		width += l.Width
 		if sc != r {
 			// rollback
-			l.Offset -= width
+			l.seek(l.Offset - width)
 			return false
 		}
 	}
 	return true
 }
 
+/*
+match the keyword, the keyword must not be followed by an identifier
+character, so `for` doesn't match `forward`.
+*/
+func (l *Lexer) matchKeyword(str string) bool {
+	var offset = l.Offset
+	if !l.match(str) {
+		return false
+	}
+	var r = l.peek()
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+		l.seek(offset)
+		return false
+	}
+	return true
+}
+
 type KeywordTokenMap map[string]ast.TokenType
 
 func (l *Lexer) matchKeywordMap(keywords KeywordTokenMap) bool {
@@ -369,7 +458,6 @@ func (l *Lexer) ignoreSpaces() int {
 		var r rune = l.peek()
 		if r == '\n' {
 			space++
-			l.Line++
 			l.next()
 		} else if r == ' ' || r == '\t' || r == '\r' {
 			space++
@@ -403,16 +491,67 @@ func (l *Lexer) runFrom(fn stateFn) {
 	if l.Output == nil {
 		l.Output = make(tokenChannel, TOKEN_CHANNEL_BUFFER)
 	}
+	// the nil token is always sent so the parser doesn't wait forever.
+	defer func() {
+		l.Output <- nil
+	}()
+	for fn != nil {
+		fn = l.dispatchRecovering(fn)
+	}
+}
+
+/*
+dispatchRecovering runs the states until the end of the input or the error.
+The error is emitted as T_ERROR token, and the input is skipped to the end of
+the statement, then the lexing is continued with the next statement.
+*/
+func (l *Lexer) dispatchRecovering(fn stateFn) (next stateFn) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*CompileError)
+			if !ok {
+				err = NewCompileError(panicMessage(r), l.File, l.Input, l.Offset, nil)
+			}
+			l.Errors = append(l.Errors, err)
+			l.emitToken(&ast.Token{Type: ast.T_ERROR, Str: err.Message, Pos: err.Range.Start.Offset, Line: err.Line - 1, Range: err.Range})
+			l.skipStatement(err.Range.Start.Offset)
+			next = lexStatement
+		}
+	}()
 	l.dispatchFn(fn)
-	l.Output <- nil
+	return nil
 }
 
-func (l *Lexer) run() {
-	if l.Output == nil {
-		l.Output = make(tokenChannel, TOKEN_CHANNEL_BUFFER)
+/*
+skipStatement skips the input from the offset to the next `;` or `}` of the
+statement, the blocks in between are skipped as a whole. The `;` and `}` are
+lexed by lexStatement, so the lexing always makes progress.
+*/
+func (l *Lexer) skipStatement(offset int) {
+	l.seek(offset)
+	var depth = 0
+	for {
+		var r = l.peek()
+		if r == EOF || (depth == 0 && (r == ';' || r == '}')) {
+			break
+		}
+		l.next()
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				l.ignore()
+				return
+			}
+		}
 	}
-	l.dispatchFn(lexStart)
-	l.Output <- nil
+	l.ignore()
+}
+
+func (l *Lexer) run() {
+	l.runFrom(lexStart)
 }
 
 func (l *Lexer) close() {
@@ -53,6 +53,28 @@ func lexIdentifier(l *Lexer) stateFn {
 	}
 	l.backup()
 
+	// the members of the module are accessed with the namespace, the variable
+	// `math.$pi` and the function call `math.div(10px, 2)`.
+	if l.peek() == '.' {
+		if l.peekBy(2) == '$' {
+			l.next()
+			lexVariableName(l)
+			return lexExpression
+		}
+		if isMemberNameStart(l.peekBy(2)) {
+			var offset = l.Offset
+			l.next()
+			r = l.next()
+			for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+				r = l.next()
+			}
+			l.backup()
+			if l.peek() != '(' {
+				l.seek(offset)
+			}
+		}
+	}
+
 	if l.peek() == '(' {
 		l.emit(ast.T_FUNCTION_NAME)
 		lexFunctionParams(l)
@@ -62,6 +84,11 @@ func lexIdentifier(l *Lexer) stateFn {
 	return lexExpression
 }
 
+// the member name of the module, the private members start with '-' or '_'
+func isMemberNameStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '-' || r == '_'
+}
+
 /*
 Lexing expression with interpolation support.
 */
@@ -72,11 +99,12 @@ func lexExpression(l *Lexer) stateFn {
 	var r2 = l.peekBy(2)
 	var lastToken = l.lastToken()
 
-	// avoid double literal concat
+	// avoid double literal concat, and the slash between the interpolations
+	// like `#{$size}/#{$line-height}` is the slash operator
 	if lastToken != nil && lastToken.Type != ast.T_LITERAL_CONCAT {
-		if leadingSpaces == 0 && lastToken != nil && lastToken.Type == ast.T_INTERPOLATION_END {
+		if leadingSpaces == 0 && lastToken != nil && lastToken.Type == ast.T_INTERPOLATION_END && r != '/' {
 			l.emit(ast.T_LITERAL_CONCAT)
-		} else if leadingSpaces == 0 && l.Offset > 0 && r == '#' && r2 == '{' {
+		} else if leadingSpaces == 0 && l.Offset > 0 && r == '#' && r2 == '{' && lastToken.Type != ast.T_DIV {
 			l.emit(ast.T_LITERAL_CONCAT)
 		}
 	}
@@ -91,6 +119,13 @@ func lexExpression(l *Lexer) stateFn {
 
 		lexIdentifier(l)
 
+	} else if r == '.' && r2 == '.' {
+
+		if !l.match("...") {
+			l.error("Expecting '...', got '%s'", r)
+		}
+		l.emit(ast.T_ELLIPSIS)
+
 	} else if r == '.' && unicode.IsDigit(r2) {
 
 		// lexNumber may return lexNumber unit
@@ -130,6 +165,11 @@ func lexExpression(l *Lexer) stateFn {
 		l.next()
 		l.emit(ast.T_DIV)
 
+	} else if r == '%' {
+
+		l.next()
+		l.emit(ast.T_MOD)
+
 	} else if r == ':' { // a port of map
 
 		l.next()
@@ -150,11 +190,33 @@ func lexExpression(l *Lexer) stateFn {
 		l.next()
 		l.emit(ast.T_PAREN_END)
 
+	} else if r == '=' && r2 == '=' {
+
+		l.match("==")
+		l.emit(ast.T_EQ)
+
 	} else if r == '=' {
 
 		l.next()
 		l.emit(ast.T_EQUAL)
 
+	} else if r == '<' || r == '>' {
+
+		// the comparison operators
+		l.next()
+		if l.peek() == '=' {
+			l.next()
+			if r == '<' {
+				l.emit(ast.T_LE)
+			} else {
+				l.emit(ast.T_GE)
+			}
+		} else if r == '<' {
+			l.emit(ast.T_LT)
+		} else {
+			l.emit(ast.T_GT)
+		}
+
 	} else if r == '#' {
 
 		// ignore interpolation here, we need to handle interpolation in the
@@ -177,6 +239,19 @@ func lexExpression(l *Lexer) stateFn {
 
 		lexVariableName(l)
 
+	} else if r == '!' {
+
+		// the flags of the variable assignment
+		if l.match("!default") {
+			l.emit(ast.T_DEFAULT)
+		} else if l.match("!global") {
+			l.emit(ast.T_GLOBAL)
+		} else if l.match("!=") {
+			l.emit(ast.T_NE)
+		} else {
+			return nil
+		}
+
 	} else if r == EOF {
 
 		return nil
@@ -190,7 +265,9 @@ func lexExpression(l *Lexer) stateFn {
 
 	// for interpolation after any token above
 	if l.peek() == '#' && l.peekBy(2) == '{' {
-		l.emit(ast.T_LITERAL_CONCAT)
+		if lastToken = l.lastToken(); lastToken == nil || lastToken.Type != ast.T_DIV {
+			l.emit(ast.T_LITERAL_CONCAT)
+		}
 		lexInterpolation2(l)
 	}
 
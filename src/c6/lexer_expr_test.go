@@ -107,3 +107,10 @@ func TestLexerExpressionDiv3WithoutSpace(t *testing.T) {
 func TestLexerExpressionMul3WithoutSpace(t *testing.T) {
 	AssertLexerTokenSequenceFromState(t, `$foo*3`, lexExpression, []ast.TokenType{ast.T_VARIABLE, ast.T_MUL, ast.T_INTEGER})
 }
+
+func TestLexerExpressionDivBetweenInterp(t *testing.T) {
+	AssertLexerTokenSequenceFromState(t, `#{$a}/#{$b}`, lexExpression, []ast.TokenType{
+		ast.T_INTERPOLATION_START, ast.T_VARIABLE, ast.T_INTERPOLATION_END,
+		ast.T_DIV,
+		ast.T_INTERPOLATION_START, ast.T_VARIABLE, ast.T_INTERPOLATION_END})
+}
@@ -26,9 +26,12 @@ func lexInterpolation(l *Lexer, emit bool) stateFn {
 
 			// find the end of interpolation end brace
 			r = l.next()
-			for r != '}' {
+			for r != '}' && r != EOF {
 				r = l.next()
 			}
+			if r == EOF {
+				l.error("Expecting interpolation end '}', got %s", r)
+			}
 			l.backup()
 
 			if emit {
@@ -62,12 +65,16 @@ func lexInterpolation2(l *Lexer) stateFn {
 	l.ignoreSpaces()
 
 	r = l.peek()
-	for r != '}' {
+	for r != '}' && r != EOF {
+		var offset = l.Offset
 		lexExpression(l)
 
 		// ignore space
 		l.ignoreSpaces()
 		r = l.peek()
+		if l.Offset == offset {
+			l.error("Unexpected '%s' in interpolation", r)
+		}
 	}
 	l.expect("}")
 	l.emit(ast.T_INTERPOLATION_END)
@@ -77,6 +77,7 @@ Possible property value syntax:
 func lexProperty(l *Lexer) stateFn {
 	var r = l.peek()
 	for r != ':' {
+		var offset = l.Offset
 		if l.peek() == '#' && l.peekBy(2) == '{' {
 			lexInterpolation2(l)
 
@@ -94,6 +95,9 @@ func lexProperty(l *Lexer) stateFn {
 			}
 		}
 		r = l.peek()
+		if l.Offset == offset {
+			l.error("Unexpected '%s' in property name", r)
+		}
 	}
 
 	lexColon(l)
@@ -112,8 +116,12 @@ func lexProperty(l *Lexer) stateFn {
 
 	r = l.peek()
 	for r != ';' && r != '}' && r != EOF {
+		var offset = l.Offset
 		lexExpression(l)
 		r = l.peek()
+		if l.Offset == offset {
+			l.error("Unexpected '%s' in property value", r)
+		}
 	}
 
 	// the semicolon in the last declaration is optional.
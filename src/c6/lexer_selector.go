@@ -20,6 +20,7 @@ func isSelector(t ast.TokenType) bool {
 		t == ast.T_TYPE_SELECTOR ||
 		t == ast.T_UNIVERSAL_SELECTOR ||
 		t == ast.T_PARENT_SELECTOR || // SASS parent selector
+		t == ast.T_PLACEHOLDER_SELECTOR || // SASS placeholder selector
 		t == ast.T_PSEUDO_SELECTOR // :hover, :visited , ...
 }
 
@@ -33,6 +34,7 @@ func isSelectorStopToken(r rune) bool {
 		r == '[' ||
 		r == '#' ||
 		r == '&' ||
+		r == '%' ||
 		r == '>' ||
 		r == '*' ||
 		r == '+' ||
@@ -99,7 +101,7 @@ func lexAttributeSelector(l *Lexer) stateFn {
 		if r == ']' {
 			l.next()
 			l.emit(ast.T_BRACKET_RIGHT)
-			return lexStatement
+			return lexSelectors
 		}
 
 	}
@@ -108,6 +110,7 @@ func lexAttributeSelector(l *Lexer) stateFn {
 }
 
 func lexClassSelector(l *Lexer) stateFn {
+	var prefixStart = l.Offset
 	var r = l.next()
 	if r != '.' {
 		l.error("Unexpected token for class selector. got '%s'", r)
@@ -121,11 +124,36 @@ func lexClassSelector(l *Lexer) stateFn {
 	}
 
 	// skip valid class name characters
-	for unicode.IsLetter(r) || r == '-' || r == '_' {
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+		r = l.next()
+	}
+	l.backup()
+	l.emitWithPrefix(ast.T_CLASS_SELECTOR, prefixStart)
+	return lexSelectors
+}
+
+/*
+Lex the SASS placeholder selector like `%button`, the placeholder is only
+rendered through the selectors that extend it.
+*/
+func lexPlaceholderSelector(l *Lexer) stateFn {
+	var prefixStart = l.Offset
+	var r = l.next()
+	if r != '%' {
+		l.error("Unexpected token for placeholder selector. got '%s'", r)
+	}
+	l.ignore()
+
+	r = l.next()
+	if !unicode.IsLetter(r) && r != '-' && r != '_' {
+		l.error("Expecting letter for placeholder selector. got '%s'", r)
+		return nil
+	}
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
 		r = l.next()
 	}
 	l.backup()
-	l.emit(ast.T_CLASS_SELECTOR)
+	l.emitWithPrefix(ast.T_PLACEHOLDER_SELECTOR, prefixStart)
 	return lexSelectors
 }
 
@@ -134,6 +162,13 @@ func lexParentSelector(l *Lexer) stateFn {
 	if r != '&' {
 		l.error("Unexpected token '%s' for universal selector.", r)
 	}
+
+	// the suffix of the parent selector, e.g. `&-item`, `&__element`
+	r = l.next()
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+		r = l.next()
+	}
+	l.backup()
 	l.emit(ast.T_PARENT_SELECTOR)
 	return lexSelectors
 }
@@ -221,8 +256,9 @@ func lexSelectors(l *Lexer) stateFn {
 
 	lexComment(l, false)
 
-	// space between selector means descendant selector
-	if tok := l.lastToken(); tok != nil && isSelector(tok.Type) {
+	// space between selector means descendant selector, the attribute
+	// selector ends with `]`
+	if tok := l.lastToken(); tok != nil && (isSelector(tok.Type) || tok.Type == ast.T_BRACKET_RIGHT) {
 		var foundSpace = false
 		var r = l.next()
 		for r == ' ' || r == '/' {
@@ -259,6 +295,8 @@ func lexSelectors(l *Lexer) stateFn {
 		return lexPseudoSelector
 	} else if r == '&' {
 		return lexParentSelector
+	} else if r == '%' {
+		return lexPlaceholderSelector
 	} else if r == '*' {
 		return lexUniversalSelector
 	} else if r == '#' {
@@ -303,6 +341,9 @@ func lexSelectors(l *Lexer) stateFn {
 		l.next()
 		l.emit(ast.T_COMMA)
 
+		// the selector list could be continued on the next line
+		l.ignoreSpaces()
+
 		// lex next selector
 		return lexSelectors
 
@@ -365,6 +406,8 @@ func lexTypeSelector(l *Lexer) stateFn {
 		}
 	case '.':
 		return lexClassSelector
+	case '%':
+		return lexPlaceholderSelector
 	case '{':
 		return lexStatement
 	}
@@ -408,6 +451,7 @@ func lexLang(l *Lexer) stateFn {
 
 func lexIdSelector(l *Lexer) stateFn {
 	var foundInterpolation = false
+	var prefixStart = l.Offset
 	var r = l.next()
 	if r != '#' {
 		l.error("Expecting '#' for lexing identifier, Got '%s'", r)
@@ -423,7 +467,7 @@ func lexIdSelector(l *Lexer) stateFn {
 			l.backup()
 			lexInterpolation(l, false)
 			foundInterpolation = true
-		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
 			break
 		}
 		r = l.next()
@@ -431,7 +475,7 @@ func lexIdSelector(l *Lexer) stateFn {
 	l.backup()
 
 	r = l.next()
-	for unicode.IsLetter(r) || unicode.IsDigit(r) {
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
 		r = l.next()
 	}
 	l.backup()
@@ -439,7 +483,7 @@ func lexIdSelector(l *Lexer) stateFn {
 	if foundInterpolation {
 		l.emit(ast.T_INTERPOLATION_SELECTOR)
 	} else {
-		l.emit(ast.T_ID_SELECTOR)
+		l.emitWithPrefix(ast.T_ID_SELECTOR, prefixStart)
 	}
 	return lexSelectors
 }
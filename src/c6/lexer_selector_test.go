@@ -50,6 +50,14 @@ func TestLexerRuleWithAttributeSelector(t *testing.T) {
 	l.close()
 }
 
+func TestLexerRuleWithAttributeSelectorDescendant(t *testing.T) {
+	l := NewLexerWithString(`[href] .a {  }`)
+	assert.NotNil(t, l)
+	l.run()
+	AssertTokenSequence(t, l, []ast.TokenType{ast.T_BRACKET_LEFT, ast.T_ATTRIBUTE_NAME, ast.T_BRACKET_RIGHT, ast.T_DESCENDANT_SELECTOR, ast.T_CLASS_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END})
+	l.close()
+}
+
 func TestLexerRuleWithAttributeSelectorEqualToUnquoteString(t *testing.T) {
 	l := NewLexerWithString(`[lang=en] {  }`)
 	assert.NotNil(t, l)
@@ -430,3 +438,17 @@ func TestLexerSelectorInterpolationInTheMiddleOfPseudoSelector2(t *testing.T) {
 	AssertTokenSequence(t, l, []ast.TokenType{ast.T_INTERPOLATION_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END})
 	l.close()
 }
+
+func TestLexerParentSelectorSuffix(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { &-item { } &__icon.b { } }`, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+		ast.T_PARENT_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END,
+		ast.T_PARENT_SELECTOR, ast.T_CLASS_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END,
+		ast.T_BRACE_END})
+}
+
+func TestLexerMultiLineSelectorList(t *testing.T) {
+	AssertLexerTokenSequence(t, ".a,\n  .b { }", []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_COMMA, ast.T_CLASS_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END,
+	})
+}
@@ -4,8 +4,8 @@ import (
 	"unicode"
 	// import "strings"
 	"c6/ast"
-	"errors"
 	"fmt"
+	"unicode/utf8"
 )
 
 type stateFn func(*Lexer) stateFn
@@ -20,6 +20,7 @@ var exprTokenMap = KeywordTokenMap{
 	"and":   ast.T_AND,
 	"or":    ast.T_OR,
 	"xor":   ast.T_XOR,
+	"not":   ast.T_NOT,
 }
 
 var unitTokenMap = KeywordTokenMap{
@@ -32,6 +33,7 @@ var unitTokenMap = KeywordTokenMap{
 	"ch":  ast.T_UNIT_CH,
 	"in":  ast.T_UNIT_IN,
 	"mm":  ast.T_UNIT_MM,
+	"Q":   ast.T_UNIT_Q,
 	"rem": ast.T_UNIT_REM,
 	"vh":  ast.T_UNIT_VH,
 	"vw":  ast.T_UNIT_VW,
@@ -53,9 +55,22 @@ var unitTokenMap = KeywordTokenMap{
 	"%":    ast.T_UNIT_PERCENT,
 }
 
+/*
+error panics with the CompileError at the unexpected rune, the rune could be
+peeked or already consumed by next().
+*/
 func (l *Lexer) error(msg string, r rune) {
-	var err = errors.New(fmt.Sprintf(msg, string(r)))
-	panic(err)
+	var offset = l.Offset
+	if last, _ := utf8.DecodeLastRuneInString(l.Input[:offset]); last == r && r != EOF {
+		if next, _ := utf8.DecodeRuneInString(l.Input[offset:]); next != r {
+			offset -= utf8.RuneLen(r)
+		}
+	}
+	var str = string(r)
+	if r == EOF {
+		str = "end of file"
+	}
+	panic(NewCompileError(fmt.Sprintf(msg, str), l.File, l.Input, offset, nil))
 }
 
 func lexCommentLine(l *Lexer, emit bool) stateFn {
@@ -66,7 +81,7 @@ func lexCommentLine(l *Lexer, emit bool) stateFn {
 
 	var r = l.next()
 	for r != '\n' && r != EOF {
-		l.next()
+		r = l.next()
 	}
 	l.backup()
 	if emit {
@@ -127,7 +142,7 @@ func lexCommentBlock(l *Lexer, emit bool) stateFn {
 		}
 		r = l.next()
 	}
-	l.error("Expecting comment end mark '*/'.", r)
+	l.error("Expecting comment end mark '*/', got %s.", r)
 	return nil
 }
 
@@ -197,7 +212,11 @@ func lexString(l *Lexer) stateFn {
 	return nil
 }
 
-func lexUrl(l *Lexer) {
+/*
+Lex the string or the url() of the rule like `@import` or `@use`, the rule is
+named in the error.
+*/
+func lexUrl(l *Lexer, rule string) {
 	if l.match("url") {
 		l.emit(ast.T_IDENT)
 		l.match("(")
@@ -217,7 +236,7 @@ func lexUrl(l *Lexer) {
 		if r == '"' || r == '\'' {
 			lexString(l)
 		} else {
-			l.error("Unexpected token for @import rule. Got %s", r)
+			l.error("Unexpected token for "+rule+" rule. Got %s", r)
 		}
 	}
 }
@@ -258,22 +277,17 @@ func lexAtRule(l *Lexer) stateFn {
 		return nil
 	}
 	l.next()
-	if l.match("import ") {
+	if l.match("import-once ") {
 
-		l.emit(ast.T_IMPORT)
+		l.emit(ast.T_IMPORT_ONCE)
 		l.ignoreSpaces()
+		return lexImportUrl(l, "@import-once")
 
-		lexUrl(l)
-		l.ignoreSpaces()
+	} else if l.match("import ") {
 
-		// looks like a media list
-		for unicode.IsLetter(l.peek()) {
-			l.next()
-		}
-		if l.precedeStartOffset() {
-			l.emit(ast.T_MEDIA)
-		}
-		return lexStatement
+		l.emit(ast.T_IMPORT)
+		l.ignoreSpaces()
+		return lexImportUrl(l, "@import")
 
 	} else if l.match("media") {
 
@@ -292,15 +306,116 @@ func lexAtRule(l *Lexer) stateFn {
 
 	} else if l.match("mixin") {
 
-		panic("@mixin is not supported yet.")
+		l.emit(ast.T_MIXIN)
+		l.ignoreSpaces()
+		lexMixinSignature(l, "mixin")
+		return lexStatement
 
 	} else if l.match("include") {
 
-		panic("@include is not supported yet")
+		l.emit(ast.T_INCLUDE)
+		l.ignoreSpaces()
+		lexMixinSignature(l, "mixin")
+
+		l.ignoreSpaces()
+		if l.match("using") {
+			l.emit(ast.T_USING)
+			l.ignoreSpaces()
+			lexFunctionParams(l)
+		}
+		return lexStatement
+
+	} else if l.match("content") {
+
+		l.emit(ast.T_CONTENT)
+		l.ignoreSpaces()
+		if l.peek() == '(' {
+			lexFunctionParams(l)
+		}
+		return lexStatement
+
+	} else if l.matchKeyword("if") {
+
+		l.emit(ast.T_IF)
+		lexControlExpression(l, nil)
+		return lexStatement
+
+	} else if l.matchKeyword("else") {
+
+		l.emit(ast.T_ELSE)
+		l.ignoreSpaces()
+		if l.matchKeyword("if") {
+			l.emit(ast.T_IF)
+			lexControlExpression(l, nil)
+		}
+		return lexStatement
+
+	} else if l.matchKeyword("each") {
+
+		l.emit(ast.T_EACH)
+		lexControlExpression(l, eachTokenMap)
+		return lexStatement
+
+	} else if l.matchKeyword("for") {
+
+		l.emit(ast.T_FOR)
+		lexControlExpression(l, forTokenMap)
+		return lexStatement
+
+	} else if l.matchKeyword("while") {
+
+		l.emit(ast.T_WHILE)
+		lexControlExpression(l, nil)
+		return lexStatement
 
 	} else if l.match("function") {
 
-		panic("@function is not supported yet")
+		l.emit(ast.T_FUNCTION)
+		l.ignoreSpaces()
+		lexMixinSignature(l, "function")
+		return lexStatement
+
+	} else if l.matchKeyword("extend") {
+
+		l.emit(ast.T_EXTEND)
+		lexExtendSelectors(l)
+		return lexStatement
+
+	} else if l.matchKeyword("use") {
+
+		l.emit(ast.T_USE)
+		lexModuleRule(l, "@use")
+		return lexStatement
+
+	} else if l.matchKeyword("forward") {
+
+		l.emit(ast.T_FORWARD)
+		lexModuleRule(l, "@forward")
+		return lexStatement
+
+	} else if l.match("return") {
+
+		l.emit(ast.T_RETURN)
+		var r = l.peek()
+		for r != ';' && r != '}' && r != EOF {
+			if lexExpression(l) == nil {
+				break
+			}
+			r = l.peek()
+		}
+		return lexStatement
+
+	} else if l.matchKeyword("warn") {
+
+		l.emit(ast.T_WARN)
+		var r = l.peek()
+		for r != ';' && r != '}' && r != EOF {
+			if lexExpression(l) == nil {
+				break
+			}
+			r = l.peek()
+		}
+		return lexStatement
 
 	} else {
 
@@ -316,6 +431,197 @@ func lexAtRule(l *Lexer) stateFn {
 	return nil
 }
 
+var eachTokenMap = KeywordTokenMap{
+	"in": ast.T_IN,
+}
+
+var forTokenMap = KeywordTokenMap{
+	"from":    ast.T_FROM,
+	"through": ast.T_THROUGH,
+	"to":      ast.T_TO,
+}
+
+/*
+Lex the expression of the control directives until the block starts, the
+keywords like `in` and `through` are emitted as their tokens:
+
+	@if $a == 1 {
+	@each $key, $value in $map {
+	@for $i from 1 through $n {
+*/
+func lexControlExpression(l *Lexer, keywords KeywordTokenMap) stateFn {
+	for {
+		l.ignoreSpaces()
+		if keywords != nil && l.matchKeywordMap(keywords) {
+			continue
+		}
+		if lexExpression(l) == nil {
+			break
+		}
+	}
+	return nil
+}
+
+/*
+Lex the url of @import and @import-once, and the media list after the url.
+*/
+func lexImportUrl(l *Lexer, rule string) stateFn {
+	lexUrl(l, rule)
+	l.ignoreSpaces()
+
+	// looks like a media list
+	for unicode.IsLetter(l.peek()) {
+		l.next()
+	}
+	if l.precedeStartOffset() {
+		l.emit(ast.T_MEDIA)
+	}
+	return lexStatement
+}
+
+/*
+Lex the url and the options of @use and @forward until the end of the
+statement, the namespace and the prefix are emitted as T_IDENT:
+
+	@use "src/corners" as c with ($radius: 3px);
+	@forward "src/list" as list-* hide list-reset, $horizontal-list-gap;
+*/
+func lexModuleRule(l *Lexer, rule string) stateFn {
+	l.ignoreSpaces()
+	lexUrl(l, rule)
+	for {
+		l.ignoreSpaces()
+		var r = l.peek()
+		if r == ';' || r == '}' || r == EOF {
+			return nil
+		}
+
+		if l.matchKeyword("as") {
+			l.emit(ast.T_AS)
+			l.ignoreSpaces()
+			// `as c`, `as *` or `as list-*`
+			r = l.next()
+			for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '*' {
+				r = l.next()
+			}
+			l.backup()
+			l.emit(ast.T_IDENT)
+		} else if l.matchKeyword("with") {
+			l.emit(ast.T_WITH)
+			l.ignoreSpaces()
+			lexFunctionParams(l)
+		} else if l.matchKeyword("show") {
+			l.emit(ast.T_SHOW)
+		} else if l.matchKeyword("hide") {
+			l.emit(ast.T_HIDE)
+		} else if r == ',' {
+			l.next()
+			l.emit(ast.T_COMMA)
+		} else if r == '$' {
+			lexVariableName(l)
+		} else if unicode.IsLetter(r) || r == '-' || r == '_' {
+			r = l.next()
+			for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+				r = l.next()
+			}
+			l.backup()
+			l.emit(ast.T_IDENT)
+		} else {
+			l.error("Unexpected token '%s' for the module rule.", r)
+		}
+	}
+}
+
+/*
+Lex the target selectors of @extend until the end of the statement, the
+`!optional` flag is emitted as T_OPTIONAL:
+
+	@extend .button;
+	@extend %message-shared !optional;
+*/
+func lexExtendSelectors(l *Lexer) stateFn {
+	for {
+		var spaces = l.ignoreSpaces()
+		var r = l.peek()
+		if r == ';' || r == '}' || r == EOF {
+			return nil
+		}
+		if l.match("!optional") {
+			l.emit(ast.T_OPTIONAL)
+			continue
+		}
+		if r == ',' {
+			l.next()
+			l.emit(ast.T_COMMA)
+			continue
+		}
+
+		// the space between the selectors is a descendant combinator, the
+		// parser rejects it since only compound selectors can be extended.
+		if tok := l.lastToken(); spaces > 0 && tok != nil && isSelector(tok.Type) && !isSelectorOperatorToken(r) {
+			l.emit(ast.T_DESCENDANT_SELECTOR)
+		}
+
+		switch {
+		case r == '.':
+			lexClassSelector(l)
+		case r == '#':
+			lexIdSelector(l)
+		case r == '%':
+			lexPlaceholderSelector(l)
+		case r == ':':
+			lexPseudoSelector(l)
+		case r == '[':
+			lexAttributeSelector(l)
+		case r == '*':
+			lexUniversalSelector(l)
+		case r == '&':
+			lexParentSelector(l)
+		case r == '>':
+			lexChildSelector(l)
+		case r == '+':
+			l.next()
+			l.emit(ast.T_ADJACENT_SELECTOR)
+		case unicode.IsLetter(r):
+			lexTypeSelector(l)
+		default:
+			l.error("Unexpected token '%s' for @extend selector.", r)
+			return nil
+		}
+	}
+}
+
+/*
+Lex the name and the optional argument list for @mixin, @include and
+@function:
+
+	@mixin button($color, $padding: 10px) { }
+	@include button(red);
+	@include clearfix;
+	@function rem($px) { }
+
+The kind is "mixin" or "function" for the error message.
+*/
+func lexMixinSignature(l *Lexer, kind string) stateFn {
+	var r = l.next()
+	if !unicode.IsLetter(r) && r != '-' && r != '_' {
+		l.error("Expecting "+kind+" name, got '%s'", r)
+	}
+	r = l.next()
+	// the namespaced mixin is separated by '.', e.g. `@include theme.button`
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || (r == '.' && isMemberNameStart(l.peek())) {
+		r = l.next()
+	}
+	l.backup()
+	l.emit(ast.T_IDENT)
+
+	l.ignoreSpaces()
+	if l.peek() == '(' {
+		lexFunctionParams(l)
+	}
+	return nil
+}
+
 func lexSpaces(l *Lexer) stateFn {
 	for {
 		var t = l.next()
@@ -329,9 +635,12 @@ func lexSpaces(l *Lexer) stateFn {
 
 func lexUnquoteStringStopAt(l *Lexer, stop rune) stateFn {
 	var r = l.next()
-	for r != stop {
+	for r != stop && r != EOF {
 		r = l.next()
 	}
+	if r == EOF {
+		l.error("Expecting '"+string(stop)+"', got %s", r)
+	}
 	l.backup()
 	l.emit(ast.T_UNQUOTE_STRING)
 	return nil
@@ -363,7 +672,9 @@ func lexVariableAssignment(l *Lexer) stateFn {
 	lexColon(l)
 	var r = l.peek()
 	for r != ';' && r != '}' && r != EOF {
-		lexExpression(l)
+		if lexExpression(l) == nil {
+			break
+		}
 		r = l.peek()
 	}
 	// l.backup()
@@ -388,7 +699,11 @@ func lexVariableName(l *Lexer) stateFn {
 	}
 
 	r = l.next()
-	if !unicode.IsLetter(r) {
+	// the private members of the module start with '-' or '_'
+	if r == '-' && (unicode.IsLetter(l.peek()) || l.peek() == '_') {
+		r = l.next()
+	}
+	if !unicode.IsLetter(r) && r != '_' {
 		l.error("The first character of a variable name must be letter. Got '%s'", r)
 	}
 
@@ -407,7 +722,7 @@ func lexVariableName(l *Lexer) stateFn {
 			}
 		} else if r == ':' {
 			break
-		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
 			break
 		} else if r == '}' {
 			l.backup()
@@ -526,7 +841,7 @@ func lexStatement(l *Lexer) stateFn {
 		l.emit(ast.T_BRACE_END)
 		return lexStatement
 
-	} else if r == '/' && (l.peek() == '*' || l.peek() == '/') {
+	} else if r == '/' && (l.peekBy(2) == '*' || l.peekBy(2) == '/') {
 
 		lexComment(l, true)
 
@@ -566,7 +881,7 @@ func lexStatement(l *Lexer) stateFn {
 			if r == '#' && l.peek() == '{' {
 				// find the matching brace
 				r = l.next()
-				for r != '}' {
+				for r != '}' && r != EOF {
 					r = l.next()
 				}
 			} else if r == '{' {
@@ -593,7 +908,7 @@ func lexStatement(l *Lexer) stateFn {
 			return lexProperty
 		}
 
-	} else if r == '[' || r == '*' || r == '>' || r == '&' || r == '#' || r == '.' || r == '+' || r == ':' {
+	} else if r == '[' || r == '*' || r == '>' || r == '&' || r == '#' || r == '.' || r == '+' || r == ':' || r == '%' {
 
 		return lexSelectors
 
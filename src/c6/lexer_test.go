@@ -86,6 +86,7 @@ func TestLexerTil(t *testing.T) {
 
 func TestLexerAtRuleImport(t *testing.T) {
 	AssertLexerTokenSequence(t, `@import "test.css";`, []ast.TokenType{ast.T_IMPORT, ast.T_QQ_STRING, ast.T_SEMICOLON})
+	AssertLexerTokenSequence(t, `@import-once "base";`, []ast.TokenType{ast.T_IMPORT_ONCE, ast.T_QQ_STRING, ast.T_SEMICOLON})
 }
 
 func TestLexerAtRuleImportWithUrl(t *testing.T) {
@@ -652,3 +653,202 @@ func TestLexerMediaQueryConditionWithExpressions(t *testing.T) {
 		})
 }
 */
+
+func TestLexerMixinStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@mixin button($color, $padding: 10px, $args...) { color: $color; }`,
+		[]ast.TokenType{ast.T_MIXIN, ast.T_IDENT,
+			ast.T_PAREN_START,
+			ast.T_VARIABLE, ast.T_COMMA,
+			ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_COMMA,
+			ast.T_VARIABLE, ast.T_ELLIPSIS,
+			ast.T_PAREN_END,
+			ast.T_BRACE_START,
+			ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_VARIABLE, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerIncludeStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { @include button(red, $padding: 5px); @include clearfix; }`,
+		[]ast.TokenType{ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+			ast.T_INCLUDE, ast.T_IDENT,
+			ast.T_PAREN_START, ast.T_IDENT, ast.T_COMMA, ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_PAREN_END,
+			ast.T_SEMICOLON,
+			ast.T_INCLUDE, ast.T_IDENT, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerIncludeStatementWithContentBlock(t *testing.T) {
+	AssertLexerTokenSequence(t, `@include media using ($type) { color: $type; }`,
+		[]ast.TokenType{ast.T_INCLUDE, ast.T_IDENT,
+			ast.T_USING, ast.T_PAREN_START, ast.T_VARIABLE, ast.T_PAREN_END,
+			ast.T_BRACE_START,
+			ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_VARIABLE, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerContentStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@mixin media { @content(screen); }`,
+		[]ast.TokenType{ast.T_MIXIN, ast.T_IDENT,
+			ast.T_BRACE_START,
+			ast.T_CONTENT, ast.T_PAREN_START, ast.T_IDENT, ast.T_PAREN_END, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerFunctionStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@function rem($px, $base: 16px) { @return $px / $base * 1rem; }`,
+		[]ast.TokenType{ast.T_FUNCTION, ast.T_IDENT,
+			ast.T_PAREN_START,
+			ast.T_VARIABLE, ast.T_COMMA,
+			ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX,
+			ast.T_PAREN_END,
+			ast.T_BRACE_START,
+			ast.T_RETURN, ast.T_VARIABLE, ast.T_DIV, ast.T_VARIABLE, ast.T_MUL, ast.T_INTEGER, ast.T_UNIT_REM, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerIfElseStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@if $a { $b: 1; } @else if $c { $b: 2; } @else { $b: 3; }`,
+		[]ast.TokenType{
+			ast.T_IF, ast.T_VARIABLE, ast.T_BRACE_START, ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_SEMICOLON, ast.T_BRACE_END,
+			ast.T_ELSE, ast.T_IF, ast.T_VARIABLE, ast.T_BRACE_START, ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_SEMICOLON, ast.T_BRACE_END,
+			ast.T_ELSE, ast.T_BRACE_START, ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_SEMICOLON, ast.T_BRACE_END,
+		})
+}
+
+func TestLexerEachStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@each $key, $value in (a: 1, b: 2) { }`,
+		[]ast.TokenType{
+			ast.T_EACH, ast.T_VARIABLE, ast.T_COMMA, ast.T_VARIABLE, ast.T_IN,
+			ast.T_PAREN_START, ast.T_IDENT, ast.T_COLON, ast.T_INTEGER, ast.T_COMMA, ast.T_IDENT, ast.T_COLON, ast.T_INTEGER, ast.T_PAREN_END,
+			ast.T_BRACE_START, ast.T_BRACE_END,
+		})
+}
+
+func TestLexerForStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@for $i from 1 through $n { } @for $i from 1 to 3 { }`,
+		[]ast.TokenType{
+			ast.T_FOR, ast.T_VARIABLE, ast.T_FROM, ast.T_INTEGER, ast.T_THROUGH, ast.T_VARIABLE, ast.T_BRACE_START, ast.T_BRACE_END,
+			ast.T_FOR, ast.T_VARIABLE, ast.T_FROM, ast.T_INTEGER, ast.T_TO, ast.T_INTEGER, ast.T_BRACE_START, ast.T_BRACE_END,
+		})
+}
+
+func TestLexerWhileStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@while $more { $more: false; }`,
+		[]ast.TokenType{
+			ast.T_WHILE, ast.T_VARIABLE, ast.T_BRACE_START,
+			ast.T_VARIABLE, ast.T_COLON, ast.T_FALSE, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerComparisonOperators(t *testing.T) {
+	AssertLexerTokenSequence(t, `@if $a == 1 and $b != 2 or not $c { } @while $i<3 or $i >= 10 { }`,
+		[]ast.TokenType{
+			ast.T_IF, ast.T_VARIABLE, ast.T_EQ, ast.T_INTEGER, ast.T_AND, ast.T_VARIABLE, ast.T_NE, ast.T_INTEGER,
+			ast.T_OR, ast.T_NOT, ast.T_VARIABLE, ast.T_BRACE_START, ast.T_BRACE_END,
+			ast.T_WHILE, ast.T_VARIABLE, ast.T_LT, ast.T_INTEGER, ast.T_OR, ast.T_VARIABLE, ast.T_GE, ast.T_INTEGER,
+			ast.T_BRACE_START, ast.T_BRACE_END,
+		})
+}
+
+func TestLexerPlaceholderSelector(t *testing.T) {
+	AssertLexerTokenSequence(t, `%message-1 { }`,
+		[]ast.TokenType{ast.T_PLACEHOLDER_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END})
+	AssertLexerTokenSequence(t, `a%button, .col-2 { }`,
+		[]ast.TokenType{ast.T_TYPE_SELECTOR, ast.T_PLACEHOLDER_SELECTOR, ast.T_COMMA, ast.T_CLASS_SELECTOR, ast.T_BRACE_START, ast.T_BRACE_END})
+}
+
+func TestLexerExtendStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { @extend a.b, %c !optional; }`,
+		[]ast.TokenType{
+			ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+			ast.T_EXTEND, ast.T_TYPE_SELECTOR, ast.T_CLASS_SELECTOR, ast.T_COMMA, ast.T_PLACEHOLDER_SELECTOR, ast.T_OPTIONAL, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerVariableAssignmentFlags(t *testing.T) {
+	AssertLexerTokenSequence(t, `$a: 1px !default; $b: red !global;`,
+		[]ast.TokenType{
+			ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_DEFAULT, ast.T_SEMICOLON,
+			ast.T_VARIABLE, ast.T_COLON, ast.T_IDENT, ast.T_GLOBAL, ast.T_SEMICOLON,
+		})
+}
+
+func TestLexerUseStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@use "src/corners" as c with ($radius: 3px);`,
+		[]ast.TokenType{
+			ast.T_USE, ast.T_QQ_STRING, ast.T_AS, ast.T_IDENT,
+			ast.T_WITH, ast.T_PAREN_START, ast.T_VARIABLE, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_PAREN_END, ast.T_SEMICOLON,
+		})
+}
+
+func TestLexerForwardStatement(t *testing.T) {
+	AssertLexerTokenSequence(t, `@forward "src/list" as list-* hide list-reset, $gap;`,
+		[]ast.TokenType{
+			ast.T_FORWARD, ast.T_QQ_STRING, ast.T_AS, ast.T_IDENT,
+			ast.T_HIDE, ast.T_IDENT, ast.T_COMMA, ast.T_VARIABLE, ast.T_SEMICOLON,
+		})
+}
+
+func TestLexerNamespacedMembers(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { width: math.div(c.$radius, 2); @include theme.button; }`,
+		[]ast.TokenType{
+			ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+			ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_FUNCTION_NAME, ast.T_PAREN_START, ast.T_VARIABLE, ast.T_COMMA, ast.T_INTEGER, ast.T_PAREN_END, ast.T_SEMICOLON,
+			ast.T_INCLUDE, ast.T_IDENT, ast.T_SEMICOLON,
+			ast.T_BRACE_END,
+		})
+}
+
+func TestLexerColumn(t *testing.T) {
+	l := NewLexerWithString("é\nab")
+	l.next()
+	assert.Equal(t, 0, l.Line)
+	assert.Equal(t, 1, l.Column)
+	assert.Equal(t, 2, l.Offset)
+
+	l.next()
+	assert.Equal(t, 1, l.Line)
+	assert.Equal(t, 0, l.Column)
+
+	l.backup()
+	assert.Equal(t, 0, l.Line)
+	assert.Equal(t, 1, l.Column)
+
+	l.next()
+	l.next()
+	assert.True(t, l.match("b"))
+	assert.Equal(t, 2, l.Column)
+	assert.False(t, l.match("c"))
+	assert.Equal(t, 2, l.Column)
+
+	l.seek(0)
+	assert.Equal(t, 0, l.Line)
+	assert.Equal(t, 0, l.Column)
+}
+
+func TestLexerTokenRange(t *testing.T) {
+	l := NewLexerWithString(".ü {\n  width: 10px;\n}")
+	l.File = "foo.scss"
+	l.run()
+	var tokens = AssertTokenSequence(t, l, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_START,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_SEMICOLON,
+		ast.T_BRACE_END,
+	})
+	l.close()
+
+	assert.Equal(t, "foo.scss", tokens[0].Range.File)
+	assert.Equal(t, "1:1-1:3", tokens[0].Range.String())
+	assert.Equal(t, "1:4-1:5", tokens[1].Range.String())
+	assert.Equal(t, "2:3-2:8", tokens[2].Range.String())
+	assert.Equal(t, 1, tokens[2].Line)
+	assert.Equal(t, "2:10-2:12", tokens[4].Range.String())
+	assert.Equal(t, ast.Position{Offset: 21, Line: 3, Column: 1}, tokens[7].Range.Start)
+}
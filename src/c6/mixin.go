@@ -0,0 +1,217 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "c6/ast"
+
+/*
+ContentBlock is the content block passed by `@include`, it's evaluated with
+the scope of the include statement when the mixin calls `@content`.
+*/
+type ContentBlock struct {
+	Block        *ast.DeclarationBlock
+	ArgumentList *ast.ArgumentList
+
+	// The context and the scope stack of the include statement
+	Context *Context
+	Scopes  []*Scope
+
+	// The content block of the mixin that contains the include statement
+	Parent *ContentBlock
+}
+
+/*
+Mixin is the declared mixin with the scope stack of the declaration, so the
+mixin body can access the variables of the enclosing scopes. The context is
+the module that declares the mixin.
+*/
+type Mixin struct {
+	Statement *ast.MixinStatement
+	Scopes    []*Scope
+	Context   *Context
+}
+
+func (context *Context) DefineMixin(mixin *ast.MixinStatement) {
+	context.Mixins[mixin.Name] = &Mixin{mixin, append([]*Scope{}, context.Scopes...), context}
+}
+
+/*
+LookupMixin finds the mixin by name, the namespaced mixin like `theme.button`
+is looked up from the module.
+*/
+func (context *Context) LookupMixin(name string) *Mixin {
+	var mixin *Mixin
+	if namespace, member := splitNamespace(name); namespace != "" {
+		mixin = context.LookupModule(namespace).Mixin(member)
+	} else if mixin = context.Mixins[name]; mixin == nil {
+		for _, module := range context.GlobalModules {
+			if mixin = module.Mixin(name); mixin != nil {
+				break
+			}
+		}
+	}
+	if mixin == nil {
+		panic(fmt.Errorf("Undefined mixin %s", name))
+	}
+	return mixin
+}
+
+/*
+IncludeMixin expands the mixin body into the output declaration block.
+
+The arguments are evaluated in the scope of the include statement, and the
+mixin body is evaluated in a new scope on top of the declaration scope.
+*/
+func (context *Context) IncludeMixin(include *ast.IncludeStatement, out *ast.DeclarationBlock) {
+	var mixin = context.LookupMixin(include.MixinName)
+	var args = context.evaluateCallArguments(include.Arguments)
+
+	var content *ContentBlock = nil
+	if include.ContentBlock != nil {
+		content = &ContentBlock{
+			Block:        include.ContentBlock,
+			ArgumentList: include.ContentArgumentList,
+			Context:      context,
+			Scopes:       append([]*Scope{}, context.Scopes...),
+			Parent:       context.Content,
+		}
+	}
+
+	// the mixin of another module is expanded with the context of its
+	// module, and the nested rulesets are resolved with the rulesets here.
+	mixin.Context.expandMixin(mixin, args, content, context.RuleSetStack, out)
+}
+
+func (context *Context) expandMixin(mixin *Mixin, args *callArguments, content *ContentBlock, ruleSets []*ast.RuleSet, out *ast.DeclarationBlock) {
+	context.enterCall()
+	defer context.leaveCall()
+
+	var stack, parentContent, parentRuleSets = context.Scopes, context.Content, context.RuleSetStack
+	context.Scopes = append([]*Scope{}, mixin.Scopes...)
+	context.Content = content
+	context.RuleSetStack = ruleSets
+
+	var scope = context.PushScope(false)
+	context.bindArguments("mixin "+mixin.Statement.Name, mixin.Statement.ArgumentList, args, scope.SymTable)
+	context.EvaluateDeclarations(mixin.Statement.Block, out)
+
+	context.Scopes, context.Content, context.RuleSetStack = stack, parentContent, parentRuleSets
+}
+
+/*
+IncludeContent evaluates the content block passed to the current mixin, the
+arguments of `@content(...)` are bound to the `using (...)` arguments.
+*/
+func (context *Context) IncludeContent(stm *ast.ContentStatement, out *ast.DeclarationBlock) {
+	var content = context.Content
+	if content == nil {
+		return
+	}
+
+	var args = context.evaluateCallArguments(stm.Arguments)
+	content.Context.expandContent(content, args, context.RuleSetStack, out)
+}
+
+func (context *Context) expandContent(content *ContentBlock, args *callArguments, ruleSets []*ast.RuleSet, out *ast.DeclarationBlock) {
+	context.enterCall()
+	defer context.leaveCall()
+
+	var stack, parentContent, parentRuleSets = context.Scopes, context.Content, context.RuleSetStack
+	context.Scopes = append([]*Scope{}, content.Scopes...)
+	context.Content = content.Parent
+	context.RuleSetStack = ruleSets
+
+	var scope = context.PushScope(false)
+	var argList = content.ArgumentList
+	if argList == nil {
+		argList = ast.NewArgumentList()
+	}
+	context.bindArguments("content block", argList, args, scope.SymTable)
+	context.EvaluateDeclarations(content.Block, out)
+
+	context.Scopes, context.Content, context.RuleSetStack = stack, parentContent, parentRuleSets
+}
+
+// The evaluated arguments of a call
+type callArguments struct {
+	Positional []ast.Expression
+	Keywords   map[string]ast.Expression
+}
+
+/*
+evaluateCallArguments evaluates the arguments in the current scope, the rest
+arguments are expanded into the positional arguments. The slash of the
+argument divides like `fn(12px/2)`.
+*/
+func (context *Context) evaluateCallArguments(exprs []ast.Expression) *callArguments {
+	var args = &callArguments{[]ast.Expression{}, map[string]ast.Expression{}}
+	for _, expr := range exprs {
+		switch t := expr.(type) {
+		case *ast.KeywordArgument:
+			if _, ok := args.Keywords[t.Name]; ok {
+				panic(fmt.Errorf("Duplicated keyword argument %s", t.Name))
+			}
+			args.Keywords[t.Name] = context.EvaluateDividedExpression(t.Value)
+		case *ast.RestArgument:
+			var val = context.EvaluateExpression(t.Value)
+			if list, ok := val.(*ast.List); ok {
+				args.Positional = append(args.Positional, list.Expressions...)
+			} else {
+				args.Positional = append(args.Positional, val)
+			}
+		default:
+			if len(args.Keywords) > 0 {
+				panic(fmt.Errorf("Positional arguments must come before keyword arguments"))
+			}
+			args.Positional = append(args.Positional, context.EvaluateDividedExpression(expr))
+		}
+	}
+	return args
+}
+
+/*
+bindArguments defines the arguments in the symbol table by the positional
+arguments, the keyword arguments and then the default values. The default
+values are evaluated in the new scope, so they can refer to the previous
+arguments.
+*/
+func (context *Context) bindArguments(callee string, argList *ast.ArgumentList, args *callArguments, symTable ast.SymTable) {
+	var keywords = map[string]ast.Expression{}
+	for name, val := range args.Keywords {
+		keywords[name] = val
+	}
+
+	var positional = args.Positional
+	for i, arg := range argList.Arguments {
+		var value ast.Expression
+		if arg.VariableLength {
+			var rest = ast.NewList()
+			rest.Separator = ", "
+			if i < len(positional) {
+				rest.Expressions = append(rest.Expressions, positional[i:]...)
+				positional = positional[:i]
+			}
+			value = rest
+		} else if i < len(positional) {
+			value = positional[i]
+		} else if val, ok := keywords[arg.Name]; ok {
+			value = val
+			delete(keywords, arg.Name)
+		} else if arg.DefaultValue != nil {
+			value = context.EvaluateExpression(arg.DefaultValue)
+		} else {
+			panic(fmt.Errorf("Missing argument %s for %s", arg.Name, callee))
+		}
+		symTable.AddVariable(&ast.Variable{Name: arg.Name, Value: value, Token: arg.Token})
+	}
+
+	if len(positional) > argList.Len() {
+		panic(fmt.Errorf("Only %d arguments allowed for %s, but %d were passed", argList.Len(), callee, len(positional)))
+	}
+	for name := range keywords {
+		panic(fmt.Errorf("No argument named %s for %s", name, callee))
+	}
+}
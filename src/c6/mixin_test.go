@@ -0,0 +1,80 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestMixinInclude(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin bordered { border: 1px solid black; }
+.a { color: red; @include bordered; }`, compiler.NewExpandedStyleCompiler())
+	assert.Equal(t, ".a {\n  color: red;\n  border: 1px solid black;\n}\n", out)
+}
+
+func TestMixinArguments(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin box($width, $height: $width, $padding: 2px) { width: $width; height: $height; padding: $padding + 1px; }
+.a { @include box(10px); }
+.b { @include box(10px, $padding: 4px); }
+.c { @include box($height: 5px, $width: 1px); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 10px; height: 10px; padding: 3px; }\n\n"+
+		".b { width: 10px; height: 10px; padding: 5px; }\n\n"+
+		".c { width: 1px; height: 5px; padding: 3px; }\n", out)
+}
+
+func TestMixinVariableArguments(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin shadows($name, $shadows...) { box-shadow: $shadows; }
+$list: 1px 1px red, 2px 2px blue;
+.a { @include shadows(a, 0 0 black, 1px 1px white); }
+.b { @include shadows(b, $list...); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { box-shadow: 0 0 black, 1px 1px white; }\n\n"+
+		".b { box-shadow: 1px 1px red, 2px 2px blue; }\n", out)
+
+	// the rest arguments are empty after the omitted default argument
+	out = RunCompilerTest(`
+@mixin pad($a, $b: 2px, $rest...) { padding: $a $b; }
+.a { @include pad(1px); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { padding: 1px 2px; }\n", out)
+
+	// the empty rest arguments can't be written in CSS
+	_, err := Compile([]byte(`
+@mixin shadows($shadows...) { box-shadow: $shadows; }
+.a { @include shadows; }`), Options{})
+	assert.Equal(t, "() isn't a valid CSS value", compileErrorMessage(t, err))
+}
+
+func TestMixinContentBlock(t *testing.T) {
+	var out = RunCompilerTest(`
+$color: red;
+@mixin wrap($color: blue) { color: $color; @content; }
+.a { @include wrap { background: $color; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: blue; background: red; }\n", out)
+}
+
+func TestMixinContentBlockUsing(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin sizes { @content(1px); @content(2px); }
+.a { @include sizes using ($size) { margin: $size; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { margin: 1px; margin: 2px; }\n", out)
+}
+
+func TestMixinNestedContentBlock(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin inner { @content; }
+@mixin outer { @include inner { @content; } }
+.a { @include outer { color: red; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: red; }\n", out)
+}
+
+func TestMixinErrors(t *testing.T) {
+	var cases = []string{
+		`.a { @include undefined; }`,
+		`@mixin m($a) { width: $a; } .a { @include m; }`,
+		`@mixin m($a) { width: $a; } .a { @include m(1px, 2px); }`,
+		`@mixin m($a) { width: $a; } .a { @include m($b: 1px); }`,
+	}
+	for _, code := range cases {
+		assert.Panics(t, func() { RunCompilerTest(code, compiler.NewCompactStyleCompiler()) }, code)
+	}
+}
@@ -0,0 +1,293 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "path"
+import "strings"
+import "c6/ast"
+
+/*
+Module is a stylesheet loaded by `@use` or `@forward`. The module is evaluated
+once per compilation with its own context, the variables, the mixins and the
+functions defined at its top level are the members of the module.
+*/
+type Module struct {
+	Url     string
+	Path    string
+	Context *Context
+
+	// The functions of the built-in module like `sass:math`
+	Builtins map[string]*customFunction
+}
+
+/*
+ModuleForward is the module forwarded by `@forward`, the members are
+forwarded with the prefix and filtered by `show` or `hide`.
+*/
+type ModuleForward struct {
+	Module    *Module
+	Statement *ast.ForwardStatement
+}
+
+/*
+DefaultNamespace returns the namespace of `@use` without `as`, it's the last
+component of the url without the extension and the leading underscore:
+
+	@use "src/_corners.scss";  // corners
+	@use "sass:math";          // math
+*/
+func DefaultNamespace(url string) string {
+	var name = path.Base(url)
+	if idx := strings.LastIndex(name, ":"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name = strings.TrimSuffix(name, path.Ext(name))
+	return strings.TrimPrefix(name, "_")
+}
+
+/*
+UseModule loads the module, and makes its members accessible with the
+namespace, or without the namespace for `as *`.
+*/
+func (context *Context) UseModule(stm *ast.UseStatement, out *ast.Block) {
+	// the configuration is evaluated in the scope of the @use rule
+	var configuration = map[string]ast.Expression{}
+	for _, arg := range stm.Configuration {
+		configuration[arg.Name] = context.EvaluateExpression(arg.Value)
+	}
+	var module = context.LoadModule(stm.Url, stm.Token, configuration, out)
+
+	var namespace = stm.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace(stm.Url)
+	}
+	if namespace == "*" {
+		context.GlobalModules = append(context.GlobalModules, module)
+		return
+	}
+	if _, ok := context.Namespaces[namespace]; ok {
+		panic(fmt.Errorf("There's already a module with namespace \"%s\"", namespace))
+	}
+	context.Namespaces[namespace] = module
+}
+
+/*
+ForwardModule loads the module, and exposes its members as the members of the
+current module. The configuration of the current module is passed to the
+forwarded module, so its `!default` variables can be configured through the
+forwarding module.
+*/
+func (context *Context) ForwardModule(stm *ast.ForwardStatement, out *ast.Block) {
+	var forward = &ModuleForward{Statement: stm}
+	var configuration = map[string]ast.Expression{}
+	for name, value := range context.Configuration {
+		if member, ok := forward.memberName(name); ok {
+			configuration[member] = value
+			delete(context.Configuration, name)
+		}
+	}
+	forward.Module = context.LoadModule(stm.Url, stm.Token, configuration, out)
+	context.Forwards = append(context.Forwards, forward)
+}
+
+/*
+LoadModule evaluates the module file with a new context, and the generated CSS
+is appended to the output block. The module that is already loaded in the
+compilation is returned without being evaluated again, and it can't be
+configured again. The token is the token of the statement that loads the
+module.
+*/
+func (context *Context) LoadModule(url string, token *ast.Token, configuration map[string]ast.Expression, out *ast.Block) *Module {
+	if strings.HasPrefix(url, "sass:") {
+		functions, ok := lookupBuiltinModule(url)
+		if !ok {
+			panic(fmt.Errorf("Unknown built-in module %s", url))
+		}
+		if len(configuration) > 0 {
+			panic(fmt.Errorf("Built-in module %s can't be configured", url))
+		}
+		return &Module{Url: url, Path: url, Context: NewContext(), Builtins: functions}
+	}
+
+	file, err := context.ResolveImportPath(url, context.CurrentFile())
+	if err != nil {
+		panic(err)
+	}
+
+	context.checkImportLoop(file, token)
+	if module, ok := context.Modules[file]; ok {
+		if len(configuration) > 0 {
+			panic(fmt.Errorf("%s was already loaded, so it can't be configured using \"with\"", url))
+		}
+		return module
+	}
+
+	block, err := NewParser(context).ParseFile(file)
+	if err != nil {
+		panic(err)
+	}
+
+	var module = &Module{Url: url, Path: file, Context: context.newModuleContext(file, token)}
+	module.Context.Configuration = configuration
+	context.Modules[file] = module
+	for _, stm := range block.Statements {
+		module.Context.EvaluateStatement(stm, out)
+	}
+
+	for name := range module.Context.Configuration {
+		panic(fmt.Errorf("%s was not declared with !default in the module %s", name, url))
+	}
+	module.Context.Configuration = nil
+	return module
+}
+
+/*
+newModuleContext creates the context for the module file, the import stack is
+inherited to detect the loops through the modules.
+*/
+func (context *Context) newModuleContext(file string, token *ast.Token) *Context {
+	var moduleContext = NewContext()
+	moduleContext.LoadPaths = context.LoadPaths
+	moduleContext.Importers = context.Importers
+	moduleContext.canonicalImporters = context.canonicalImporters
+	moduleContext.ImportOnce = context.ImportOnce
+	moduleContext.ImportedFiles = context.ImportedFiles
+	moduleContext.Modules = context.Modules
+	moduleContext.customFunctions = context.customFunctions
+	moduleContext.Warnings = context.Warnings
+	moduleContext.callDepth = context.callDepth
+	moduleContext.ImportStack = append([]*ImportFrame{}, context.ImportStack...)
+	moduleContext.pushImport(file, token)
+	return moduleContext
+}
+
+/*
+LookupModule returns the module used with the namespace.
+*/
+func (context *Context) LookupModule(namespace string) *Module {
+	if module, ok := context.Namespaces[namespace]; ok {
+		return module
+	}
+	panic(fmt.Errorf("There is no module with the namespace \"%s\"", namespace))
+}
+
+/*
+configuredValue takes the configured value of the `!default` variable, the
+configuration only applies to the variables at the top level of the module.
+*/
+func (context *Context) configuredValue(name string) (ast.Expression, bool) {
+	if len(context.Configuration) == 0 || !context.inSemiGlobalScope() {
+		return nil, false
+	}
+	value, ok := context.Configuration[name]
+	delete(context.Configuration, name)
+	return value, ok
+}
+
+// Variable returns the public variable of the module, the name starts with '$'
+func (module *Module) Variable(name string) *ast.Variable {
+	var context, member = module.resolveMember(name, func(context *Context, name string) bool {
+		return context.GlobalSymTable.FindVariable(name) != nil
+	})
+	if context == nil {
+		return nil
+	}
+	return context.GlobalSymTable.FindVariable(member)
+}
+
+// Mixin returns the public mixin of the module
+func (module *Module) Mixin(name string) *Mixin {
+	var context, member = module.resolveMember(name, func(context *Context, name string) bool {
+		return context.Mixins[name] != nil
+	})
+	if context == nil {
+		return nil
+	}
+	return context.Mixins[member]
+}
+
+// Function returns the public function of the module
+func (module *Module) Function(name string) *Function {
+	var context, member = module.resolveMember(name, func(context *Context, name string) bool {
+		return context.Functions[name] != nil
+	})
+	if context == nil {
+		return nil
+	}
+	return context.Functions[member]
+}
+
+/*
+resolveMember finds the context of the module that defines the member, and
+the name of the member in that module. The forwarded members are looked up
+with the prefix removed. The private members are not visible outside the
+module.
+*/
+func (module *Module) resolveMember(name string, defined func(*Context, string) bool) (*Context, string) {
+	if isPrivateMember(name) {
+		return nil, ""
+	}
+	if defined(module.Context, name) {
+		return module.Context, name
+	}
+	for _, forward := range module.Context.Forwards {
+		if member, ok := forward.memberName(name); ok {
+			if context, member := forward.Module.resolveMember(member, defined); context != nil {
+				return context, member
+			}
+		}
+	}
+	return nil, ""
+}
+
+/*
+memberName returns the name of the member in the forwarded module for the
+forwarded name, it reports false if the name is not forwarded.
+*/
+func (forward *ModuleForward) memberName(name string) (string, bool) {
+	var stm = forward.Statement
+	if stm.Show != nil && !containsName(stm.Show, name) {
+		return "", false
+	}
+	if containsName(stm.Hide, name) {
+		return "", false
+	}
+
+	var sigil = ""
+	if strings.HasPrefix(name, "$") {
+		sigil, name = "$", name[1:]
+	}
+	if !strings.HasPrefix(name, stm.Prefix) {
+		return "", false
+	}
+	return sigil + strings.TrimPrefix(name, stm.Prefix), true
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The members start with '-' or '_' are private
+func isPrivateMember(name string) bool {
+	name = strings.TrimPrefix(name, "$")
+	return strings.HasPrefix(name, "-") || strings.HasPrefix(name, "_")
+}
+
+/*
+splitNamespace splits the namespaced member name like `math.$pi` and
+`math.div`, the namespace is empty if the name is not namespaced.
+*/
+func splitNamespace(name string) (string, string) {
+	if idx := strings.Index(name, "."); idx > 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
+}
@@ -0,0 +1,137 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+
+func compileModuleFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, files)
+	block, err := NewContext().EvaluateFile(filepath.Join(dir, "main.scss"))
+	assert.Nil(t, err)
+	return compiler.NewCompactStyleCompiler().CompileBlock(block)
+}
+
+func TestUseNamespace(t *testing.T) {
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss": `@use "lib/corners"; @use "theme" as t;
+.a { radius: corners.$radius; width: corners.double(2px); @include t.box(1px); }`,
+		"lib/_corners.scss": `$radius: 3px; @function double($n) { @return $n * 2; }`,
+		"theme.scss":        `$pad: 5px; @mixin box($border) { padding: $pad; border: $border; }`,
+	})
+	assert.Equal(t, ".a { radius: 3px; width: 4px; padding: 5px; border: 1px; }\n", out)
+}
+
+func TestUseWithoutNamespace(t *testing.T) {
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss":  `@use "theme" as *; .a { color: $color; @include box; }`,
+		"theme.scss": `$color: red; @mixin box { padding: 1px; }`,
+	})
+	assert.Equal(t, ".a { color: red; padding: 1px; }\n", out)
+}
+
+func TestUseConfiguration(t *testing.T) {
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss":    `@use "library" with ($black: #222, $radius: 2px); .a { color: library.$black; radius: library.$radius; }`,
+		"library.scss": `$black: #000 !default; $radius: 1px !default; $white: #fff !default;`,
+	})
+	assert.Equal(t, ".a { color: #222; radius: 2px; }\n", out)
+
+	assert.Panics(t, func() {
+		compileModuleFiles(t, map[string]string{
+			"main.scss":    `@use "library" with ($black: #222);`,
+			"library.scss": `$black: #000;`,
+		})
+	})
+}
+
+func TestUseEvaluatedOnce(t *testing.T) {
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss":   `@use "a"; @use "b"; .main { color: a.$color; }`,
+		"a.scss":      `@use "shared"; $color: shared.$color;`,
+		"b.scss":      `@use "shared"; .b { color: shared.$color; }`,
+		"shared.scss": `$color: red; .shared { color: $color; }`,
+	})
+	assert.Equal(t, ".shared { color: red; }\n\n.b { color: red; }\n\n.main { color: red; }\n", out)
+}
+
+func TestUseModuleScope(t *testing.T) {
+	// the module can't see the variables of the module that uses it
+	assert.Panics(t, func() {
+		compileModuleFiles(t, map[string]string{
+			"main.scss":  `$color: red; @use "theme";`,
+			"theme.scss": `.a { color: $color; }`,
+		})
+	})
+
+	// the mixin refers to the members of its own module
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss":  `@use "theme"; $color: blue; .a { @include theme.text { b: $color; } .b { @include theme.text; } }`,
+		"theme.scss": `$color: red; @mixin text { color: $color; & > span { color: $color; } @content; }`,
+	})
+	assert.Equal(t, ".a { color: red; b: blue; }\n.a > span { color: red; }\n.a .b { color: red; }\n.a .b > span { color: red; }\n", out)
+}
+
+func TestUsePrivateMembers(t *testing.T) {
+	assert.Panics(t, func() {
+		compileModuleFiles(t, map[string]string{
+			"main.scss":  `@use "theme"; .a { color: theme.$-secret; }`,
+			"theme.scss": `$-secret: red;`,
+		})
+	})
+	assert.Panics(t, func() {
+		compileModuleFiles(t, map[string]string{
+			"main.scss":  `@use "theme"; .a { @include theme._hidden; }`,
+			"theme.scss": `@mixin _hidden { color: red; }`,
+		})
+	})
+}
+
+func TestForward(t *testing.T) {
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss":  `@use "index"; .a { gap: index.$list-gap; @include index.list-reset; }`,
+		"index.scss": `@forward "list" as list-* hide list-debug;`,
+		"list.scss":  `$gap: 1px; @mixin reset { margin: 0; } @mixin debug { color: red; }`,
+	})
+	assert.Equal(t, ".a { gap: 1px; margin: 0; }\n", out)
+
+	assert.Panics(t, func() {
+		compileModuleFiles(t, map[string]string{
+			"main.scss":  `@use "index"; .a { @include index.debug; }`,
+			"index.scss": `@forward "list" show $gap;`,
+			"list.scss":  `$gap: 1px; @mixin debug { color: red; }`,
+		})
+	})
+}
+
+func TestUseBuiltinModules(t *testing.T) {
+	var out = compileModuleFiles(t, map[string]string{
+		"main.scss": `@use "sass:math"; @use "sass:list" as *;
+$l: slash(1px, 2px, 3px);
+.a { width: math.div(12px, 2); font: math.div(12px, 1.5) slash(12px, 1.5); grid-area: $l; content: separator($l) separator(1 2); }`,
+	})
+	assert.Equal(t, ".a { width: 6px; font: 8px 12px/1.5; grid-area: 1px/2px/3px; content: slash space; }\n", out)
+
+	_, err := Compile([]byte(`@use "sass:math" with ($a: 1);`), Options{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Built-in module sass:math can't be configured")
+
+	_, err = Compile([]byte(`@use "sass:foo";`), Options{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Unknown built-in module sass:foo")
+}
+
+func TestUseAfterOtherRules(t *testing.T) {
+	_, err := Compile([]byte("@charset \"UTF-8\";\n$a: 1;\n@use \"sass:math\";\n.a { width: math.div(4px, $a); }"), Options{})
+	assert.Nil(t, err)
+
+	_, err = Compile([]byte(".a { color: red; }\n@use \"sass:math\";"), Options{})
+	assert.Equal(t, "{anonymous}:2:1: @use rules must be written before any other rules.\n@use \"sass:math\";\n^", err.Error())
+}
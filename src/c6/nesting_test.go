@@ -0,0 +1,91 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestNestedRuleSet(t *testing.T) {
+	var out = RunCompilerTest(`
+.nav {
+	color: red;
+	ul { margin: 0; li { display: inline; } }
+	> a { color: blue; }
+}`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".nav { color: red; }\n.nav ul { margin: 0; }\n.nav ul li { display: inline; }\n.nav > a { color: blue; }\n", out)
+}
+
+func TestNestedRuleSetNestedStyle(t *testing.T) {
+	var out = RunCompilerTest(`.a { color: red; .b { color: blue; } }`, compiler.NewNestedStyleCompiler())
+	assert.Equal(t, ".a {\n  color: red; }\n  .a .b {\n    color: blue; }\n", out)
+}
+
+func TestNestedParentSelector(t *testing.T) {
+	var out = RunCompilerTest(`
+.button {
+	&:hover { color: red; }
+	&.is-active { color: blue; }
+	&-item { color: green; }
+	&__icon { width: 1px; }
+	.theme-dark & { color: white; }
+	& + & { margin: 0; }
+}`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".button:hover { color: red; }\n"+
+		".button.is-active { color: blue; }\n"+
+		".button-item { color: green; }\n"+
+		".button__icon { width: 1px; }\n"+
+		".theme-dark .button { color: white; }\n"+
+		".button + .button { margin: 0; }\n", out)
+}
+
+func TestNestedSelectorListProduct(t *testing.T) {
+	var out = RunCompilerTest(`.a, .b { .c, &:hover { color: red; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a .c, .a:hover, .b .c, .b:hover { color: red; }\n", out)
+}
+
+func TestNestedMultipleParentSelectors(t *testing.T) {
+	var out = RunCompilerTest(`.a, .b { & + & { margin: 0; } & > &-x { color: red; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a + .a, .a + .b, .b + .a, .b + .b { margin: 0; }\n"+
+		".a > .a-x, .a > .b-x, .b > .a-x, .b > .b-x { color: red; }\n", out)
+}
+
+func TestNestedRuleSetInMixin(t *testing.T) {
+	var out = RunCompilerTest(`
+@mixin hover { &:hover { @content; } }
+.a { @include hover { color: red; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a:hover { color: red; }\n", out)
+}
+
+func TestNestedParentSelectorErrors(t *testing.T) {
+	assert.Panics(t, func() {
+		RunCompilerTest(`&.a { color: red; }`, compiler.NewCompactStyleCompiler())
+	})
+	assert.Panics(t, func() {
+		RunCompilerTest(`a:hover { &-item { color: red; } }`, compiler.NewCompactStyleCompiler())
+	})
+}
+
+func TestNestedExtend(t *testing.T) {
+	var out = RunCompilerTest(`
+%card { padding: 1px; }
+.list { .item { @extend %card; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".list .item { padding: 1px; }\n", out)
+}
+
+func TestNestedRuleSetSourceOrder(t *testing.T) {
+	// the nested rulesets see the variables assigned before them
+	var out = RunCompilerTest(`
+.x { $a: 1; .y { w: $a; } $a: 2; .z { w: $a; } }
+@if true { $i: 1; .w { w: $i; } $i: 5; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".x .y { w: 1; }\n.x .z { w: 2; }\n\n.w { w: 1; }\n", out)
+
+	// the rulesets of the mixin are written in the place of @include
+	out = RunCompilerTest(`
+@mixin m { .mid { c: 2; } }
+.x { .first { c: 1; } @include m; .last { c: 3; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".x .first { c: 1; }\n.x .mid { c: 2; }\n.x .last { c: 3; }\n", out)
+}
+
+func TestNestedAttributeSelectorDescendant(t *testing.T) {
+	var out = RunCompilerTest(`[data-x] .b { c: 1; } .a { [data-x] & { c: 2; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, "[data-x] .b { c: 1; }\n\n[data-x] .a { c: 2; }\n", out)
+}
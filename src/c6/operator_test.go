@@ -0,0 +1,134 @@
+package c6
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+import "c6/compiler"
+
+func TestOperatorArithmetic(t *testing.T) {
+	var out = RunCompilerTest(`$a: 10px;
+.a { width: $a * 2; height: 2 * $a; margin: $a % 3; padding: -5 % 3; top: $a / 4; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 20px; height: 20px; margin: 1px; padding: 1; top: 2.5px; }\n", out)
+}
+
+func TestOperatorColors(t *testing.T) {
+	var out = RunCompilerTest(`$c: #010203;
+.a { color: $c + #010101; background: 2 * $c; border-color: 1 - $c; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: #020304; background: #020406; border-color: 1-#010203; }\n", out)
+}
+
+func TestOperatorStrings(t *testing.T) {
+	var out = RunCompilerTest(`$s: "icon";
+.a { content: $s + -home; font-family: serif + $s; grid-area: a / b; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { content: \"icon-home\"; font-family: serificon; grid-area: a/b; }\n", out)
+}
+
+func TestOperatorUndefined(t *testing.T) {
+	_, err := Compile([]byte(`.a { color: 1px + #fff; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Undefined operation: 1px + #fff", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`$m: (a: 1); .a { width: $m * 2; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Undefined operation: (a: 1) * 2", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`.a { width: 10px + 2em; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Incompatible units: 'px' and 'em'", compileErrorMessage(t, err))
+}
+
+func TestOperatorUnitConversion(t *testing.T) {
+	var out = RunCompilerTest(`.a { width: 1in + 4px; height: 1cm + 10mm; transform: rotate(1turn - 90deg); transition-delay: 1s + 500ms; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 1.04167in; height: 2cm; transform: rotate(0.75turn); transition-delay: 1.5s; }\n", out)
+}
+
+func TestOperatorCompoundUnits(t *testing.T) {
+	var out = RunCompilerTest(`$speed: 10px / 1s;
+.a { width: $speed * 2s; height: 10px * 2px / 4px; margin: 4Q + 1mm; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 20px; height: 5px; margin: 8Q; }\n", out)
+
+	_, err := Compile([]byte(`.a { width: 10px * 2px; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "20px*px isn't a valid CSS value", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`$a: 2px * 3px / 1s; .a { width: $a + 1px; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Incompatible units: 'px*px/s' and 'px'", compileErrorMessage(t, err))
+}
+
+func TestOperatorComparison(t *testing.T) {
+	var out = RunCompilerTest(`.a { a: 1in == 96px; b: 1 == 1px; c: 2 < 3 and 3 >= 3; d: 10px > 1cm; e: 1 + 2 == 3; f: "a" == a; g: 1 < 2 == true; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: true; b: false; c: true; d: false; e: true; f: true; g: true; }\n", out)
+
+	_, err := Compile([]byte(`.a { width: 1px < 1em; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Incompatible units: 'px' and 'em'", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`.a { width: a < 1; }`), Options{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Undefined operation: a < 1", compileErrorMessage(t, err))
+}
+
+func TestOperatorBoolean(t *testing.T) {
+	var out = RunCompilerTest(`.a { a: not true; b: not 0; c: null or 5; d: false or null; e: 1 and 2; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: false; b: false; c: 5; e: 2; }\n", out)
+}
+
+func TestOperatorConditions(t *testing.T) {
+	var out = RunCompilerTest(`$x: null;
+.a { @if $x and $x > 1 { width: 1; } @else if not $x { width: 2; } }
+.b { $i: 0; @while $i < 3 { width: $i; $i: $i + 1; } }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 2; }\n\n.b { width: 0; width: 1; width: 2; }\n", out)
+}
+
+func TestOperatorSlashSeparator(t *testing.T) {
+	var out = RunCompilerTest(`$s: 12px; $l: 1.5;
+.a { font: 12px/1.5 sans-serif; grid-row: 1 / -1; aspect-ratio: 16 / 9; margin: 12px/2/3; font-size: #{$s}/#{$l}; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { font: 12px/1.5 sans-serif; grid-row: 1/-1; aspect-ratio: 16/9; margin: 12px/2/3; font-size: 12px/1.5; }\n", out)
+}
+
+func TestOperatorSlashDivision(t *testing.T) {
+	var out = RunCompilerTest(`$a: 12px; $b: 12px/2; $c: 12px/2 3;
+@function fn() { @return 12px; }
+@function half() { @return 12px/2; }
+@function id($n) { @return $n; }
+.a { a: $a/2; b: (12px/2); c: fn()/2; d: +12px/2; e: 1 + 12px/2; f: 12px/2 * 3; g: $b; h: $c; i: half(); j: id(12px/2); k: 1/2 == 0.5; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: 6px; b: 6px; c: 6px; d: 6px; e: 7px; f: 18px; g: 6px; h: 12px/2 3; i: 6px; j: 6px; k: true; }\n", out)
+
+	// the parenthesized operand divides
+	out = RunCompilerTest(`.a { a: (12px)/2; b: 12px/(2); c: (12px)/2/3; d: 12px/2/3; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: 6px; b: 6px; c: 2px; d: 12px/2/3; }\n", out)
+}
+
+func TestOperatorNullProperty(t *testing.T) {
+	var out = RunCompilerTest(`$m: null; $opt: null !default;
+.a { a: null; b: null or null; c: $m; d: $opt; width: 1px; }
+.b { a: null; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 1px; }\n", out)
+}
+
+func TestOperatorNullListItems(t *testing.T) {
+	var out = RunCompilerTest(`$m: null;
+.a { a: 1 null 2; b: null, 1px; c: null $m; d: (null null) 1; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: 1 2; b: 1px; d: 1; }\n", out)
+}
+
+func TestOperatorColorKeywords(t *testing.T) {
+	var out = RunCompilerTest(`.a { color: red + blue; a: red == #f00; b: red != #ff0000; content: red + -x; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: #ff00ff; a: true; b: false; content: red-x; }\n", out)
+}
+
+func TestOperatorDivisionByZero(t *testing.T) {
+	var out = RunCompilerTest(`.a { a: (1/0); b: (1px/0); c: (-1/0); d: (0/0); }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { a: Infinity; b: Infinitypx; c: -Infinity; d: NaN; }\n", out)
+}
+
+func TestOperatorPlainCssFunction(t *testing.T) {
+	var out = RunCompilerTest(`.a { color: #ddd + #111; y: foo() + 1; z: foo() - 1; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: #eeeeee; y: foo()1; z: foo()-1; }\n", out)
+
+	_, err := Compile([]byte(`.a { w: rgba(0, 0, 0, .5) * 2; }`), Options{})
+	assert.Equal(t, "Undefined operation: rgba(0, 0, 0, 0.5) * 2", compileErrorMessage(t, err))
+
+	_, err = Compile([]byte(`.a { w: foo() * 2; }`), Options{})
+	assert.Equal(t, "Undefined operation: foo() * 2", compileErrorMessage(t, err))
+}
@@ -6,21 +6,27 @@ package c6
 
 import "fmt"
 import "c6/ast"
-import "path/filepath"
-import "io/ioutil"
+import "runtime"
+
+// Syntax is the syntax of the stylesheet by its file type
+type Syntax uint
 
 const (
-	UnknownFileType = iota
+	UnknownFileType Syntax = iota
 	ScssFileType
 	SassFileType
+	CssFileType
 )
 
 type ParserError struct {
 	ExpectingToken string
 	ActualToken    string
+
+	// The actual token, the error is reported at its position
+	Token *ast.Token
 }
 
-const debugParser = true
+const debugParser = false
 
 func debug(format string, args ...interface{}) {
 	if debugParser {
@@ -32,12 +38,14 @@ func (e ParserError) Error() string {
 	return fmt.Sprintf("Expecting '%s', but the actual token we got was '%s'.", e.ExpectingToken, e.ActualToken)
 }
 
-func getFileTypeByExtension(extension string) uint {
+func getFileTypeByExtension(extension string) Syntax {
 	switch extension {
 	case "scss":
 		return ScssFileType
 	case "sass":
 		return SassFileType
+	case "css":
+		return CssFileType
 	}
 	return UnknownFileType
 }
@@ -50,27 +58,75 @@ type Parser struct {
 	Pos         int
 	RollbackPos int
 	Tokens      []*ast.Token
+
+	// The file being parsed, it's empty for the code that is not from a file
+	File string
+
+	lexer *Lexer
+
+	// The last token returned by next(), the errors are reported at it
+	last *ast.Token
+
+	// The errors found by the parser, the parser continues with the next
+	// statement after the error
+	Diagnostics []Diagnostic
+
+	// The code being parsed and its first error
+	code string
+	err  *CompileError
+}
+
+// lexerError is raised by the T_ERROR token of the lexer
+type lexerError struct {
+	Token *ast.Token
 }
 
 func NewParser(context *Context) *Parser {
-	return &Parser{context, nil, 0, 0, []*ast.Token{}}
+	return &Parser{Context: context, Tokens: []*ast.Token{}}
 }
 
-func (parser *Parser) ParseFile(path string) error {
-	ext := filepath.Ext(path)
-	filetype := getFileTypeByExtension(ext)
-	data, err := ioutil.ReadFile(path)
+/*
+ParseFile parses the file loaded by the importer chain of the context, the
+parsed ast.Block is cached until the contents of the file are modified.
+*/
+func (parser *Parser) ParseFile(path string) (*ast.Block, error) {
+	var context = parser.Context
+	if context == nil {
+		context = NewContext()
+	}
+	canonical, err := context.canonicalizeFile(path)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	code, syntax, err := context.LoadImport(canonical)
+	if err != nil {
+		return nil, err
+	}
+	// the file is included even if it fails to parse, the watcher tracks it
+	// to compile again when it's fixed
+	context.ImportedFiles[canonical] = true
+	if block := getCachedFileAst(canonical, code); block != nil {
+		parser.File = canonical
+		return block, nil
 	}
 
-	var code string = string(data)
-	switch filetype {
-	case ScssFileType:
-		parser.ParseScss(code)
-		break
+	var block *ast.Block
+	parser.File = canonical
+	switch syntax {
+	case ScssFileType, CssFileType:
+		// the plain CSS is valid SCSS
+		block, err = parser.ParseScss(code)
+	case SassFileType:
+		block, err = parser.ParseSass(code)
+	default:
+		return nil, fmt.Errorf("Unsupported file type: %s", canonical)
 	}
-	return nil
+	if err != nil {
+		// the partial block is not cached
+		return block, err
+	}
+	cacheFileAst(canonical, code, block)
+	return block, nil
 }
 
 func (self *Parser) backup() {
@@ -91,7 +147,7 @@ func (self *Parser) rollback() {
 
 func (self *Parser) accept(tokenType ast.TokenType) *ast.Token {
 	var tok = self.next()
-	if tok.Type == tokenType {
+	if tok != nil && tok.Type == tokenType {
 		return tok
 	}
 	self.backup()
@@ -100,9 +156,12 @@ func (self *Parser) accept(tokenType ast.TokenType) *ast.Token {
 
 func (self *Parser) expect(tokenType ast.TokenType) *ast.Token {
 	var tok = self.next()
+	if tok == nil {
+		self.errorAt(nil, "Unexpected end of file")
+	}
 	if tok.Type != tokenType {
 		self.backup()
-		panic(fmt.Errorf("Expecting %s, Got %s", tokenType, tok))
+		panic(ParserError{tokenType.String(), tok.Str, tok})
 	}
 	return tok
 
@@ -113,7 +172,7 @@ func (self *Parser) acceptTypes(types []ast.TokenType) bool {
 	var match = true
 	for _, tokType := range types {
 		var tok = self.next()
-		if tok.Type != tokType {
+		if tok == nil || tok.Type != tokType {
 			match = false
 			break
 		}
@@ -126,23 +185,56 @@ func (self *Parser) acceptTypes(types []ast.TokenType) bool {
 }
 
 func (self *Parser) next() *ast.Token {
-	var p = self.Pos
+	var tok = self.fetch(self.Pos)
 	self.Pos++
-	if p < len(self.Tokens) {
-		return self.Tokens[p]
-	} else {
-		if len(self.Tokens) > 1 {
-			// get the last token
-			var tok = self.Tokens[len(self.Tokens)-1]
-			if tok == nil {
-				return nil
-			}
+	self.last = tok
+	if tok != nil && tok.Type == ast.T_ERROR {
+		panic(lexerError{tok})
+	}
+	return tok
+}
+
+/*
+fetch returns the token at the position without raising the errors of the
+lexer, the tokens are received from the lexer on demand. The comments are
+skipped since they are not rendered.
+*/
+func (self *Parser) fetch(p int) *ast.Token {
+	for p >= len(self.Tokens) {
+		if len(self.Tokens) > 0 && self.Tokens[len(self.Tokens)-1] == nil {
+			// the lexer is finished
+			return nil
 		}
 		token := <-self.Input
+		for token != nil && (token.Type == ast.T_COMMENT_LINE || token.Type == ast.T_COMMENT_BLOCK) {
+			token = <-self.Input
+		}
 		self.Tokens = append(self.Tokens, token)
-		return token
 	}
-	return nil
+	return self.Tokens[p]
+}
+
+/*
+setRange sets the range of the parsed node from the token at the start
+position to the last consumed token, it's deferred by the parse functions:
+
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+*/
+func (parser *Parser) setRange(node ast.Node, start int) {
+	var end = parser.Pos - 1
+	if start > end || end >= len(parser.Tokens) {
+		return
+	}
+	var first, last = parser.Tokens[start], parser.Tokens[end]
+	if first == nil || last == nil {
+		return
+	}
+	ast.SetNodeRange(node, tokensRange(first, last))
+}
+
+// tokensRange returns the range from the first token to the last token
+func tokensRange(first *ast.Token, last *ast.Token) ast.Range {
+	return ast.Range{File: first.Range.File, Start: first.Range.Start, End: last.Range.End}
 }
 
 func (self *Parser) peekBy(offset int) *ast.Token {
@@ -166,20 +258,56 @@ func (self *Parser) current() *ast.Token {
 }
 
 func (self *Parser) peek() *ast.Token {
-	if self.Pos < len(self.Tokens) {
-		return self.Tokens[self.Pos]
+	var tok = self.fetch(self.Pos)
+	if tok != nil && tok.Type == ast.T_ERROR {
+		self.last = tok
+		panic(lexerError{tok})
 	}
-	token := <-self.Input
-	self.Tokens = append(self.Tokens, token)
-	return token
+	return tok
+}
+
+/*
+nextToken returns the next token where the rule requires one, the end of file
+is raised as the error.
+*/
+func (parser *Parser) nextToken() *ast.Token {
+	var tok = parser.next()
+	if tok == nil {
+		parser.errorAt(nil, "Unexpected end of file")
+	}
+	return tok
+}
+
+/*
+peekToken returns the next token without consuming it where the rule requires
+one, the end of file is raised as the error.
+*/
+func (parser *Parser) peekToken() *ast.Token {
+	var tok = parser.peek()
+	if tok == nil {
+		parser.errorAt(nil, "Unexpected end of file")
+	}
+	return tok
+}
+
+/*
+peekIs checks the type of the next token, it's false at the end of file.
+*/
+func (parser *Parser) peekIs(tokenType ast.TokenType) bool {
+	var tok = parser.peek()
+	return tok != nil && tok.Type == tokenType
 }
 
 func (self *Parser) isSelector() bool {
 	var tok = self.peek()
+	if tok == nil {
+		return false
+	}
 	if tok.Type == ast.T_ID_SELECTOR ||
 		tok.Type == ast.T_TYPE_SELECTOR ||
 		tok.Type == ast.T_CLASS_SELECTOR ||
 		tok.Type == ast.T_PSEUDO_SELECTOR ||
+		tok.Type == ast.T_PLACEHOLDER_SELECTOR ||
 		tok.Type == ast.T_PARENT_SELECTOR {
 		return true
 	} else if tok.Type == ast.T_BRACKET_LEFT {
@@ -194,17 +322,184 @@ func (self *Parser) eof() bool {
 	return tok == nil
 }
 
-func (parser *Parser) ParseScss(code string) *ast.Block {
+/*
+ParseScss parses the SCSS code. The parser recovers from the errors at the
+end of the statements and collects them in Diagnostics, the partial block is
+returned with the first error as *CompileError.
+*/
+func (parser *Parser) ParseScss(code string) (*ast.Block, error) {
 	l := NewLexerWithString(code)
-	l.run()
+	l.File = parser.File
+	parser.lexer = l
 	parser.Input = l.getOutput()
+	parser.code = code
+	parser.err = nil
+
+	// the token channel is buffered, lex the code in another goroutine so
+	// that the large input won't block the lexer.
+	go l.run()
+
+	var block = &ast.Block{}
+	for parser.fetch(parser.Pos) != nil {
+		parser.recoverStatement(true, func() {
+			var pos = parser.Pos
+			stm := parser.ParseStatement(nil)
+			if use, ok := stm.(*ast.UseStatement); ok && !onlyModuleRules(block.Statements) {
+				parser.errorAt(use.Token, "@use rules must be written before any other rules.")
+			}
+			if stm != nil {
+				block.AppendStatement(stm)
+			} else if parser.Pos == pos {
+				// reported at the token by next()
+				panic(fmt.Errorf("Unexpected token '%s'", parser.next().Str))
+			}
+		})
+	}
+	if parser.err != nil {
+		return block, parser.err
+	}
+	return block, nil
+}
+
+/*
+onlyModuleRules checks if the statements can be followed by `@use`, only
+`@charset`, `@use`, `@forward` and the variable declarations are allowed
+before it.
+*/
+func onlyModuleRules(stms []ast.Statement) bool {
+	for _, stm := range stms {
+		switch stm.(type) {
+		case *ast.CharsetStatement, *ast.UseStatement, *ast.ForwardStatement, *ast.VariableAssignment:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+/*
+recoverStatement runs the parse function of the statement. The error is
+collected as the diagnostic, and the tokens are skipped to the end of the
+statement, so the parsing continues with the next statement.
+*/
+func (parser *Parser) recoverStatement(topLevel bool, parse func()) {
+	var start = parser.Pos
+	defer func() {
+		if r := recover(); r != nil {
+			var err = parser.compileError(r)
+			if parser.err == nil {
+				parser.err = err
+			}
+			// the error of the lexer is raised again by the enclosing
+			// statement at the end of file, it's reported once
+			if n := len(parser.Diagnostics); n == 0 || parser.Diagnostics[n-1].Range.Start != err.Range.Start {
+				parser.Diagnostics = append(parser.Diagnostics, NewErrorDiagnostic(err))
+			}
 
-	block := ast.Block{}
-	for !parser.eof() {
-		stm := parser.ParseStatement(nil)
-		if stm != nil {
-			block.AppendStatement(stm)
+			var errTok = parser.last
+			if err.Token != nil {
+				errTok = err.Token
+			}
+			parser.skipStatement(start, errTok, topLevel)
 		}
+	}()
+	parse()
+}
+
+/*
+skipStatement skips the tokens from the error token to the next `;` or the
+`}` of the statement, the blocks in between are skipped as a whole. The `}`
+of the enclosing block is kept unless it's at the top level.
+*/
+func (parser *Parser) skipStatement(start int, errTok *ast.Token, topLevel bool) {
+	var pos = start
+	for i := start; i < len(parser.Tokens); i++ {
+		if parser.Tokens[i] == errTok {
+			pos = i
+			break
+		}
+	}
+
+	var depth = 0
+	for tok := parser.fetch(pos); tok != nil; tok = parser.fetch(pos) {
+		if tok.Type == ast.T_ERROR {
+			// the lexer has skipped the statement of its error, and the next
+			// error is reported by the next statement
+			if tok == errTok {
+				pos++
+				if next := parser.fetch(pos); next != nil && next.Type == ast.T_SEMICOLON {
+					pos++
+				}
+			}
+			break
+		}
+		if tok.Type == ast.T_BRACE_START {
+			depth++
+		} else if tok.Type == ast.T_BRACE_END {
+			if depth == 0 {
+				if topLevel {
+					pos++
+				}
+				break
+			}
+			depth--
+			if depth == 0 {
+				pos++
+				break
+			}
+		} else if tok.Type == ast.T_SEMICOLON && depth == 0 {
+			pos++
+			break
+		}
+		pos++
+	}
+	parser.Pos = pos
+}
+
+/*
+errorAt raises the error at the token, or at the end of the code when the
+token is nil.
+*/
+func (parser *Parser) errorAt(tok *ast.Token, format string, args ...interface{}) {
+	var message = fmt.Sprintf(format, args...)
+	if tok == nil {
+		panic(NewCompileError(message, parser.File, parser.code, len(parser.code), nil))
+	}
+	panic(NewCompileError(message, parser.File, parser.code, tok.Pos, tok))
+}
+
+/*
+tokenString returns the quoted string of the token for the error messages.
+*/
+func tokenString(tok *ast.Token) string {
+	if tok == nil {
+		return "end of file"
+	}
+	return "'" + tok.Str + "'"
+}
+
+/*
+compileError converts the recovered panic of the parser to *CompileError, it's
+reported at the last token returned by next().
+*/
+func (parser *Parser) compileError(r interface{}) *CompileError {
+	var code = parser.code
+	if err, ok := r.(*CompileError); ok {
+		return err
+	}
+	if err, ok := r.(lexerError); ok {
+		return NewCompileError(err.Token.Str, parser.File, code, err.Token.Pos, nil)
+	}
+	if err, ok := r.(ParserError); ok && err.Token != nil {
+		return NewCompileError(err.Error(), parser.File, code, err.Token.Pos, err.Token)
+	}
+	// the runtime errors are the bugs of the parser, not of the code
+	if _, ok := r.(runtime.Error); ok {
+		panic(r)
+	}
+	var message = panicMessage(r)
+	if parser.last == nil {
+		return NewCompileError(message, parser.File, code, len(code), nil)
 	}
-	return &block
+	return NewCompileError(message, parser.File, code, parser.last.Pos, parser.last)
 }
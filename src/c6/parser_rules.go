@@ -6,75 +6,161 @@ package c6
 
 import "fmt"
 import "strconv"
+import "strings"
 import "c6/ast"
 
 func (parser *Parser) ParseStatement(parentRuleSet *ast.RuleSet) ast.Statement {
 	var token = parser.peek()
 
-	if token.Type == ast.T_IMPORT {
+	if token.Type == ast.T_IMPORT || token.Type == ast.T_IMPORT_ONCE {
 		return parser.ParseImportStatement()
+	} else if token.Type == ast.T_CHARSET {
+		return parser.ParseCharsetStatement()
 	} else if token.Type == ast.T_VARIABLE {
 		return parser.ParseVariableAssignment()
-	} else if token.IsSelector() {
+	} else if token.Type == ast.T_MIXIN {
+		return parser.ParseMixinStatement()
+	} else if token.Type == ast.T_INCLUDE {
+		return parser.ParseIncludeStatement()
+	} else if token.Type == ast.T_FUNCTION {
+		return parser.ParseFunctionStatement()
+	} else if token.Type == ast.T_IF {
+		return parser.ParseIfStatement()
+	} else if token.Type == ast.T_EACH {
+		return parser.ParseEachStatement()
+	} else if token.Type == ast.T_FOR {
+		return parser.ParseForStatement()
+	} else if token.Type == ast.T_WHILE {
+		return parser.ParseWhileStatement()
+	} else if token.Type == ast.T_MEDIA {
+		return parser.ParseMediaStatement()
+	} else if token.Type == ast.T_USE {
+		return parser.ParseUseStatement()
+	} else if token.Type == ast.T_FORWARD {
+		return parser.ParseForwardStatement()
+	} else if token.Type == ast.T_WARN {
+		return parser.ParseWarnStatement()
+	} else if token.IsSelector() || token.Type == ast.T_BRACKET_LEFT {
 		return parser.ParseRuleSet(parentRuleSet)
 	}
 	return nil
 }
 
-func (parser *Parser) ParseRuleSet(parentRuleSet *ast.RuleSet) ast.Statement {
-	var ruleset = ast.RuleSet{}
+func (parser *Parser) ParseRuleSet(parentRuleSet *ast.RuleSet) (parsed ast.Statement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var ruleset = ast.NewRuleSet()
+
+	// the selector list is separated by ',', each item is a complex selector
+	// ranged from its first token to the last one
+	var complex = ast.NewComplexSelector()
+	var first, last *ast.Token
 	var tok = parser.next()
 
-	for tok.IsSelector() {
+	for tok != nil && (tok.IsSelector() || tok.Type == ast.T_GT || tok.Type == ast.T_COMMA || tok.Type == ast.T_BRACKET_LEFT) {
 
 		switch tok.Type {
 
 		case ast.T_TYPE_SELECTOR:
-			sel := ast.TypeSelector{tok.Str}
-			ruleset.AppendSelector(sel)
+			complex.AppendSelector(ast.NewTypeSelector(tok))
 
 		case ast.T_UNIVERSAL_SELECTOR:
 			sel := ast.UniversalSelector{}
-			ruleset.AppendSelector(sel)
+			complex.AppendSelector(sel)
 
 		case ast.T_ID_SELECTOR:
-			sel := ast.IdSelector{tok.Str}
-			ruleset.AppendSelector(sel)
+			complex.AppendSelector(ast.NewIdSelector(tok))
 
 		case ast.T_CLASS_SELECTOR:
-			sel := ast.ClassSelector{tok.Str}
-			ruleset.AppendSelector(sel)
+			complex.AppendSelector(ast.NewClassSelector(tok))
 
 		case ast.T_PARENT_SELECTOR:
-			sel := ast.ParentSelector{parentRuleSet}
-			ruleset.AppendSelector(sel)
+			complex.AppendSelector(ast.NewParentSelector(parentRuleSet, tok))
 
 		case ast.T_PSEUDO_SELECTOR:
-			sel := ast.PseudoSelector{tok.Str, ""}
-			if nextTok := parser.peek(); nextTok.Type == ast.T_LANG_CODE {
+			sel := ast.PseudoSelector{PseudoClass: tok.Str}
+			if nextTok := parser.accept(ast.T_LANG_CODE); nextTok != nil {
 				sel.C = nextTok.Str
 			}
-			ruleset.AppendSelector(sel)
+			complex.AppendSelector(sel)
+
+		case ast.T_PLACEHOLDER_SELECTOR:
+			complex.AppendSelector(ast.NewPlaceholderSelector(tok))
+
+		case ast.T_BRACKET_LEFT:
+			parser.backup()
+			complex.AppendSelector(parser.ParseAttributeSelector())
 
 		case ast.T_ADJACENT_SELECTOR:
-			ruleset.AppendSelector(ast.AdjacentSelector{})
-		case ast.T_CHILD_SELECTOR:
-			ruleset.AppendSelector(ast.ChildSelector{})
+			complex.AppendSelector(ast.AdjacentSelector{})
+		case ast.T_CHILD_SELECTOR, ast.T_GT:
+			complex.AppendSelector(ast.ChildSelector{})
 		case ast.T_DESCENDANT_SELECTOR:
-			ruleset.AppendSelector(ast.DescendantSelector{})
+			complex.AppendSelector(ast.DescendantSelector{})
+
+		case ast.T_COMMA:
+			if first != nil {
+				complex.SetRange(tokensRange(first, last))
+			}
+			ruleset.AppendSelector(complex)
+			complex = ast.NewComplexSelector()
+			first = nil
+
 		default:
-			panic(fmt.Errorf("Unexpected selector token: %+v", tok))
+			parser.errorAt(tok, "Unexpected selector token: %s", tokenString(tok))
+		}
+		if tok.Type != ast.T_COMMA {
+			if first == nil {
+				first = tok
+			}
+			last = parser.Tokens[parser.Pos-1]
 		}
 		tok = parser.next()
 	}
 	parser.backup()
 
+	if complex.Len() > 0 {
+		complex.SetRange(tokensRange(first, last))
+		ruleset.AppendSelector(complex)
+	}
+
 	// parse declaration block
-	ruleset.DeclarationBlock = parser.ParseDeclarationBlock(&ruleset)
-	return &ruleset
+	ruleset.DeclarationBlock = parser.ParseDeclarationBlock(ruleset)
+	return ruleset
 }
 
-func (parser *Parser) ParseNumber() ast.Expression {
+/*
+Parse attribute selector like `[href]`, `[lang|=en]` or `[type="text"]`
+*/
+func (parser *Parser) ParseAttributeSelector() ast.Selector {
+	var start = parser.expect(ast.T_BRACKET_LEFT)
+	var nameTok = parser.expect(ast.T_ATTRIBUTE_NAME)
+	var sel = ast.AttributeSelector{Name: nameTok.Str}
+
+	var tok = parser.nextToken()
+	if tok.Type == ast.T_EQUAL || tok.Type == ast.T_TILDE_EQUAL || tok.Type == ast.T_PIPE_EQUAL {
+		sel.Op = tok.Str
+		tok = parser.nextToken()
+		switch tok.Type {
+		case ast.T_QQ_STRING:
+			sel.Pattern = "\"" + tok.Str + "\""
+		case ast.T_Q_STRING:
+			sel.Pattern = "'" + tok.Str + "'"
+		default:
+			sel.Pattern = tok.Str
+		}
+		tok = parser.nextToken()
+	}
+	if tok.Type != ast.T_BRACKET_RIGHT {
+		panic(ParserError{"]", tok.Str, tok})
+	}
+	sel.SetRange(tokensRange(start, tok))
+	return sel
+}
+
+func (parser *Parser) ParseNumber() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var pos = parser.Pos
 	debug("ParseNumber at %d", parser.Pos)
 
@@ -84,13 +170,17 @@ func (parser *Parser) ParseNumber() ast.Expression {
 
 	var negative = false
 
-	if tok.Type == ast.T_MINUS {
+	if tok != nil && tok.Type == ast.T_MINUS {
 		tok = parser.next()
 		negative = true
-	} else if tok.Type == ast.T_PLUS {
+	} else if tok != nil && tok.Type == ast.T_PLUS {
 		tok = parser.next()
 		negative = false
 	}
+	if tok == nil {
+		parser.restore(pos)
+		return nil
+	}
 
 	var val float64
 	var tok2 = parser.peek()
@@ -123,7 +213,7 @@ func (parser *Parser) ParseNumber() ast.Expression {
 		return nil
 	}
 
-	if tok2.IsOneOfTypes([]ast.TokenType{ast.T_UNIT_PX, ast.T_UNIT_PT, ast.T_UNIT_CM, ast.T_UNIT_EM, ast.T_UNIT_MM, ast.T_UNIT_REM, ast.T_UNIT_DEG, ast.T_UNIT_PERCENT}) {
+	if tok2 != nil && tok2.IsUnit() {
 		// consume the unit token
 		parser.next()
 		return ast.NewLength(val, ast.ConvertTokenTypeToUnitType(tok2.Type), tok)
@@ -131,34 +221,73 @@ func (parser *Parser) ParseNumber() ast.Expression {
 	return ast.NewNumber(val, tok)
 }
 
-func (parser *Parser) ParseFunctionCall() *ast.FunctionCall {
+func (parser *Parser) ParseFunctionCall() (parsed *ast.FunctionCall) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var identTok = parser.next()
 
 	debug("ParseFunctionCall => next: %s", identTok)
 
 	var fcall = ast.NewFunctionCall(identTok)
 
-	parser.expect(ast.T_PAREN_START)
-
-	var argTok = parser.peek()
-	for argTok.Type != ast.T_PAREN_END {
-		var arg = parser.ParseFactor()
+	for _, arg := range parser.ParseCallArguments() {
 		fcall.AppendArgument(arg)
 		debug("ParseFunctionCall => arg: %+v", arg)
+	}
+	return fcall
+}
 
-		argTok = parser.peek()
-		if argTok.Type == ast.T_COMMA {
-			parser.next() // skip comma
-			argTok = parser.peek()
-		} else if argTok.Type == ast.T_PAREN_END {
-			parser.next() // consume ')'
+/*
+Parse the arguments of a function call or a mixin inclusion, the argument
+can be an expression, a keyword argument or a rest argument:
+
+	(10px, $padding: 5px, $shadows...)
+*/
+func (parser *Parser) ParseCallArguments() []ast.Expression {
+	var args = []ast.Expression{}
+	parser.expect(ast.T_PAREN_START)
+
+	var tok = parser.peekToken()
+	for tok.Type != ast.T_PAREN_END {
+		var pos = parser.Pos
+
+		// keyword argument `$name: value`
+		if varTok := parser.accept(ast.T_VARIABLE); varTok != nil {
+			if parser.accept(ast.T_COLON) != nil {
+				var value = parser.ParseSpaceSepList()
+				if value == nil {
+					panic(fmt.Errorf("Expecting value for the keyword argument %s", varTok.Str))
+				}
+				args = append(args, ast.NewKeywordArgument(varTok.Str, value))
+			} else {
+				parser.restore(pos)
+			}
+		}
+
+		if parser.Pos == pos {
+			var arg = parser.ParseSpaceSepList()
+			if arg == nil {
+				var tok = parser.peek()
+				parser.errorAt(tok, "Unexpected token in the argument list: %s", tokenString(tok))
+			}
+			if parser.accept(ast.T_ELLIPSIS) != nil {
+				arg = ast.NewRestArgument(arg)
+			}
+			args = append(args, arg)
+		}
+
+		if parser.accept(ast.T_COMMA) == nil {
 			break
 		}
+		tok = parser.peekToken()
 	}
-	return fcall
+	parser.expect(ast.T_PAREN_END)
+	return args
 }
 
-func (parser *Parser) ParseIdent() *ast.Ident {
+func (parser *Parser) ParseIdent() (parsed *ast.Ident) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var tok = parser.next()
 	debug("ReduceIndent => next: %s", tok)
 	if tok.Type != ast.T_IDENT {
@@ -170,16 +299,19 @@ func (parser *Parser) ParseIdent() *ast.Ident {
 /**
 The ParseFactor must return an Expression interface compatible object
 */
-func (parser *Parser) ParseFactor() ast.Expression {
+func (parser *Parser) ParseFactor() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseFactor at %d", parser.Pos)
 	var tok = parser.peek()
 	debug("ParseFactor => peek: %s", tok)
+	if tok == nil {
+		return nil
+	}
 
 	if tok.Type == ast.T_PAREN_START {
-		parser.expect(ast.T_PAREN_START)
-		var expr = parser.ParseExpression(true)
-		parser.expect(ast.T_PAREN_END)
-		return expr
+
+		return parser.ParseParenthesis()
 
 	} else if tok.Type == ast.T_INTERPOLATION_START {
 
@@ -202,21 +334,35 @@ func (parser *Parser) ParseFactor() ast.Expression {
 		tok = parser.next()
 		return ast.Expression(ast.NewString(tok))
 
+	} else if tok.Type == ast.T_TRUE || tok.Type == ast.T_FALSE {
+
+		tok = parser.next()
+		return ast.Expression(ast.NewBoolean(tok.Type == ast.T_TRUE, tok))
+
+	} else if tok.Type == ast.T_NULL {
+
+		tok = parser.next()
+		return ast.Expression(ast.NewNull(tok))
+
 	} else if tok.Type == ast.T_HEX_COLOR {
 
 		parser.next()
 		return ast.Expression(ast.NewHexColorFromToken(tok))
 
-	} else if tok.Type == ast.T_INTEGER || tok.Type == ast.T_FLOAT {
+	} else if tok.Type == ast.T_INTEGER || tok.Type == ast.T_FLOAT || parser.isNegativeNumber() {
 
 		// reduce number
 		var number = parser.ParseNumber()
 		return ast.Expression(number)
 
+	} else if tok.Type == ast.T_VARIABLE {
+
+		return parser.ParseVariable()
+
 	} else if tok.Type == ast.T_FUNCTION_NAME {
 
 		var fcall = parser.ParseFunctionCall()
-		return ast.Expression(*fcall)
+		return ast.Expression(fcall)
 
 	} else {
 
@@ -225,59 +371,153 @@ func (parser *Parser) ParseFactor() ast.Expression {
 	return nil
 }
 
-func (parser *Parser) ParseTerm() ast.Expression {
+/*
+isNegativeNumber reports whether the minus sign is attached to the number,
+the negative number is a literal like the `-1` of `grid-row: 1 / -1`, while
+`- 1` is the unary minus.
+*/
+func (parser *Parser) isNegativeNumber() bool {
+	var tok = parser.peek()
+	if tok == nil || tok.Type != ast.T_MINUS {
+		return false
+	}
+	var num = parser.fetch(parser.Pos + 1)
+	return num != nil && (num.Type == ast.T_INTEGER || num.Type == ast.T_FLOAT) && tok.Pos+len(tok.Str) == num.Pos
+}
+
+func (parser *Parser) ParseTerm() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseTerm at %d", parser.Pos)
 	var pos = parser.Pos
+	var grouped = parser.peekIs(ast.T_PAREN_START)
 	var factor = parser.ParseFactor()
 	if factor == nil {
 		parser.restore(pos)
 		return nil
 	}
+	return parser.parseTermOperators(factor, grouped)
+}
 
-	// see if the next token is '*' or '/'
+/*
+parseTermOperators parses the '*', '/' and '%' operations after the factor,
+grouped is true if the factor is in the parenthesis. The slash next to the
+parenthesized operand divides: `(12px)/2` and `12px/(2)` are 6px.
+*/
+func (parser *Parser) parseTermOperators(factor ast.Expression, grouped bool) ast.Expression {
+	// the operators are left associative
 	var tok = parser.peek()
-	if tok.Type == ast.T_MUL || tok.Type == ast.T_DIV {
+	for tok != nil && (tok.Type == ast.T_MUL || tok.Type == ast.T_DIV || tok.Type == ast.T_MOD) {
 		parser.next()
-		if term := parser.ParseTerm(); term != nil {
-			if tok.Type == ast.T_MUL {
-				return ast.NewBinaryExpression(ast.OpMul, factor, term, false)
-			} else if tok.Type == ast.T_DIV {
-				return ast.NewBinaryExpression(ast.OpDiv, factor, term, false)
-			}
-		} else {
-			panic("Unexpected token after * and /")
+		var rightGrouped = parser.peekIs(ast.T_PAREN_START)
+		var right = parser.ParseFactor()
+		if right == nil {
+			panic("Unexpected token after * / and %")
 		}
+		var op = ast.ConvertTokenTypeToOpType(tok.Type)
+		factor = ast.NewBinaryExpression(op, factor, right, op == ast.OpDiv && (grouped || rightGrouped))
+		grouped = false
+		tok = parser.peek()
 	}
 	return factor
 }
 
+/*
+The precedence of the boolean and the comparison operators, the arithmetic
+operators bind tighter than them.
+*/
+var binaryOperatorPrecedence = map[ast.TokenType]int{
+	ast.T_OR:  1,
+	ast.T_AND: 2,
+	ast.T_EQ:  3,
+	ast.T_NE:  3,
+	ast.T_LT:  4,
+	ast.T_LE:  4,
+	ast.T_GT:  4,
+	ast.T_GE:  4,
+}
+
 /**
 
 We here treat the property values as expressions:
 
 	padding: {expression} {expression} {expression};
 	margin: {expression};
+	@if $a + 1 > 2 and not $b { }
 
 */
-func (parser *Parser) ParseExpression(inParenthesis bool) ast.Expression {
-	var pos = parser.Pos
+func (parser *Parser) ParseExpression(inParenthesis bool) (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseExpression")
+	var expr = parser.ParseArithmeticExpression(inParenthesis)
+	if expr == nil {
+		return nil
+	}
+	return parser.parseBinaryOperators(expr, 1, inParenthesis)
+}
+
+/*
+parseBinaryOperators climbs the precedence of the boolean and the comparison
+operators, the operators of the same precedence are left associative:
+
+	$a or $b and $c    // $a or ($b and $c)
+	1 < 2 == true      // (1 < 2) == true
+*/
+func (parser *Parser) parseBinaryOperators(left ast.Expression, minPrecedence int, inParenthesis bool) ast.Expression {
+	for {
+		var tok = parser.peek()
+		if tok == nil {
+			return left
+		}
+		var precedence, ok = binaryOperatorPrecedence[tok.Type]
+		if !ok || precedence < minPrecedence {
+			return left
+		}
+		parser.next()
+
+		var right = parser.ParseArithmeticExpression(inParenthesis)
+		if right == nil {
+			var next = parser.peek()
+			parser.errorAt(next, "Expecting expression after '%s', got %s", tok.Str, tokenString(next))
+		}
+		// the operators of the higher precedence on the right are reduced first
+		for next := parser.peek(); next != nil && binaryOperatorPrecedence[next.Type] > precedence; next = parser.peek() {
+			right = parser.parseBinaryOperators(right, precedence+1, inParenthesis)
+		}
+		left = ast.NewBinaryExpression(ast.ConvertTokenTypeToOpType(tok.Type), left, right, inParenthesis)
+	}
+}
+
+/*
+ParseArithmeticExpression parses the operators `+ - * / %` and the unary
+operators `+ - not`.
+*/
+func (parser *Parser) ParseArithmeticExpression(inParenthesis bool) (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
 
-	// plus or minus. This creates an unary expression that holds the later term.
-	// this is for:  +3 or -4
+	var pos = parser.Pos
+
+	// plus, minus or not. This creates an unary expression that holds the later factor,
+	// and the factor is the operand of the term: -5 % 3 is (-5) % 3. The minus
+	// sign attached to the number is parsed as the negative number.
 	var tok = parser.peek()
+	if tok == nil {
+		return nil
+	}
 	var expr ast.Expression
-	if tok.Type == ast.T_PLUS || tok.Type == ast.T_MINUS {
+	if tok.Type == ast.T_PLUS || (tok.Type == ast.T_MINUS && !parser.isNegativeNumber()) || tok.Type == ast.T_NOT {
 		parser.next()
-		if term := parser.ParseTerm(); term != nil {
-			expr = ast.NewUnaryExpression(ast.ConvertTokenTypeToOpType(tok.Type), term)
+		var grouped = parser.peekIs(ast.T_PAREN_START)
+		if factor := parser.ParseFactor(); factor != nil {
+			var uexpr = ast.NewUnaryExpression(ast.ConvertTokenTypeToOpType(tok.Type), factor)
+			expr = uexpr
 
-			if uexpr, ok := expr.(*ast.UnaryExpression); ok {
-				// if it's evaluatable just return the evaluated value.
-				if val := uexpr.Evaluate(nil); val != nil {
-					expr = ast.Expression(val)
-				}
+			// if it's evaluatable just return the evaluated value.
+			if val := uexpr.Evaluate(nil); val != nil {
+				expr = ast.Expression(val)
 			}
+			expr = parser.parseTermOperators(expr, grouped)
 
 		} else {
 			parser.restore(pos)
@@ -288,13 +528,13 @@ func (parser *Parser) ParseExpression(inParenthesis bool) ast.Expression {
 	}
 
 	if expr == nil {
-		debug("ParseExpression failed, got %+v, restoring to %d", expr, pos)
+		debug("ParseArithmeticExpression failed, got %+v, restoring to %d", expr, pos)
 		parser.restore(pos)
 		return nil
 	}
 
 	var rightTok = parser.peek()
-	for rightTok.Type == ast.T_PLUS || rightTok.Type == ast.T_MINUS || rightTok.Type == ast.T_LITERAL_CONCAT {
+	for rightTok != nil && (rightTok.Type == ast.T_PLUS || rightTok.Type == ast.T_MINUS || rightTok.Type == ast.T_LITERAL_CONCAT) {
 		// accept plus or minus
 		parser.next()
 
@@ -318,44 +558,91 @@ func (parser *Parser) ParseExpression(inParenthesis bool) ast.Expression {
 	return expr
 }
 
-func (parser *Parser) ParseMap() ast.Expression {
+func (parser *Parser) ParseMap() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var pos = parser.Pos
-	var tok = parser.next()
 	// since it's not started with '(', it's not map
-	if tok.Type != ast.T_PAREN_START {
-		parser.restore(pos)
+	if !parser.peekIs(ast.T_PAREN_START) {
 		return nil
 	}
+	if m, ok := parser.ParseParenthesis().(*ast.Map); ok {
+		return m
+	}
+	parser.restore(pos)
+	return nil
+}
 
-	tok = parser.peek()
-	for tok.Type != ast.T_PAREN_END {
-		var keyExpr = parser.ParseExpression(false)
-		if keyExpr == nil {
-			parser.restore(pos)
-			return nil
-		}
+/*
+ParseParenthesis parses the expression, the list or the map in the
+parenthesis:
 
-		if parser.accept(ast.T_COLON) == nil {
-			parser.restore(pos)
-			return nil
-		}
+	(10px + 2px) * 3
+	(1px, 2px, 3px)
+	(primary: #333, secondary: #666)
+*/
+func (parser *Parser) ParseParenthesis() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
 
-		var valueExpr = parser.ParseExpression(false)
-		if valueExpr == nil {
-			parser.restore(pos)
-			return nil
-		}
+	parser.expect(ast.T_PAREN_START)
 
-		tok = parser.peek()
-		if tok.Type == ast.T_COMMA {
-			parser.next()
-			tok = parser.peek()
+	// empty list
+	if parser.accept(ast.T_PAREN_END) != nil {
+		return ast.NewList()
+	}
+
+	var first = parser.ParseSpaceSepList()
+	if first == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Unexpected token in parenthesis: %s", tokenString(tok))
+	}
+
+	var expr = first
+	if parser.accept(ast.T_COLON) != nil {
+		var m = ast.NewMap()
+		var key = first
+		for {
+			var value = parser.ParseSpaceSepList()
+			if value == nil {
+				panic(fmt.Errorf("Expecting map value for the key %s", key))
+			}
+			m.Set(key, value)
+
+			// the trailing comma is allowed
+			if parser.accept(ast.T_COMMA) == nil || parser.peekIs(ast.T_PAREN_END) {
+				break
+			}
+			if key = parser.ParseSpaceSepList(); key == nil {
+				var tok = parser.peek()
+				parser.errorAt(tok, "Expecting map key, got %s", tokenString(tok))
+			}
+			parser.expect(ast.T_COLON)
 		}
+		expr = m
+	} else if parser.peekIs(ast.T_COMMA) {
+		var list = ast.NewList()
+		list.Separator = ", "
+		list.Append(first)
+		for parser.accept(ast.T_COMMA) != nil && !parser.peekIs(ast.T_PAREN_END) {
+			var item = parser.ParseSpaceSepList()
+			if item == nil {
+				var tok = parser.peek()
+				parser.errorAt(tok, "Unexpected token in list: %s", tokenString(tok))
+			}
+			list.Append(item)
+		}
+		expr = list
+	} else if bexpr, ok := expr.(*ast.BinaryExpression); ok {
+		bexpr.Grouped = true
 	}
-	return nil
+
+	parser.expect(ast.T_PAREN_END)
+	return expr
 }
 
-func (parser *Parser) ParseString() ast.Expression {
+func (parser *Parser) ParseString() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var tok = parser.peek()
 
 	if tok.Type == ast.T_QQ_STRING {
@@ -383,7 +670,9 @@ func (parser *Parser) ParseString() ast.Expression {
 	return nil
 }
 
-func (parser *Parser) ParseInterp() ast.Expression {
+func (parser *Parser) ParseInterp() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseInterp at %d", parser.Pos)
 	var startTok = parser.peek()
 
@@ -402,15 +691,16 @@ func (parser *Parser) ParseInterp() ast.Expression {
 The stop token is used from variable assignment expression,
  we expect ';' semicolon at the end of expression to avoid the ambiguity of list, map and expression.
 */
-func (parser *Parser) ParseValue(stopTokType ast.TokenType) ast.Expression {
+func (parser *Parser) ParseValue(stopTokType ast.TokenType) (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseValue")
 	var pos = parser.Pos
 
 	// try parse map
 	debug("Trying Map")
 	if mapValue := parser.ParseMap(); mapValue != nil {
-		var tok = parser.peek()
-		if stopTokType == 0 || tok.Type == stopTokType {
+		if stopTokType == 0 || parser.peekIs(stopTokType) {
 			debug("OK List")
 			return mapValue
 		}
@@ -420,8 +710,7 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) ast.Expression {
 
 	debug("Trying List")
 	if listValue := parser.ParseList(); listValue != nil {
-		var tok = parser.peek()
-		if stopTokType == 0 || tok.Type == stopTokType {
+		if stopTokType == 0 || parser.peekIs(stopTokType) {
 			debug("OK List: %+v", listValue)
 			return listValue
 		}
@@ -432,23 +721,21 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) ast.Expression {
 	debug("ParseExpression trying", pos)
 
 	if expr := parser.ParseExpression(false); expr != nil {
-		var tok = parser.peek()
-		for tok.Type == ast.T_LITERAL_CONCAT {
-			parser.accept(ast.T_LITERAL_CONCAT)
-
+		for parser.accept(ast.T_LITERAL_CONCAT) != nil {
 			var rightExpr = parser.ParseExpression(false)
 			if rightExpr == nil {
 				panic("Expecting expression or ident after the literal concat operator.")
 			}
 			expr = ast.NewLiteralConcat(expr, rightExpr)
-			tok = parser.peek()
 		}
 		return expr
 	}
 	return nil
 }
 
-func (parser *Parser) ParseList() ast.Expression {
+func (parser *Parser) ParseList() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseList at %d", parser.Pos)
 	var pos = parser.Pos
 	var list = parser.ParseCommaSepList()
@@ -460,33 +747,23 @@ func (parser *Parser) ParseList() ast.Expression {
 	return list
 }
 
-func (parser *Parser) ParseCommaSepList() ast.Expression {
+func (parser *Parser) ParseCommaSepList() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseCommaSepList at %d", parser.Pos)
 	var list = ast.NewList()
 	list.Separator = ", "
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_COMMA && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
-
-		// when the syntax start with a '(', it could be a list or map.
-		if tok.Type == ast.T_PAREN_START {
-
-			parser.next()
-			if sublist := parser.ParseCommaSepList(); sublist != nil {
-				debug("Appending sublist %+v", list)
-				list.Append(sublist)
-			}
-			// allow empty list here
-			parser.expect(ast.T_PAREN_END)
+	for tok != nil && tok.Type != ast.T_COMMA && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
 
+		// the parenthesized list or expression is parsed by ParseFactor
+		var sublist = parser.ParseSpaceSepList()
+		if sublist != nil {
+			debug("Appending sublist %+v", list)
+			list.Append(sublist)
 		} else {
-			var sublist = parser.ParseSpaceSepList()
-			if sublist != nil {
-				debug("Appending sublist %+v", list)
-				list.Append(sublist)
-			} else {
-				break
-			}
+			break
 		}
 
 		if parser.accept(ast.T_COMMA) == nil {
@@ -509,7 +786,9 @@ func (parser *Parser) ParseCommaSepList() ast.Expression {
 	return list
 }
 
-func (parser *Parser) ParseVariable() *ast.Variable {
+func (parser *Parser) ParseVariable() (parsed *ast.Variable) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var pos = parser.Pos
 	var tok = parser.next()
 	if tok.Type != ast.T_VARIABLE {
@@ -519,7 +798,9 @@ func (parser *Parser) ParseVariable() *ast.Variable {
 	return ast.NewVariable(tok)
 }
 
-func (parser *Parser) ParseVariableAssignment() ast.Statement {
+func (parser *Parser) ParseVariableAssignment() (parsed ast.Statement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var pos = parser.Pos
 
 	var variable = parser.ParseVariable()
@@ -533,36 +814,41 @@ func (parser *Parser) ParseVariableAssignment() ast.Statement {
 		panic("Expecting colon after variable name")
 	}
 
-	// Expecting semicolon at the end of the statement
-	var expr = parser.ParseValue(ast.T_SEMICOLON)
+	// the value is followed by the optional flags and the semicolon
+	var expr = parser.ParseValue(0)
 	if expr == nil {
 		panic("Expecting value after variable assignment.")
 	}
 
-	parser.expect(ast.T_SEMICOLON)
+	var assignment = ast.NewVariableAssignment(variable, expr)
+	for tok := parser.peek(); tok != nil && (tok.Type == ast.T_DEFAULT || tok.Type == ast.T_GLOBAL); tok = parser.peek() {
+		parser.next()
+		if tok.Type == ast.T_DEFAULT {
+			assignment.Default = true
+		} else {
+			assignment.Global = true
+		}
+	}
 
-	// Reduce list or map here
-	return ast.NewVariableAssignment(variable, expr)
+	// the semicolon of the last declaration is optional
+	if parser.accept(ast.T_SEMICOLON) == nil {
+		if tok := parser.peek(); tok != nil && tok.Type != ast.T_BRACE_END {
+			panic(ParserError{";", tok.Str, tok})
+		}
+	}
+	return assignment
 }
 
-func (parser *Parser) ParseSpaceSepList() ast.Expression {
+func (parser *Parser) ParseSpaceSepList() (parsed ast.Expression) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParseSpaceSepList at %d", parser.Pos)
 
 	var list = ast.NewList()
 	list.Separator = " "
 
 	var tok = parser.peek()
-
-	if tok.Type == ast.T_PAREN_START {
-		parser.next()
-		if sublist := parser.ParseCommaSepList(); sublist != nil {
-			list.Append(sublist)
-		}
-		parser.expect(ast.T_PAREN_END)
-	}
-
-	tok = parser.peek()
-	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
+	for tok != nil && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
 		var subexpr = parser.ParseExpression(true)
 		if subexpr != nil {
 			debug("Parsed Expression: %+v", subexpr)
@@ -571,7 +857,7 @@ func (parser *Parser) ParseSpaceSepList() ast.Expression {
 			break
 		}
 		tok = parser.peek()
-		if tok.Type == ast.T_COMMA {
+		if tok != nil && tok.Type == ast.T_COMMA {
 			break
 		}
 	}
@@ -589,13 +875,15 @@ func (parser *Parser) ParseSpaceSepList() ast.Expression {
 /**
 We treat the property value section as a list value, which is separated by ',' or ' '
 */
-func (parser *Parser) ParsePropertyValue(parentRuleSet *ast.RuleSet, property *ast.Property) *ast.List {
+func (parser *Parser) ParsePropertyValue(parentRuleSet *ast.RuleSet, property *ast.Property) (parsed *ast.List) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	debug("ParsePropertyValue")
 	// var tok = parser.peek()
 	var list = ast.NewList()
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
+	for tok != nil && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
 		var sublist = parser.ParseList()
 		if sublist != nil {
 			list.Append(sublist)
@@ -606,57 +894,488 @@ func (parser *Parser) ParsePropertyValue(parentRuleSet *ast.RuleSet, property *a
 		tok = parser.peek()
 	}
 
+	// the semicolon of the last declaration is optional, and the '}' belongs to
+	// the declaration block.
 	tok = parser.peek()
-	if tok.Type == ast.T_SEMICOLON || tok.Type == ast.T_BRACE_END {
+	if list.Len() == 0 {
+		parser.errorAt(tok, "Expecting value for the property %s. Got %s", property.Name.String, tokenString(tok))
+	}
+	if tok != nil && tok.Type == ast.T_SEMICOLON {
 		parser.next()
-	} else {
-		panic(fmt.Errorf("Unexpected end of property value. Got %s", tok))
+	} else if tok == nil || tok.Type != ast.T_BRACE_END {
+		parser.errorAt(tok, "Unexpected end of property value. Got %s", tokenString(tok))
 	}
 	return list
 }
 
-func (parser *Parser) ParseDeclarationBlock(parentRuleSet *ast.RuleSet) *ast.DeclarationBlock {
+func (parser *Parser) ParseDeclarationBlock(parentRuleSet *ast.RuleSet) (parsed *ast.DeclarationBlock) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	var declBlock = ast.DeclarationBlock{}
 
-	var tok = parser.next() // should be '{'
-	if tok.Type != ast.T_BRACE_START {
-		panic(ParserError{"{", tok.Str})
+	parser.expect(ast.T_BRACE_START)
+
+	var tok *ast.Token
+
+	// the declarations are parsed one by one, so the parsing continues after
+	// the error of the declaration
+	for tok = parser.fetch(parser.Pos); tok != nil && tok.Type != ast.T_BRACE_END; tok = parser.fetch(parser.Pos) {
+		parser.recoverStatement(false, func() {
+			parser.ParseDeclaration(parentRuleSet, &declBlock)
+		})
 	}
+	parser.expect(ast.T_BRACE_END)
 
-	tok = parser.next()
-	for tok != nil && tok.Type != ast.T_BRACE_END {
+	return &declBlock
+}
 
-		if tok.Type == ast.T_PROPERTY_NAME_TOKEN {
-			parser.expect(ast.T_COLON)
+/*
+ParseDeclaration parses the declaration of the declaration block into the
+block.
+*/
+func (parser *Parser) ParseDeclaration(parentRuleSet *ast.RuleSet, declBlock *ast.DeclarationBlock) {
+	var tok = parser.peekToken()
+	if tok.Type == ast.T_PROPERTY_NAME_TOKEN {
+		var start = parser.Pos
+		parser.next()
+		parser.expect(ast.T_COLON)
+
+		var property = ast.NewProperty(tok)
+		var valueList = parser.ParsePropertyValue(parentRuleSet, property)
+		property.Values = valueList.Expressions
+		parser.setRange(property, start)
+		declBlock.Append(property)
+
+	} else if tok.Type == ast.T_VARIABLE {
+
+		declBlock.Append(parser.ParseVariableAssignment().(ast.Declaration))
+
+	} else if tok.Type == ast.T_MIXIN {
+
+		declBlock.Append(parser.ParseMixinStatement().(ast.Declaration))
+
+	} else if tok.Type == ast.T_FUNCTION {
+
+		declBlock.Append(parser.ParseFunctionStatement().(ast.Declaration))
+
+	} else if tok.Type == ast.T_INCLUDE {
+
+		declBlock.Append(parser.ParseIncludeStatement())
+
+	} else if tok.Type == ast.T_CONTENT {
+
+		declBlock.Append(parser.ParseContentStatement())
+
+	} else if tok.Type == ast.T_RETURN {
+
+		declBlock.Append(parser.ParseReturnStatement())
 
-			var property = ast.NewProperty(tok)
-			var valueList = parser.ParsePropertyValue(parentRuleSet, property)
-			_ = valueList
-			// property.Values = valueList
-			declBlock.Append(property)
-			_ = property
+	} else if tok.Type == ast.T_WARN {
 
-		} else if tok.IsSelector() {
-			// parse subrule
-			panic("subselector unimplemented")
+		declBlock.Append(parser.ParseWarnStatement())
+
+	} else if tok.Type == ast.T_IF {
+
+		declBlock.Append(parser.ParseIfStatement())
+
+	} else if tok.Type == ast.T_EACH {
+
+		declBlock.Append(parser.ParseEachStatement())
+
+	} else if tok.Type == ast.T_FOR {
+
+		declBlock.Append(parser.ParseForStatement())
+
+	} else if tok.Type == ast.T_WHILE {
+
+		declBlock.Append(parser.ParseWhileStatement())
+
+	} else if tok.Type == ast.T_EXTEND {
+
+		declBlock.Append(parser.ParseExtendStatement())
+
+	} else if tok.Type == ast.T_SEMICOLON {
+
+		// empty declaration
+		parser.next()
+
+	} else if tok.IsSelector() || tok.Type == ast.T_GT || tok.Type == ast.T_BRACKET_LEFT {
+
+		declBlock.Append(parser.ParseRuleSet(parentRuleSet).(*ast.RuleSet))
+
+	} else {
+		var tok = parser.next()
+		parser.errorAt(tok, "Unexpected token in declaration block: %s", tokenString(tok))
+	}
+}
+
+/*
+Parse the argument list of the mixin declaration or the `using` of the
+content block:
+
+	($color, $padding: 10px, $shadows...)
+*/
+func (parser *Parser) ParseArgumentList() (parsed *ast.ArgumentList) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var argList = ast.NewArgumentList()
+	parser.expect(ast.T_PAREN_START)
+
+	var tok = parser.peekToken()
+	for tok.Type != ast.T_PAREN_END {
+		var arg = ast.NewArgument(parser.expect(ast.T_VARIABLE))
+		if parser.accept(ast.T_COLON) != nil {
+			arg.DefaultValue = parser.ParseSpaceSepList()
+			if arg.DefaultValue == nil {
+				panic(fmt.Errorf("Expecting default value for the argument %s", arg.Name))
+			}
+		} else if parser.accept(ast.T_ELLIPSIS) != nil {
+			arg.VariableLength = true
+		}
+		argList.Append(arg)
+
+		if parser.accept(ast.T_COMMA) == nil {
+			break
+		}
+		tok = parser.peekToken()
+	}
+	parser.expect(ast.T_PAREN_END)
+
+	for i, arg := range argList.Arguments {
+		if arg.VariableLength && i != argList.Len()-1 {
+			panic(fmt.Errorf("Only the last argument can take variable arguments: %s", arg.Name))
+		}
+	}
+	return argList
+}
+
+func (parser *Parser) ParseMixinStatement() (parsed ast.Statement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	parser.expect(ast.T_MIXIN)
+
+	var mixin = ast.NewMixinStatement(parser.expect(ast.T_IDENT))
+	if parser.peekIs(ast.T_PAREN_START) {
+		mixin.ArgumentList = parser.ParseArgumentList()
+	}
+	mixin.Block = parser.ParseDeclarationBlock(nil)
+	return mixin
+}
+
+func (parser *Parser) ParseFunctionStatement() (parsed ast.Statement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	parser.expect(ast.T_FUNCTION)
+
+	var fn = ast.NewFunctionStatement(parser.expect(ast.T_IDENT))
+	if parser.peekIs(ast.T_PAREN_START) {
+		fn.ArgumentList = parser.ParseArgumentList()
+	}
+	fn.Block = parser.ParseDeclarationBlock(nil)
+	return fn
+}
+
+func (parser *Parser) ParseReturnStatement() (parsed *ast.ReturnStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var tok = parser.expect(ast.T_RETURN)
+
+	var value = parser.ParseValue(0)
+	if value == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Expecting value after @return. Got %s", tokenString(tok))
+	}
+
+	// the semicolon of the last declaration is optional
+	parser.expectStatementEnd()
+	return ast.NewReturnStatement(value, tok)
+}
+
+func (parser *Parser) ParseWarnStatement() (parsed *ast.WarnStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var tok = parser.expect(ast.T_WARN)
+
+	var value = parser.ParseValue(0)
+	if value == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Expecting value after @warn. Got %s", tokenString(tok))
+	}
+
+	// the semicolon of the last declaration is optional
+	parser.expectStatementEnd()
+	return ast.NewWarnStatement(value, tok)
+}
+
+/*
+Parse the condition of @if and @while, the condition is an expression.
+*/
+func (parser *Parser) ParseCondition() ast.Expression {
+	var condition = parser.ParseExpression(false)
+	if condition == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Expecting condition, got %s", tokenString(tok))
+	}
+	return condition
+}
+
+func (parser *Parser) ParseIfStatement() (parsed *ast.IfStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var tok = parser.expect(ast.T_IF)
+	var stm = ast.NewIfStatement(parser.ParseCondition(), tok)
+	stm.Block = parser.ParseDeclarationBlock(nil)
+
+	for parser.accept(ast.T_ELSE) != nil {
+		if tok = parser.accept(ast.T_IF); tok != nil {
+			var elseIf = ast.NewIfStatement(parser.ParseCondition(), tok)
+			elseIf.Block = parser.ParseDeclarationBlock(nil)
+			stm.AppendElseIf(elseIf)
 		} else {
-			panic("unexpected token")
+			stm.ElseBlock = parser.ParseDeclarationBlock(nil)
+			break
 		}
+	}
+	return stm
+}
 
-		tok = parser.next()
+func (parser *Parser) ParseEachStatement() (parsed *ast.EachStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var stm = ast.NewEachStatement(parser.expect(ast.T_EACH))
+	for {
+		stm.AppendVariable(ast.NewVariable(parser.expect(ast.T_VARIABLE)))
+		if parser.accept(ast.T_COMMA) == nil {
+			break
+		}
 	}
+	parser.expect(ast.T_IN)
 
-	return &declBlock
+	stm.List = parser.ParseValue(ast.T_BRACE_START)
+	if stm.List == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Expecting list after 'in', got %s", tokenString(tok))
+	}
+	stm.Block = parser.ParseDeclarationBlock(nil)
+	return stm
+}
+
+func (parser *Parser) ParseForStatement() (parsed *ast.ForStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var tok = parser.expect(ast.T_FOR)
+	var stm = ast.NewForStatement(ast.NewVariable(parser.expect(ast.T_VARIABLE)), tok)
+	parser.expect(ast.T_FROM)
+
+	if stm.From = parser.ParseExpression(false); stm.From == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Expecting expression after 'from', got %s", tokenString(tok))
+	}
+
+	if parser.accept(ast.T_THROUGH) != nil {
+		stm.Inclusive = true
+	} else if parser.accept(ast.T_TO) == nil {
+		var tok = parser.peekToken()
+		panic(ParserError{"through or to", tok.Str, tok})
+	}
+
+	if stm.To = parser.ParseExpression(false); stm.To == nil {
+		var tok = parser.peek()
+		parser.errorAt(tok, "Expecting expression after 'through' or 'to', got %s", tokenString(tok))
+	}
+	stm.Block = parser.ParseDeclarationBlock(nil)
+	return stm
+}
+
+func (parser *Parser) ParseWhileStatement() (parsed *ast.WhileStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var tok = parser.expect(ast.T_WHILE)
+	var stm = ast.NewWhileStatement(parser.ParseCondition(), tok)
+	stm.Block = parser.ParseDeclarationBlock(nil)
+	return stm
 }
 
-func (parser *Parser) ParseImportStatement() ast.Statement {
-	// skip the ast.T_IMPORT token
+func (parser *Parser) ParseIncludeStatement() (parsed *ast.IncludeStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	parser.expect(ast.T_INCLUDE)
+
+	var stm = ast.NewIncludeStatement(parser.expect(ast.T_IDENT))
+	if parser.peekIs(ast.T_PAREN_START) {
+		stm.Arguments = parser.ParseCallArguments()
+	}
+
+	if parser.accept(ast.T_USING) != nil {
+		stm.ContentArgumentList = parser.ParseArgumentList()
+	}
+
+	var tok = parser.peek()
+	if tok == nil {
+		if stm.ContentArgumentList != nil {
+			parser.errorAt(nil, "Unexpected end of file")
+		}
+	} else if tok.Type == ast.T_BRACE_START {
+		stm.ContentBlock = parser.ParseDeclarationBlock(nil)
+	} else if stm.ContentArgumentList != nil {
+		panic(ParserError{"{", tok.Str, tok})
+	} else if tok.Type == ast.T_SEMICOLON {
+		parser.next()
+	} else if tok.Type != ast.T_BRACE_END {
+		panic(ParserError{";", tok.Str, tok})
+	}
+	return stm
+}
+
+func (parser *Parser) ParseContentStatement() (parsed *ast.ContentStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var stm = ast.NewContentStatement(parser.expect(ast.T_CONTENT))
+	if parser.peekIs(ast.T_PAREN_START) {
+		stm.Arguments = parser.ParseCallArguments()
+	}
+	parser.expectStatementEnd()
+	return stm
+}
+
+/*
+Parse the @extend directive, the targets are compound selectors separated by
+commas:
+
+	@extend .button, %message-shared !optional;
+*/
+func (parser *Parser) ParseExtendStatement() (parsed *ast.ExtendStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var stm = ast.NewExtendStatement(parser.expect(ast.T_EXTEND))
+	var compound = ast.NewComplexSelector()
+
+	var tok = parser.nextToken()
+	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_END {
+		switch tok.Type {
+		case ast.T_TYPE_SELECTOR:
+			compound.AppendSelector(ast.NewTypeSelector(tok))
+		case ast.T_UNIVERSAL_SELECTOR:
+			compound.AppendSelector(ast.UniversalSelector{})
+		case ast.T_ID_SELECTOR:
+			compound.AppendSelector(ast.NewIdSelector(tok))
+		case ast.T_CLASS_SELECTOR:
+			compound.AppendSelector(ast.NewClassSelector(tok))
+		case ast.T_PLACEHOLDER_SELECTOR:
+			compound.AppendSelector(ast.NewPlaceholderSelector(tok))
+		case ast.T_PSEUDO_SELECTOR:
+			var sel = ast.PseudoSelector{PseudoClass: tok.Str}
+			if nextTok := parser.accept(ast.T_LANG_CODE); nextTok != nil {
+				sel.C = nextTok.Str
+			}
+			compound.AppendSelector(sel)
+		case ast.T_BRACKET_LEFT:
+			parser.backup()
+			compound.AppendSelector(parser.ParseAttributeSelector())
+		case ast.T_COMMA:
+			stm.AppendSelector(compound)
+			compound = ast.NewComplexSelector()
+		case ast.T_OPTIONAL:
+			stm.Optional = true
+		case ast.T_DESCENDANT_SELECTOR, ast.T_CHILD_SELECTOR, ast.T_GT, ast.T_ADJACENT_SELECTOR:
+			panic(fmt.Errorf("Complex selectors may not be extended: %s", stm))
+		default:
+			parser.errorAt(tok, "Unexpected token in @extend: %s", tokenString(tok))
+		}
+		tok = parser.nextToken()
+	}
+	if tok.Type == ast.T_BRACE_END {
+		parser.backup()
+	}
+	if compound.Len() > 0 {
+		stm.AppendSelector(compound)
+	}
+	if len(stm.Selectors) == 0 {
+		panic(fmt.Errorf("Expected selector for @extend"))
+	}
+	return stm
+}
+
+/*
+Parse the @media block, the query is rebuilt from the tokens and the block
+contains the statements like the top level:
+
+	@media screen and (max-width: 100px) { }
+*/
+func (parser *Parser) ParseMediaStatement() (parsed *ast.MediaStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var mediaTok = parser.expect(ast.T_MEDIA)
+
+	var query = ""
+	var tok = parser.nextToken()
+	for tok.Type != ast.T_BRACE_START {
+		switch {
+		case tok.Type == ast.T_PAREN_END:
+			query += ")"
+		case tok.Type == ast.T_COLON:
+			query += ": "
+		case tok.Type == ast.T_COMMA:
+			query += ", "
+		case tok.IsUnit():
+			query += tok.Str
+		default:
+			if query != "" && !strings.HasSuffix(query, "(") && !strings.HasSuffix(query, " ") {
+				query += " "
+			}
+			query += tok.Str
+		}
+		tok = parser.nextToken()
+	}
+
+	var stm = ast.NewMediaStatement(query, mediaTok)
+	for tok = parser.fetch(parser.Pos); tok != nil && tok.Type != ast.T_BRACE_END; tok = parser.fetch(parser.Pos) {
+		parser.recoverStatement(false, func() {
+			var sub = parser.ParseStatement(nil)
+			if sub == nil {
+				var tok = parser.next()
+				parser.errorAt(tok, "Unexpected token in @media block: %s", tokenString(tok))
+			}
+			stm.Block.AppendStatement(sub)
+		})
+	}
+	parser.expect(ast.T_BRACE_END)
+	return stm
+}
+
+func (parser *Parser) ParseImportStatement() (parsed ast.Statement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	// skip the ast.T_IMPORT or ast.T_IMPORT_ONCE token
 	var tok = parser.next()
 
+	// the urls are separated by comma: @import "a", "b";
+	var list = &ast.ImportList{}
+	for {
+		list.Imports = append(list.Imports, parser.parseImportUrl(tok))
+		if parser.accept(ast.T_COMMA) == nil {
+			break
+		}
+	}
+
+	parser.expectStatementEnd()
+	if len(list.Imports) == 1 {
+		return list.Imports[0]
+	}
+	return list
+}
+
+/*
+parseImportUrl parses the url and the media list of the import, the token is
+the @import or @import-once token of the statement.
+*/
+func (parser *Parser) parseImportUrl(importTok *ast.Token) (parsed *ast.ImportStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
 	// Create the import statement node
-	var rule = ast.ImportStatement{}
+	var rule = ast.ImportStatement{Once: importTok.Type == ast.T_IMPORT_ONCE, Token: importTok}
 
-	tok = parser.peek()
+	var tok = parser.peekToken()
 	// expecting url(..)
 	if tok.Type == ast.T_IDENT {
 		parser.advance()
@@ -665,14 +1384,14 @@ func (parser *Parser) ParseImportStatement() ast.Statement {
 			panic("invalid function for @import rule.")
 		}
 
-		if tok = parser.next(); tok.Type != ast.T_PAREN_START {
+		if tok = parser.nextToken(); tok.Type != ast.T_PAREN_START {
 			panic("expecting parenthesis after url")
 		}
 
-		tok = parser.next()
+		tok = parser.nextToken()
 		rule.Url = ast.Url(tok.Str)
 
-		if tok = parser.next(); tok.Type != ast.T_PAREN_END {
+		if tok = parser.nextToken(); tok.Type != ast.T_PAREN_END {
 			panic("expecting parenthesis after url")
 		}
 
@@ -689,15 +1408,118 @@ func (parser *Parser) ParseImportStatement() ast.Statement {
 		@import url("bluish.css") projection, tv;
 	*/
 	tok = parser.peek()
-	if tok.Type == ast.T_MEDIA {
+	if tok != nil && tok.Type == ast.T_MEDIA {
 		parser.advance()
 		rule.MediaList = append(rule.MediaList, tok.Str)
 	}
+	return &rule
+}
+
+/*
+ParseUseStatement parses the @use rule:
+
+	@use "src/corners" as c;
+	@use "library" with ($black: #222, $border-radius: 0.1rem);
+*/
+func (parser *Parser) ParseUseStatement() (parsed *ast.UseStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
 
-	// must be ast.T_SEMICOLON
-	tok = parser.next()
-	if tok.Type != ast.T_SEMICOLON {
-		panic(ParserError{";", tok.Str})
+	var tok = parser.expect(ast.T_USE)
+	var stm = ast.NewUseStatement(parser.parseModuleUrl(), tok)
+
+	if parser.accept(ast.T_AS) != nil {
+		var ns = parser.expect(ast.T_IDENT)
+		if ns.Str == "" || (ns.Str != "*" && strings.Contains(ns.Str, "*")) {
+			panic(ParserError{"namespace", ns.Str, ns})
+		}
+		stm.Namespace = ns.Str
 	}
-	return &rule
+
+	if parser.accept(ast.T_WITH) != nil {
+		for _, arg := range parser.ParseCallArguments() {
+			keyword, ok := arg.(*ast.KeywordArgument)
+			if !ok {
+				panic(fmt.Errorf("Expecting `$name: value` for the configuration of @use, got %s", arg))
+			}
+			stm.Configuration = append(stm.Configuration, keyword)
+		}
+	}
+	parser.expectStatementEnd()
+	return stm
+}
+
+/*
+ParseForwardStatement parses the @forward rule:
+
+	@forward "src/list" as list-* hide list-reset, $horizontal-list-gap;
+*/
+func (parser *Parser) ParseForwardStatement() (parsed *ast.ForwardStatement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	var tok = parser.expect(ast.T_FORWARD)
+	var stm = ast.NewForwardStatement(parser.parseModuleUrl(), tok)
+
+	if parser.accept(ast.T_AS) != nil {
+		var prefix = parser.expect(ast.T_IDENT)
+		if !strings.HasSuffix(prefix.Str, "*") || strings.Count(prefix.Str, "*") > 1 {
+			panic(ParserError{"prefix-*", prefix.Str, prefix})
+		}
+		stm.Prefix = strings.TrimSuffix(prefix.Str, "*")
+	}
+
+	if parser.accept(ast.T_SHOW) != nil {
+		stm.Show = parser.parseMemberNames()
+	} else if parser.accept(ast.T_HIDE) != nil {
+		stm.Hide = parser.parseMemberNames()
+	}
+	parser.expectStatementEnd()
+	return stm
+}
+
+func (parser *Parser) parseModuleUrl() string {
+	var tok = parser.next()
+	if tok == nil || (tok.Type != ast.T_QQ_STRING && tok.Type != ast.T_Q_STRING) {
+		parser.errorAt(tok, "Expecting the quoted url of the module, got %s", tokenString(tok))
+	}
+	return tok.Str
+}
+
+// the member names of `show` and `hide`, separated by ','
+func (parser *Parser) parseMemberNames() []string {
+	var names = []string{}
+	for {
+		var tok = parser.next()
+		if tok == nil || (tok.Type != ast.T_IDENT && tok.Type != ast.T_VARIABLE) {
+			parser.errorAt(tok, "Expecting the member name, got %s", tokenString(tok))
+		}
+		names = append(names, tok.Str)
+		if parser.accept(ast.T_COMMA) == nil {
+			return names
+		}
+	}
+}
+
+/*
+expectStatementEnd expects the `;` of the statement, it's optional before the
+`}` of the block and at the end of file.
+*/
+func (parser *Parser) expectStatementEnd() {
+	if tok := parser.peek(); tok != nil && tok.Type != ast.T_BRACE_END {
+		parser.expect(ast.T_SEMICOLON)
+	}
+}
+
+func (parser *Parser) ParseCharsetStatement() (parsed ast.Statement) {
+	defer func(start int) { parser.setRange(parsed, start) }(parser.Pos)
+
+	// skip the ast.T_CHARSET token
+	parser.expect(ast.T_CHARSET)
+
+	var tok = parser.nextToken()
+	if !tok.IsString() {
+		panic(ParserError{"string", tok.Str, tok})
+	}
+	var stm = ast.NewCharsetStatement(tok)
+	parser.expectStatementEnd()
+	return stm
 }
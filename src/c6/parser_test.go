@@ -5,16 +5,39 @@ import "c6/ast"
 import "github.com/stretchr/testify/assert"
 
 import "fmt"
+import "io/ioutil"
+import "os"
+import "path/filepath"
 
 func RunParserTest(code string) *ast.Block {
 	fmt.Printf("Test parsing: %s\n", code)
 	var parser = NewParser(NewContext())
-	return parser.ParseScss(code)
+	block, err := parser.ParseScss(code)
+	if err != nil {
+		panic(err)
+	}
+	return block
+}
+
+func TestParserParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c6")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "test.scss")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`div { width: 1px; }`), 0644))
+
+	block, err := NewParser(NewContext()).ParseFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(block.Statements))
+
+	_, err = NewParser(NewContext()).ParseFile(filepath.Join(dir, "test.txt"))
+	assert.NotNil(t, err)
 }
 
 func TestParserParseImportRuleWithUrl(t *testing.T) {
 	parser := NewParser(NewContext())
-	block := parser.ParseScss(`@import url("http://foo.com/bar.css");`)
+	block, _ := parser.ParseScss(`@import url("http://foo.com/bar.css");`)
 
 	rule, ok := block.Statement(0).(*ast.ImportStatement)
 	assert.True(t, ok, "Convert to ImportStatement OK")
@@ -32,7 +55,7 @@ func TestParserParseImportRuleWithUrl(t *testing.T) {
 
 func TestParserParseImportRuleWithString(t *testing.T) {
 	parser := NewParser(NewContext())
-	block := parser.ParseScss(`@import "foo.css";`)
+	block, _ := parser.ParseScss(`@import "foo.css";`)
 
 	rule, ok := block.Statement(0).(*ast.ImportStatement)
 	assert.True(t, ok, "Convert to ImportStatement OK")
@@ -131,14 +154,15 @@ func TestParserMassiveRules(t *testing.T) {
 	for _, buffer := range buffers {
 		fmt.Printf("Input %s\n", buffer)
 		var parser = NewParser(NewContext())
-		var block = parser.ParseScss(buffer)
+		var block, err = parser.ParseScss(buffer)
+		assert.Nil(t, err)
 		fmt.Printf("%+v\n", block)
 	}
 }
 
 func TestParserParseTypeSelectorRule(t *testing.T) {
 	parser := NewParser(NewContext())
-	block := parser.ParseScss(`div { width: auto; }`)
+	block, _ := parser.ParseScss(`div { width: auto; }`)
 
 	ruleset, ok := block.Statements[0].(*ast.RuleSet)
 	assert.True(t, ok)
@@ -214,3 +238,199 @@ func TestParseNestedRule(t *testing.T) {
 	p.parseScss(code)
 }
 */
+
+func TestParserMixinStatement(t *testing.T) {
+	var block = RunParserTest(`@mixin button($color, $padding: 10px, $shadows...) { color: $color; padding: $padding; }`)
+	mixin, ok := block.Statements[0].(*ast.MixinStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "button", mixin.Name)
+	assert.Equal(t, 3, mixin.ArgumentList.Len())
+	assert.Equal(t, "$color", mixin.ArgumentList.Arguments[0].Name)
+	assert.NotNil(t, mixin.ArgumentList.Arguments[1].DefaultValue)
+	assert.True(t, mixin.ArgumentList.Arguments[2].VariableLength)
+	assert.Equal(t, 2, len(mixin.Block.Declarations))
+}
+
+func TestParserIncludeStatement(t *testing.T) {
+	var block = RunParserTest(`.a { @include button(red, $padding: 5px, $list...) using ($x) { width: $x; } }`)
+	var ruleset = block.Statements[0].(*ast.RuleSet)
+	include, ok := ruleset.DeclarationBlock.Declarations[0].(*ast.IncludeStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "button", include.MixinName)
+	assert.Equal(t, 3, len(include.Arguments))
+
+	_, ok = include.Arguments[1].(*ast.KeywordArgument)
+	assert.True(t, ok)
+	_, ok = include.Arguments[2].(*ast.RestArgument)
+	assert.True(t, ok)
+
+	assert.Equal(t, 1, include.ContentArgumentList.Len())
+	assert.Equal(t, 1, len(include.ContentBlock.Declarations))
+}
+
+func TestParserFunctionStatement(t *testing.T) {
+	var block = RunParserTest(`@function rem($px, $base: 16px) { $ratio: $px / $base; @return $ratio * 1rem; }`)
+	fn, ok := block.Statements[0].(*ast.FunctionStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "rem", fn.Name)
+	assert.Equal(t, 2, fn.ArgumentList.Len())
+	assert.Equal(t, 2, len(fn.Block.Declarations))
+
+	ret, ok := fn.Block.Declarations[1].(*ast.ReturnStatement)
+	assert.True(t, ok)
+	_, ok = ret.Value.(*ast.BinaryExpression)
+	assert.True(t, ok)
+}
+
+func TestParserIfStatement(t *testing.T) {
+	var block = RunParserTest(`@if $a { $b: 1; } @else if $c { $b: 2; } @else if $d { $b: 3; } @else { $b: 4; }`)
+	stm, ok := block.Statements[0].(*ast.IfStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(stm.ElseIfs))
+	assert.NotNil(t, stm.ElseBlock)
+	assert.Equal(t, 1, len(block.Statements))
+}
+
+func TestParserEachStatement(t *testing.T) {
+	var block = RunParserTest(`@each $key, $value in (a: 1px, b: 2px) { $x: $value; }`)
+	stm, ok := block.Statements[0].(*ast.EachStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(stm.Variables))
+
+	m, ok := stm.List.(*ast.Map)
+	assert.True(t, ok)
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, "2px", m.Get(&ast.String{Value: "b"}).String())
+}
+
+func TestParserForStatement(t *testing.T) {
+	var block = RunParserTest(`@for $i from 1 through 3 { $x: $i; }`)
+	stm, ok := block.Statements[0].(*ast.ForStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "$i", stm.Variable.Name)
+	assert.True(t, stm.Inclusive)
+}
+
+func TestParserParenthesis(t *testing.T) {
+	var block = RunParserTest(`$a: (1px, 2px) (3px 4px); $b: (); $c: (1 + 2) * 3;`)
+	assert.Equal(t, 3, len(block.Statements))
+
+	list, ok := block.Statements[0].(*ast.VariableAssignment).Expression.(*ast.List)
+	assert.True(t, ok)
+	assert.Equal(t, 2, list.Len())
+
+	empty, ok := block.Statements[1].(*ast.VariableAssignment).Expression.(*ast.List)
+	assert.True(t, ok)
+	assert.Equal(t, 0, empty.Len())
+}
+
+func TestParserExtendStatement(t *testing.T) {
+	var block = RunParserTest(`.a { @extend .b, %c !optional; }`)
+	var ruleset = block.Statement(0).(*ast.RuleSet)
+	var stm, ok = ruleset.DeclarationBlock.Declarations[0].(*ast.ExtendStatement)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(stm.Selectors))
+	assert.True(t, stm.Optional)
+	assert.Equal(t, "@extend .b, %c !optional", stm.String())
+
+	assert.Panics(t, func() {
+		RunParserTest(`.a { @extend .b .c; }`)
+	})
+}
+
+func TestParserUseStatement(t *testing.T) {
+	var block = RunParserTest(`@use "library" as lib with ($black: #222, $radius: 1px);`)
+	var stm, ok = block.Statement(0).(*ast.UseStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "library", stm.Url)
+	assert.Equal(t, "lib", stm.Namespace)
+	assert.Equal(t, 2, len(stm.Configuration))
+	assert.Equal(t, "$black", stm.Configuration[0].Name)
+	assert.Equal(t, "corners", DefaultNamespace("src/_corners.scss"))
+	assert.Equal(t, "math", DefaultNamespace("sass:math"))
+}
+
+func TestParserForwardStatement(t *testing.T) {
+	var block = RunParserTest(`@forward "src/list" as list-* show list-reset, $list-gap;`)
+	var stm, ok = block.Statement(0).(*ast.ForwardStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "list-", stm.Prefix)
+	assert.Equal(t, []string{"list-reset", "$list-gap"}, stm.Show)
+	assert.Equal(t, `@forward "src/list" as list-* show list-reset, $list-gap;`, stm.String())
+}
+
+func TestParserMediaStatement(t *testing.T) {
+	var block = RunParserTest(`@media screen and (max-width: 100px), print { %a { color: red; } }`)
+	var media, ok = block.Statement(0).(*ast.MediaStatement)
+	assert.True(t, ok)
+	assert.Equal(t, "screen and (max-width: 100px), print", media.Query)
+	assert.Equal(t, 1, len(media.Block.Statements))
+}
+
+func TestParserNestedRuleSet(t *testing.T) {
+	var block = RunParserTest(`.a { color: red; &-item, > .b { color: blue; } }`)
+	var ruleset = block.Statement(0).(*ast.RuleSet)
+	// the nested ruleset is kept in the source order
+	assert.Equal(t, 2, len(ruleset.DeclarationBlock.Declarations))
+	assert.Equal(t, 1, len(ruleset.GetSubRuleSets()))
+	assert.Equal(t, ruleset.GetSubRuleSets()[0], ruleset.DeclarationBlock.Declarations[1])
+
+	var sub = ruleset.GetSubRuleSets()[0]
+	assert.Equal(t, 2, len(sub.Selectors))
+	assert.Equal(t, "&-item", sub.Selectors[0].String())
+	assert.Equal(t, " > .b", sub.Selectors[1].String())
+}
+
+func TestParserVariableAssignmentFlags(t *testing.T) {
+	var block = RunParserTest(`$a: 1px 2px !default; $b: red !default !global;`)
+	var a = block.Statement(0).(*ast.VariableAssignment)
+	assert.True(t, a.Default)
+	assert.False(t, a.Global)
+	assert.Equal(t, 2, a.Expression.(*ast.List).Len())
+
+	var b = block.Statement(1).(*ast.VariableAssignment)
+	assert.True(t, b.Default)
+	assert.True(t, b.Global)
+}
+
+func TestParserNodeRange(t *testing.T) {
+	var parser = NewParser(NewContext())
+	parser.File = "foo.scss"
+	block, err := parser.ParseScss(".a {\n  width: 1px + $ü;\n  @include foo(1px);\n}\n$b: 1, 2;")
+	assert.Nil(t, err)
+
+	var ruleset = block.Statements[0].(*ast.RuleSet)
+	assert.Equal(t, "1:1-4:2", ast.NodeRange(ruleset).String())
+	assert.Equal(t, "foo.scss", ast.NodeRange(ruleset).File)
+
+	var property = ruleset.DeclarationBlock.Declarations[0].(*ast.Property)
+	assert.Equal(t, "2:3-2:19", ast.NodeRange(property).String())
+	assert.Equal(t, "2:10-2:18", ast.NodeRange(property.Values[0]).String())
+
+	var include = ruleset.DeclarationBlock.Declarations[1].(*ast.IncludeStatement)
+	assert.Equal(t, "3:3-3:21", ast.NodeRange(include).String())
+
+	var assignment = block.Statements[1].(*ast.VariableAssignment)
+	assert.Equal(t, "5:1-5:10", ast.NodeRange(assignment).String())
+	assert.Equal(t, "5:5-5:9", ast.NodeRange(assignment.Expression).String())
+}
+
+func TestParserSelectorRange(t *testing.T) {
+	var block = RunParserTest("a.b #c, [href=\"x\"] > %p {\n  &-d:hover { }\n}")
+	var ruleset = block.Statements[0].(*ast.RuleSet)
+
+	var first = ruleset.Selectors[0].(*ast.ComplexSelector)
+	assert.Equal(t, "1:1-1:7", ast.NodeRange(first).String())
+	assert.Equal(t, "1:1-1:2", ast.NodeRange(first.Selectors[0]).String())
+	assert.Equal(t, "1:2-1:4", ast.NodeRange(first.Selectors[1]).String())
+	assert.Equal(t, "1:5-1:7", ast.NodeRange(first.Selectors[3]).String())
+
+	var second = ruleset.Selectors[1].(*ast.ComplexSelector)
+	assert.Equal(t, "1:9-1:24", ast.NodeRange(second).String())
+	assert.Equal(t, "1:9-1:19", ast.NodeRange(second.Selectors[0]).String())
+	assert.Equal(t, "1:22-1:24", ast.NodeRange(second.Selectors[2]).String())
+
+	var nested = ruleset.DeclarationBlock.Declarations[0].(*ast.RuleSet)
+	var parent = nested.Selectors[0].(*ast.ComplexSelector).Selectors[0]
+	assert.Equal(t, "2:3-2:6", ast.NodeRange(parent).String())
+}
@@ -0,0 +1,58 @@
+package c6
+
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func TestScopeShadowing(t *testing.T) {
+	var out = RunCompilerTest(`
+$x: 1px;
+.a { $x: 2px; width: $x; .b { $x: 3px; width: $x; } }
+.c { width: $x; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 2px; }\n.a .b { width: 3px; }\n\n.c { width: 1px; }\n", out)
+}
+
+func TestScopeGlobalFlag(t *testing.T) {
+	var out = RunCompilerTest(`
+$x: 1px;
+.a { $x: 2px !global; $y: 3px !global; }
+.b { width: $x; height: $y; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".b { width: 2px; height: 3px; }\n", out)
+}
+
+func TestScopeDefaultFlag(t *testing.T) {
+	var out = RunCompilerTest(`
+$x: 1px;
+$x: 2px !default;
+$y: null;
+$y: 3px !default;
+$z: 4px !default;
+.a { width: $x $y $z; $x: 5px !default; height: $x; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 1px 3px 4px; height: 1px; }\n", out)
+}
+
+func TestScopeFlowControl(t *testing.T) {
+	var out = RunCompilerTest(`
+$x: 1px;
+@if true { $x: 2px; $local: 1px; }
+$i: 10px;
+@for $i from 1 through 2 { }
+.a { width: $x; height: $i; }
+.b { $n: 0; @for $i from 1 through 3 { $n: $n + $i; } width: $n; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { width: 2px; height: 10px; }\n\n.b { width: 6; }\n", out)
+
+	assert.Panics(t, func() {
+		RunCompilerTest(`@if true { $local: 1px; } .a { width: $local; }`, compiler.NewCompactStyleCompiler())
+	})
+}
+
+func TestScopeMixinClosure(t *testing.T) {
+	var out = RunCompilerTest(`
+.a { $color: red; @mixin paint { color: $color; } @include paint; }`, compiler.NewCompactStyleCompiler())
+	assert.Equal(t, ".a { color: red; }\n", out)
+
+	// the mixin can't access the local variables of the include statement
+	assert.Panics(t, func() {
+		RunCompilerTest(`@mixin paint { color: $color; } .a { $color: red; @include paint; }`, compiler.NewCompactStyleCompiler())
+	})
+}
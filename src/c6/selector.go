@@ -0,0 +1,137 @@
+package c6
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+import "fmt"
+import "c6/ast"
+
+/*
+ResolveSelectors combines the selectors of the nested ruleset with the
+selectors of the parent ruleset, every parent is combined with every child:
+
+	.a, .b {
+		.c, &:hover { }
+	}
+
+generates `.a .c, .a:hover, .b .c, .b:hover`. The selector without the parent
+selector `&` is placed after the parent as a descendant, and the suffix of
+the parent selector is appended to the parent, `&-item` generates `.a-item`.
+Each `&` of the selector is expanded to every parent, `& + &` generates
+`.a + .a, .a + .b, .b + .a, .b + .b`.
+
+The parents are nil for the top-level rulesets.
+*/
+func ResolveSelectors(selectors []ast.Selector, parents []ast.Selector) []ast.Selector {
+	if parents == nil {
+		for _, sel := range selectors {
+			if hasParentSelector(sel) {
+				panic(fmt.Errorf("Top-level selectors may not contain the parent selector \"&\": %s", sel))
+			}
+		}
+		return selectors
+	}
+
+	var resolved = []ast.Selector{}
+	for _, parent := range parents {
+		for _, sel := range selectors {
+			resolved = append(resolved, resolveSelector(sel, parent, parents)...)
+		}
+	}
+	return resolved
+}
+
+func hasParentSelector(sel ast.Selector) bool {
+	if complex, ok := sel.(*ast.ComplexSelector); ok {
+		for _, sub := range complex.Selectors {
+			if _, ok := sub.(ast.ParentSelector); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selectorSequence(sel ast.Selector) []ast.Selector {
+	if complex, ok := sel.(*ast.ComplexSelector); ok {
+		return complex.Selectors
+	}
+	return []ast.Selector{sel}
+}
+
+/*
+resolveSelector resolves the selector with the parent, the first `&` is
+replaced with the parent, and the others are expanded to all the parents.
+*/
+func resolveSelector(sel ast.Selector, parent ast.Selector, parents []ast.Selector) []ast.Selector {
+	var sequence = selectorSequence(sel)
+
+	if !hasParentSelector(sel) {
+		var result = ast.NewComplexSelector()
+		result.Selectors = append(result.Selectors, selectorSequence(parent)...)
+		// `> .child` is combined with the parent by the child combinator
+		if len(sequence) > 0 && !isCombinator(sequence[0]) {
+			result.AppendSelector(ast.DescendantSelector{})
+		}
+		result.Selectors = append(result.Selectors, sequence...)
+		return []ast.Selector{result}
+	}
+
+	var results = [][]ast.Selector{{}}
+	var first = true
+	for _, sub := range sequence {
+		parentSel, ok := sub.(ast.ParentSelector)
+		if !ok {
+			for i := range results {
+				results[i] = append(results[i], sub)
+			}
+			continue
+		}
+		var candidates = parents
+		if first {
+			candidates = []ast.Selector{parent}
+			first = false
+		}
+		var expanded = [][]ast.Selector{}
+		for _, result := range results {
+			for _, candidate := range candidates {
+				var selectors = append(append([]ast.Selector{}, result...), substituteParent(parentSel, candidate)...)
+				expanded = append(expanded, selectors)
+			}
+		}
+		results = expanded
+	}
+
+	var resolved = []ast.Selector{}
+	for _, selectors := range results {
+		var result = ast.NewComplexSelector()
+		result.Selectors = selectors
+		resolved = append(resolved, result)
+	}
+	return resolved
+}
+
+// substituteParent returns the selector sequence of the parent for `&`, with the suffix of `&-item`
+func substituteParent(parentSel ast.ParentSelector, parent ast.Selector) []ast.Selector {
+	var parentSequence = selectorSequence(parent)
+	if parentSel.Suffix == "" {
+		return parentSequence
+	}
+	var selectors = append([]ast.Selector{}, parentSequence[:len(parentSequence)-1]...)
+	return append(selectors, appendSelectorSuffix(parentSequence[len(parentSequence)-1], parentSel.Suffix, parent))
+}
+
+func appendSelectorSuffix(sel ast.Selector, suffix string, parent ast.Selector) ast.Selector {
+	switch t := sel.(type) {
+	case ast.ClassSelector:
+		return ast.ClassSelector{ClassName: t.ClassName + suffix}
+	case ast.IdSelector:
+		return ast.IdSelector{Id: t.Id + suffix}
+	case ast.TypeSelector:
+		return ast.TypeSelector{Type: t.Type + suffix}
+	case ast.PlaceholderSelector:
+		return ast.PlaceholderSelector{Name: t.Name + suffix}
+	}
+	panic(fmt.Errorf("Invalid parent selector for \"&%s\": %s", suffix, parent))
+}
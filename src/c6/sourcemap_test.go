@@ -0,0 +1,83 @@
+package c6
+
+import "encoding/base64"
+import "encoding/json"
+import "strings"
+import "testing"
+import "c6/compiler"
+import "github.com/stretchr/testify/assert"
+
+func RunSourceMapTest(t *testing.T, code string, c compiler.Compiler) (string, []compiler.Mapping) {
+	var parser = NewParser(NewContext())
+	parser.File = "foo.scss"
+	block, err := parser.ParseScss(code)
+	assert.Nil(t, err)
+	return c.CompileBlockWithMappings(NewContext().Evaluate(block))
+}
+
+func TestSourceMapNestedStyleMappings(t *testing.T) {
+	css, mappings := RunSourceMapTest(t, ".a {\n  color: red;\n  .b { width: 1px; }\n}\n", compiler.NewNestedStyleCompiler())
+	assert.Equal(t, ".a {\n  color: red; }\n  .a .b {\n    width: 1px; }\n", css)
+	assert.Equal(t, []compiler.Mapping{
+		{GeneratedLine: 0, GeneratedColumn: 0, Source: "foo.scss", Line: 0, Column: 0},
+		{GeneratedLine: 1, GeneratedColumn: 2, Source: "foo.scss", Line: 1, Column: 2},
+		{GeneratedLine: 2, GeneratedColumn: 2, Source: "foo.scss", Line: 2, Column: 2},
+		{GeneratedLine: 3, GeneratedColumn: 4, Source: "foo.scss", Line: 2, Column: 7},
+	}, mappings)
+
+	var sourceMap = compiler.NewSourceMap("foo.css", mappings)
+	assert.Equal(t, []string{"foo.scss"}, sourceMap.Sources)
+	assert.Equal(t, "AAAA;EACE;EACA;IAAK", sourceMap.Mappings)
+}
+
+func TestSourceMapCompressedStyleMappings(t *testing.T) {
+	css, mappings := RunSourceMapTest(t, "@media print {\n  p { x: y }\n}\n", compiler.NewCompressedStyleCompiler())
+	assert.Equal(t, "@media print{p{x:y}}", css)
+	assert.Equal(t, []compiler.Mapping{
+		{GeneratedLine: 0, GeneratedColumn: 13, Source: "foo.scss", Line: 1, Column: 2},
+		{GeneratedLine: 0, GeneratedColumn: 15, Source: "foo.scss", Line: 1, Column: 6},
+	}, mappings)
+	assert.Equal(t, "aACE,EAAI", compiler.NewSourceMap("", mappings).Mappings)
+}
+
+func TestSourceMapMixinMapsToDefinition(t *testing.T) {
+	_, mappings := RunSourceMapTest(t, "@mixin m {\n  color: red;\n}\n.a { @include m; }\n", compiler.NewCompactStyleCompiler())
+	assert.Equal(t, 2, len(mappings))
+	// the selector is from the ruleset, the property from the mixin
+	assert.Equal(t, 3, mappings[0].Line)
+	assert.Equal(t, 1, mappings[1].Line)
+}
+
+func TestSourceMapVLQ(t *testing.T) {
+	var sourceMap = compiler.NewSourceMap("out.css", []compiler.Mapping{
+		{GeneratedLine: 0, GeneratedColumn: 16, Source: "a.scss", Line: 100, Column: 0},
+		{GeneratedLine: 0, GeneratedColumn: 1, Source: "b.scss", Line: 0, Column: 3},
+		{GeneratedLine: 2, GeneratedColumn: 0, Source: "a.scss", Line: 1, Column: 0},
+	})
+	assert.Equal(t, []string{"b.scss", "a.scss"}, sourceMap.Sources)
+	assert.Equal(t, "CAAG,eCoGH;;AAnGA", sourceMap.Mappings)
+}
+
+func TestSourceMapOutput(t *testing.T) {
+	var sourceMap = compiler.NewSourceMap("out.css", []compiler.Mapping{
+		{Source: "", Line: 0, Column: 0},
+	})
+	assert.Nil(t, sourceMap.SetSourcesContent(func(source string) (string, error) {
+		return "a { }", nil
+	}))
+
+	data, err := sourceMap.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":3,"file":"out.css","sources":["stdin"],"sourcesContent":["a { }"],"names":[],"mappings":"AAAA"}`, string(data))
+
+	uri, err := sourceMap.DataURI()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(uri, "data:application/json;charset=utf-8;base64,"))
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, "data:application/json;charset=utf-8;base64,"))
+	assert.Nil(t, err)
+	var parsed map[string]interface{}
+	assert.Nil(t, json.Unmarshal(decoded, &parsed))
+	assert.Equal(t, float64(3), parsed["version"])
+
+	assert.Equal(t, "/*# sourceMappingURL=out.css.map */\n", compiler.SourceMappingURLComment("out.css.map"))
+}